@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/tdawidzi/dictionary_app/auth"
+	"github.com/tdawidzi/dictionary_app/config"
+	"github.com/tdawidzi/dictionary_app/models"
+	"github.com/tdawidzi/dictionary_app/repository"
+	"github.com/tdawidzi/dictionary_app/utils"
+)
+
+const apiKeyUsage = "usage: dictionary_app apikey create <name>|list|revoke <name>"
+
+// apiKey runs "apikey" command - creates key for named client (key is printed only once), lists or revokes keys
+func apiKey(cfg *config.Config, args []string) error {
+	if len(args) == 0 || (args[0] != "list" && len(args) != 2) {
+		return errors.New(apiKeyUsage)
+	}
+	if cfg.DB_Driver == config.DriverMemory {
+		return errors.New("API keys cannot be stored in memory storage, set them in API_KEYS instead")
+	}
+
+	store, closeStore, err := utils.OpenStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	switch args[0] {
+	case "create":
+		key, err := auth.GenerateAPIKey()
+		if err != nil {
+			return err
+		}
+		err = store.APIKeys().Create(&models.APIKey{Name: args[1], Hash: auth.HashAPIKey(key)})
+		if errors.Is(err, repository.ErrDuplicate) {
+			return fmt.Errorf("API key %s already exists", args[1])
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create API key: %w", err)
+		}
+		fmt.Printf("API key of %s (it is not stored, save it now):\n%s\n", args[1], key)
+		return nil
+	case "list":
+		keys, err := store.APIKeys().List()
+		if err != nil {
+			return fmt.Errorf("failed to query API keys: %w", err)
+		}
+		for _, key := range keys {
+			fmt.Printf("%s\tcreated %s\n", key.Name, key.CreatedAt.Format("2006-01-02 15:04:05"))
+		}
+		return nil
+	case "revoke":
+		err := store.APIKeys().Delete(args[1])
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("API key %s does not exist", args[1])
+		}
+		if err != nil {
+			return fmt.Errorf("failed to revoke API key: %w", err)
+		}
+		fmt.Printf("Revoked API key of %s\n", args[1])
+		return nil
+	}
+	return errors.New(apiKeyUsage)
+}
+
+// seedAPIKeys adds keys from API_KEYS to memory storage, which is empty at startup and cannot be changed by "apikey" command
+func seedAPIKeys(cfg *config.Config, store repository.Store) error {
+	if len(cfg.API_Keys) == 0 {
+		return nil
+	}
+	if cfg.DB_Driver != config.DriverMemory {
+		return errors.New("API_KEYS can be used only with memory storage, create keys with apikey command")
+	}
+
+	for i, entry := range cfg.API_Keys {
+		// Entry is not printed, it contains the key
+		name, key, ok := strings.Cut(entry, ":")
+		if !ok || name == "" || key == "" {
+			return fmt.Errorf("invalid API_KEYS entry %d: expected <name>:<key>", i+1)
+		}
+		err := store.APIKeys().Create(&models.APIKey{Name: name, Hash: auth.HashAPIKey(key)})
+		if errors.Is(err, repository.ErrDuplicate) {
+			return fmt.Errorf("API key %s is given twice in API_KEYS", name)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create API key: %w", err)
+		}
+	}
+	return nil
+}
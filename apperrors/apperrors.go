@@ -0,0 +1,104 @@
+// Package apperrors defines errors returned by API handlers. Error code is reported in GraphQL error extensions,
+// so clients can tell missing or invalid input from server failures.
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type Code string
+
+const (
+	CodeNotFound   Code = "NOT_FOUND"   // requested entity does not exist
+	CodeConflict   Code = "CONFLICT"    // entity already exists or was modified concurrently
+	CodeValidation Code = "VALIDATION"  // invalid input
+	CodeForbidden  Code = "FORBIDDEN"   // role of principal does not allow the operation
+	CodeBadRequest Code = "BAD_REQUEST" // invalid GraphQL document (syntax error, unknown field...)
+	CodeInternal   Code = "INTERNAL"    // unexpected failure, e.g. database outage
+)
+
+// Error is an error with code
+type Error struct {
+	Code   Code
+	Err    error
+	Fields []FieldError // invalid input fields, only for VALIDATION code
+
+	// CurrentVersion of entity updated based on outdated version, only for CONFLICT code
+	CurrentVersion *int
+}
+
+// FieldError describes why value of single input field is invalid
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Extensions implements gqlerrors.ExtendedError
+func (e *Error) Extensions() map[string]interface{} {
+	extensions := map[string]interface{}{"code": string(e.Code)}
+	if len(e.Fields) > 0 {
+		extensions["fields"] = e.Fields
+	}
+	if e.CurrentVersion != nil {
+		extensions["currentVersion"] = *e.CurrentVersion
+	}
+	return extensions
+}
+
+// NotFound formats error (like fmt.Errorf) with NOT_FOUND code
+func NotFound(format string, args ...interface{}) error {
+	return &Error{Code: CodeNotFound, Err: fmt.Errorf(format, args...)}
+}
+
+// Conflict formats error (like fmt.Errorf) with CONFLICT code
+func Conflict(format string, args ...interface{}) error {
+	return &Error{Code: CodeConflict, Err: fmt.Errorf(format, args...)}
+}
+
+// StaleVersion formats error (like fmt.Errorf) with CONFLICT code of update based on outdated version of entity.
+// Current version is reported in extensions.
+func StaleVersion(current int, format string, args ...interface{}) error {
+	return &Error{Code: CodeConflict, Err: fmt.Errorf(format, args...), CurrentVersion: &current}
+}
+
+// Forbidden formats error (like fmt.Errorf) with FORBIDDEN code
+func Forbidden(format string, args ...interface{}) error {
+	return &Error{Code: CodeForbidden, Err: fmt.Errorf(format, args...)}
+}
+
+// Validation formats error (like fmt.Errorf) with VALIDATION code
+func Validation(format string, args ...interface{}) error {
+	return &Error{Code: CodeValidation, Err: fmt.Errorf(format, args...)}
+}
+
+// InvalidFields creates VALIDATION error listing all invalid fields
+func InvalidFields(fields []FieldError) error {
+	messages := make([]string, len(fields))
+	for i, field := range fields {
+		messages[i] = field.Field + ": " + field.Message
+	}
+	return &Error{
+		Code:   CodeValidation,
+		Err:    fmt.Errorf("invalid input: %s", strings.Join(messages, "; ")),
+		Fields: fields,
+	}
+}
+
+// CodeOf returns code of the first coded error in chain, errors without code are internal
+func CodeOf(err error) Code {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return CodeInternal
+}
@@ -0,0 +1,53 @@
+package apperrors_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tdawidzi/dictionary_app/apperrors"
+)
+
+func TestCodeOf(t *testing.T) {
+	notFound := apperrors.NotFound("word not found: %w", errors.New("record not found"))
+	assert.Equal(t, apperrors.CodeNotFound, apperrors.CodeOf(notFound))
+	assert.EqualError(t, notFound, "word not found: record not found")
+
+	// Code is found in wrapped errors
+	wrapped := fmt.Errorf("failed to set paradigm: %w", apperrors.Conflict("form exists"))
+	assert.Equal(t, apperrors.CodeConflict, apperrors.CodeOf(wrapped))
+
+	assert.Equal(t, apperrors.CodeInternal, apperrors.CodeOf(errors.New("connection refused")))
+}
+
+func TestExtensions(t *testing.T) {
+	var err *apperrors.Error
+	assert.True(t, errors.As(apperrors.Validation("missing word"), &err))
+	assert.Equal(t, map[string]interface{}{"code": "VALIDATION"}, err.Extensions())
+}
+
+func TestInvalidFields(t *testing.T) {
+	err := apperrors.InvalidFields([]apperrors.FieldError{
+		{Field: "word", Message: "cannot be empty"},
+		{Field: "example", Message: "must contain the word"},
+	})
+	assert.Equal(t, apperrors.CodeValidation, apperrors.CodeOf(err))
+	assert.EqualError(t, err, "invalid input: word: cannot be empty; example: must contain the word")
+
+	var e *apperrors.Error
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, []apperrors.FieldError{
+		{Field: "word", Message: "cannot be empty"},
+		{Field: "example", Message: "must contain the word"},
+	}, e.Extensions()["fields"])
+}
+
+func TestStaleVersion(t *testing.T) {
+	err := apperrors.StaleVersion(3, "word was modified meanwhile")
+	assert.Equal(t, apperrors.CodeConflict, apperrors.CodeOf(err))
+
+	var e *apperrors.Error
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, map[string]interface{}{"code": "CONFLICT", "currentVersion": 3}, e.Extensions())
+}
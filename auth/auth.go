@@ -0,0 +1,192 @@
+// Package auth authenticates clients of the API - by API keys stored (hashed) in the database,
+// or by JWTs signed with locally configured keys. Authenticated principal is passed to resolvers in request context.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/tdawidzi/dictionary_app/models"
+	"github.com/tdawidzi/dictionary_app/repository"
+)
+
+// Headers with credentials
+const (
+	APIKeyHeader        = "X-API-Key"
+	AuthorizationHeader = "Authorization" // "Bearer <JWT>"
+)
+
+// Authentication methods
+const (
+	MethodAPIKey = "api_key"
+	MethodJWT    = "jwt"
+)
+
+// apiKeyPrefix makes keys easy to recognize (e.g. by secret scanners)
+const apiKeyPrefix = "dk_"
+
+// ErrInvalidCredentials - credentials of request are wrong (unknown key, invalid or expired token)
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// Prefixes of principal IDs, by authentication method
+const (
+	apiKeyIDPrefix = "apikey:"
+	jwtIDPrefix    = "jwt:"
+)
+
+// Principal - authenticated client
+type Principal struct {
+	Name   string // name of API key or subject of JWT
+	Issuer string // issuer of JWT
+	Method string
+	Role   string // see models.Roles
+}
+
+// ID identifies principal among clients of all authentication methods - "apikey:<name>" or "jwt:<issuer>/<subject>".
+// Roles are given to IDs, so a JWT subject never gets role of API key with the same name (or vice versa).
+func (p Principal) ID() string {
+	switch p.Method {
+	case MethodAPIKey:
+		return apiKeyIDPrefix + p.Name
+	case MethodJWT:
+		return jwtIDPrefix + p.Issuer + "/" + p.Name
+	}
+	return p.Name
+}
+
+// ValidID checks if principal ID names authentication method and client
+func ValidID(id string) bool {
+	name, ok := strings.CutPrefix(id, apiKeyIDPrefix)
+	if !ok {
+		name, ok = strings.CutPrefix(id, jwtIDPrefix)
+		ok = ok && strings.Contains(name, "/")
+	}
+	return ok && name != ""
+}
+
+type principalKey struct{}
+
+// WithPrincipal attaches authenticated principal to request context
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFrom returns principal of request, false for anonymous requests
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	if ctx == nil {
+		return Principal{}, false
+	}
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok
+}
+
+// RoleFrom returns role of request principal - anonymous requests are viewers
+func RoleFrom(ctx context.Context) string {
+	if principal, ok := PrincipalFrom(ctx); ok {
+		return principal.Role
+	}
+	return models.RoleViewer
+}
+
+// Allows checks if role has permissions of required role (every role has permissions of the lower ones)
+func Allows(role, required string) bool {
+	return slices.Index(models.Roles, role) >= slices.Index(models.Roles, required)
+}
+
+// Authenticator checks credentials of requests
+type Authenticator struct {
+	store  repository.Store
+	jwt    *JWTVerifier // nil when JWTs are not accepted
+	admins []string
+}
+
+// New creates authenticator accepting API keys of given store and JWTs checked by given verifier (optional).
+// Roles of principals are read from users of the store, principals with given IDs are always admins.
+func New(store repository.Store, jwt *JWTVerifier, admins ...string) *Authenticator {
+	return &Authenticator{store: store, jwt: jwt, admins: admins}
+}
+
+// Authenticate returns principal of request credentials - API key in X-API-Key header or bearer JWT in Authorization header.
+// Returns false if request has no credentials, ErrInvalidCredentials if they are wrong.
+func (a *Authenticator) Authenticate(r *http.Request) (Principal, bool, error) {
+	principal, ok, err := a.principal(r)
+	if !ok || err != nil {
+		return principal, false, err
+	}
+	if principal.Role, err = a.role(principal.ID()); err != nil {
+		return principal, false, err
+	}
+	return principal, true, nil
+}
+
+// role gives role of principal with given ID - viewer if it is not a user
+func (a *Authenticator) role(id string) (string, error) {
+	if slices.Contains(a.admins, id) {
+		return models.RoleAdmin, nil
+	}
+	user, err := a.store.Users().Find(id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return models.RoleViewer, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to query user: %w", err)
+	}
+	return user.Role, nil
+}
+
+func (a *Authenticator) principal(r *http.Request) (Principal, bool, error) {
+	key := r.Header.Get(APIKeyHeader)
+	authorization := r.Header.Get(AuthorizationHeader)
+	switch {
+	case key != "" && authorization != "":
+		return Principal{}, false, fmt.Errorf("%w: both API key and authorization given", ErrInvalidCredentials)
+	case key != "":
+		principal, err := a.apiKeyPrincipal(key)
+		return principal, err == nil, err
+	case authorization != "":
+		scheme, token, _ := strings.Cut(authorization, " ")
+		if !strings.EqualFold(scheme, "Bearer") || token == "" {
+			return Principal{}, false, fmt.Errorf("%w: expected bearer token", ErrInvalidCredentials)
+		}
+		if a.jwt == nil {
+			return Principal{}, false, fmt.Errorf("%w: JWT authentication is not configured", ErrInvalidCredentials)
+		}
+		principal, err := a.jwt.Verify(strings.TrimSpace(token))
+		return principal, err == nil, err
+	}
+	return Principal{}, false, nil
+}
+
+func (a *Authenticator) apiKeyPrincipal(key string) (Principal, error) {
+	found, err := a.store.APIKeys().FindByHash(HashAPIKey(key))
+	if errors.Is(err, repository.ErrNotFound) {
+		return Principal{}, fmt.Errorf("%w: unknown API key", ErrInvalidCredentials)
+	}
+	if err != nil {
+		return Principal{}, fmt.Errorf("failed to query API key: %w", err)
+	}
+	return Principal{Name: found.Name, Method: MethodAPIKey}, nil
+}
+
+// GenerateAPIKey returns new random API key - only its hash is stored
+func GenerateAPIKey() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	return apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// HashAPIKey gives hash of API key stored in the database. Keys are random, so plain SHA-256 is enough (no salt).
+func HashAPIKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,195 @@
+package auth_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tdawidzi/dictionary_app/auth"
+	"github.com/tdawidzi/dictionary_app/models"
+	"github.com/tdawidzi/dictionary_app/repository"
+)
+
+var secret = []byte("0123456789abcdef0123456789abcdef")
+
+// 2100-01-01 and 2000-01-01
+const (
+	future = "4102444800"
+	past   = "946684800"
+)
+
+func encode(data string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(data))
+}
+
+func signHS256(header, claims string, key []byte) string {
+	signed := encode(header) + "." + encode(claims)
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func signRS256(t *testing.T, claims string, key *rsa.PrivateKey) string {
+	signed := encode(`{"alg":"RS256","typ":"JWT"}`) + "." + encode(claims)
+	hash := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+	assert.NoError(t, err)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func request(headers map[string]string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader("{}"))
+	for name, value := range headers {
+		r.Header.Set(name, value)
+	}
+	return r
+}
+
+func TestAPIKeys(t *testing.T) {
+	key, err := auth.GenerateAPIKey()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(key, "dk_"))
+	assert.NotEqual(t, key, auth.HashAPIKey(key))
+
+	store := repository.NewMemoryStore()
+	keys := store.APIKeys()
+	assert.NoError(t, keys.Create(&models.APIKey{Name: "importer", Hash: auth.HashAPIKey(key)}))
+	authenticator := auth.New(store, nil)
+
+	principal, ok, err := authenticator.Authenticate(request(map[string]string{auth.APIKeyHeader: key}))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, auth.Principal{Name: "importer", Method: auth.MethodAPIKey, Role: models.RoleViewer}, principal)
+	assert.Equal(t, "apikey:importer", principal.ID())
+
+	// No credentials - anonymous request
+	_, ok, err = authenticator.Authenticate(request(nil))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// Unknown or revoked key, JWT not configured
+	_, _, err = authenticator.Authenticate(request(map[string]string{auth.APIKeyHeader: key + "x"}))
+	assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+	_, _, err = authenticator.Authenticate(request(map[string]string{auth.AuthorizationHeader: "Bearer " + signHS256(`{"alg":"HS256"}`, `{"sub":"ola","exp":`+future+`}`, secret)}))
+	assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+	assert.NoError(t, keys.Delete("importer"))
+	_, _, err = authenticator.Authenticate(request(map[string]string{auth.APIKeyHeader: key}))
+	assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+}
+
+func TestHS256(t *testing.T) {
+	verifier, err := auth.NewJWTVerifier(auth.JWTKeys{HS256Secret: secret, Issuer: "https://login.example.com", Audience: "dictionary"})
+	assert.NoError(t, err)
+	authenticator := auth.New(repository.NewMemoryStore(), verifier)
+
+	header := `{"alg":"HS256","typ":"JWT"}`
+	valid := `{"sub":"ola","iss":"https://login.example.com","aud":["dictionary","other"],"exp":` + future + `}`
+	principal, ok, err := authenticator.Authenticate(request(map[string]string{auth.AuthorizationHeader: "Bearer " + signHS256(header, valid, secret)}))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, auth.Principal{Name: "ola", Issuer: "https://login.example.com", Method: auth.MethodJWT, Role: models.RoleViewer}, principal)
+	assert.Equal(t, "jwt:https://login.example.com/ola", principal.ID())
+
+	for name, token := range map[string]string{
+		"wrong secret":    signHS256(header, valid, []byte("fedcba9876543210fedcba9876543210")),
+		"unsigned":        encode(`{"alg":"none"}`) + "." + encode(valid) + ".",
+		"expired":         signHS256(header, `{"sub":"ola","iss":"https://login.example.com","aud":"dictionary","exp":`+past+`}`, secret),
+		"not valid yet":   signHS256(header, `{"sub":"ola","iss":"https://login.example.com","aud":"dictionary","exp":`+future+`,"nbf":`+future+`}`, secret),
+		"no expiration":   signHS256(header, `{"sub":"ola","iss":"https://login.example.com","aud":"dictionary"}`, secret),
+		"no subject":      signHS256(header, `{"iss":"https://login.example.com","aud":"dictionary","exp":`+future+`}`, secret),
+		"other issuer":    signHS256(header, `{"sub":"ola","iss":"https://evil.example.com","aud":"dictionary","exp":`+future+`}`, secret),
+		"other audience":  signHS256(header, `{"sub":"ola","iss":"https://login.example.com","aud":"other","exp":`+future+`}`, secret),
+		"malformed token": "abc.def",
+	} {
+		_, err := verifier.Verify(token)
+		assert.ErrorIs(t, err, auth.ErrInvalidCredentials, name)
+	}
+
+	// Secrets which could be brute-forced are refused
+	_, err = auth.NewJWTVerifier(auth.JWTKeys{HS256Secret: []byte("secret")})
+	assert.Error(t, err)
+	verifier, err = auth.NewJWTVerifier(auth.JWTKeys{})
+	assert.NoError(t, err)
+	assert.Nil(t, verifier)
+}
+
+func TestRS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	assert.NoError(t, err)
+	publicKey := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	verifier, err := auth.NewJWTVerifier(auth.JWTKeys{RS256PublicKey: publicKey})
+	assert.NoError(t, err)
+	principal, err := verifier.Verify(signRS256(t, `{"sub":"ola","exp":`+future+`}`, key))
+	assert.NoError(t, err)
+	assert.Equal(t, "ola", principal.Name)
+
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	_, err = verifier.Verify(signRS256(t, `{"sub":"ola","exp":`+future+`}`, other))
+	assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+
+	// HS256 token signed with the public key is not accepted by RS256 verifier
+	_, err = verifier.Verify(signHS256(`{"alg":"HS256"}`, `{"sub":"ola","exp":`+future+`}`, publicKey))
+	assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+
+	_, err = auth.NewJWTVerifier(auth.JWTKeys{RS256PublicKey: []byte("not a key")})
+	assert.Error(t, err)
+}
+
+func TestRoles(t *testing.T) {
+	store := repository.NewMemoryStore()
+	assert.NoError(t, store.APIKeys().Create(&models.APIKey{Name: "ala", Hash: auth.HashAPIKey("dk_ala")}))
+	assert.NoError(t, store.APIKeys().Create(&models.APIKey{Name: "ola", Hash: auth.HashAPIKey("dk_ola")}))
+	assert.NoError(t, store.Users().Create(&models.User{Name: "apikey:ala", Role: models.RoleEditor}))
+	assert.NoError(t, store.Users().Create(&models.User{Name: "apikey:ola", Role: models.RoleViewer}))
+	verifier, err := auth.NewJWTVerifier(auth.JWTKeys{HS256Secret: secret})
+	assert.NoError(t, err)
+
+	// Configured admins are admins whatever their user role is
+	authenticator := auth.New(store, verifier, "apikey:ola")
+	for key, role := range map[string]string{"dk_ala": models.RoleEditor, "dk_ola": models.RoleAdmin} {
+		principal, ok, err := authenticator.Authenticate(request(map[string]string{auth.APIKeyHeader: key}))
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, role, principal.Role)
+		assert.Equal(t, role, auth.RoleFrom(auth.WithPrincipal(context.Background(), principal)))
+	}
+	assert.Equal(t, models.RoleViewer, auth.RoleFrom(context.Background()))
+
+	// Roles of API keys are not given to JWT subjects with the same name
+	for _, sub := range []string{"ala", "ola"} {
+		token := signHS256(`{"alg":"HS256"}`, `{"sub":"`+sub+`","exp":`+future+`}`, secret)
+		principal, ok, err := authenticator.Authenticate(request(map[string]string{auth.AuthorizationHeader: "Bearer " + token}))
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "jwt:/"+sub, principal.ID())
+		assert.Equal(t, models.RoleViewer, principal.Role)
+	}
+	assert.NoError(t, store.Users().Create(&models.User{Name: "jwt:/ala", Role: models.RoleAdmin}))
+	principal, _, err := authenticator.Authenticate(request(map[string]string{auth.AuthorizationHeader: "Bearer " + signHS256(`{"alg":"HS256"}`, `{"sub":"ala","exp":`+future+`}`, secret)}))
+	assert.NoError(t, err)
+	assert.Equal(t, models.RoleAdmin, principal.Role)
+
+	for id, valid := range map[string]bool{"apikey:ala": true, "jwt:/ala": true, "jwt:https://login.example.com/ala": true, "ala": false, "apikey:": false, "jwt:ala": false} {
+		assert.Equal(t, valid, auth.ValidID(id), id)
+	}
+
+	assert.True(t, auth.Allows(models.RoleAdmin, models.RoleEditor))
+	assert.True(t, auth.Allows(models.RoleEditor, models.RoleEditor))
+	assert.False(t, auth.Allows(models.RoleViewer, models.RoleEditor))
+	assert.False(t, auth.Allows(models.RoleEditor, models.RoleAdmin))
+}
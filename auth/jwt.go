@@ -0,0 +1,209 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Algorithms of accepted tokens
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+)
+
+// minHS256SecretLength - shorter secrets could be brute-forced from a single token
+const minHS256SecretLength = 32
+
+// clockSkew - tolerance of expiration and "not before" times, for clocks of token issuer and the server
+const clockSkew = time.Minute
+
+// JWTKeys - keys of accepted tokens (at least one of them), and required claims
+type JWTKeys struct {
+	HS256Secret    []byte
+	RS256PublicKey []byte // PEM encoded (PUBLIC KEY or RSA PUBLIC KEY)
+	Issuer         string // required "iss" claim, empty - any
+	Audience       string // required "aud" claim, empty - any
+}
+
+// JWTVerifier checks signed tokens (JWS compact serialization). Tokens need subject and expiration time.
+// Algorithm of token has to be one of configured keys - unsigned tokens are never accepted.
+type JWTVerifier struct {
+	hs256Secret    []byte
+	rs256PublicKey *rsa.PublicKey
+	issuer         string
+	audience       string
+	now            func() time.Time
+}
+
+// NewJWTVerifier creates verifier of tokens signed with given keys. Returns nil if no key is given.
+func NewJWTVerifier(keys JWTKeys) (*JWTVerifier, error) {
+	if len(keys.HS256Secret) == 0 && len(keys.RS256PublicKey) == 0 {
+		return nil, nil
+	}
+
+	v := &JWTVerifier{issuer: keys.Issuer, audience: keys.Audience, now: time.Now}
+	if len(keys.HS256Secret) > 0 {
+		if len(keys.HS256Secret) < minHS256SecretLength {
+			return nil, fmt.Errorf("HS256 secret must have at least %d bytes", minHS256SecretLength)
+		}
+		v.hs256Secret = keys.HS256Secret
+	}
+	if len(keys.RS256PublicKey) > 0 {
+		key, err := parseRSAPublicKey(keys.RS256PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		v.rs256PublicKey = key
+	}
+	return v, nil
+}
+
+func parseRSAPublicKey(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid RS256 public key: no PEM data")
+	}
+	if block.Type == "RSA PUBLIC KEY" {
+		key, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RS256 public key: %w", err)
+		}
+		return key, nil
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RS256 public key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("invalid RS256 public key: not an RSA key")
+	}
+	return key, nil
+}
+
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+}
+
+type jwtClaims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt *float64 `json:"exp"`
+	NotBefore *float64 `json:"nbf"`
+}
+
+// audience claim is a single string or an array of strings
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(a))
+}
+
+// Verify checks signature and claims of token, returns its subject as principal
+func (v *JWTVerifier) Verify(token string) (Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Principal{}, invalidToken("malformed token")
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return Principal{}, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Principal{}, invalidToken("malformed signature")
+	}
+	if err := v.verifySignature(header.Algorithm, parts[0]+"."+parts[1], signature); err != nil {
+		return Principal{}, err
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return Principal{}, err
+	}
+	if err := v.checkClaims(claims); err != nil {
+		return Principal{}, err
+	}
+	return Principal{Name: claims.Subject, Issuer: claims.Issuer, Method: MethodJWT}, nil
+}
+
+func (v *JWTVerifier) verifySignature(algorithm, signed string, signature []byte) error {
+	switch {
+	case algorithm == AlgorithmHS256 && v.hs256Secret != nil:
+		mac := hmac.New(sha256.New, v.hs256Secret)
+		mac.Write([]byte(signed))
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return invalidToken("invalid signature")
+		}
+	case algorithm == AlgorithmRS256 && v.rs256PublicKey != nil:
+		hash := sha256.Sum256([]byte(signed))
+		if err := rsa.VerifyPKCS1v15(v.rs256PublicKey, crypto.SHA256, hash[:], signature); err != nil {
+			return invalidToken("invalid signature")
+		}
+	default:
+		return invalidToken("unsupported algorithm %q", algorithm)
+	}
+	return nil
+}
+
+func (v *JWTVerifier) checkClaims(claims jwtClaims) error {
+	now := v.now()
+	if claims.Subject == "" {
+		return invalidToken("missing subject")
+	}
+	if claims.ExpiresAt == nil {
+		return invalidToken("missing expiration time")
+	}
+	if now.After(unixTime(*claims.ExpiresAt).Add(clockSkew)) {
+		return invalidToken("token expired")
+	}
+	if claims.NotBefore != nil && now.Before(unixTime(*claims.NotBefore).Add(-clockSkew)) {
+		return invalidToken("token not valid yet")
+	}
+	if v.issuer != "" && claims.Issuer != v.issuer {
+		return invalidToken("unexpected issuer")
+	}
+	if v.audience != "" && !slices.Contains(claims.Audience, v.audience) {
+		return invalidToken("unexpected audience")
+	}
+	return nil
+}
+
+// decodeSegment decodes JSON of token header or claims
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return invalidToken("malformed token")
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return invalidToken("malformed token")
+	}
+	return nil
+}
+
+// unixTime converts NumericDate claim, fractions of seconds are ignored
+func unixTime(seconds float64) time.Time {
+	return time.Unix(int64(seconds), 0)
+}
+
+func invalidToken(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidCredentials, fmt.Sprintf(format, args...))
+}
@@ -3,17 +3,47 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
 
+// Storage drivers (DB_DRIVER)
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite" // single file database, for offline use without PostgreSQL
+	DriverMemory   = "memory" // data is kept in memory only, for tests and demos
+)
+
+// Database file used by SQLite driver when SQLITE_PATH is not set
+const DefaultSQLitePath = "dictionary.db"
+
+// Days deleted entries stay in trash when PURGE_AFTER_DAYS is not set - purging is disabled,
+// permanent deletion of entries has to be enabled explicitly
+const DefaultPurgeAfterDays = 0
+
 // Config struct -  stores configuration info
 type Config struct {
+	DB_Driver   string
 	DB_Host     string
 	DB_Port     string
 	DB_User     string
 	DB_Password string
 	DB_Name     string
+	DB_Path     string // SQLite database file
+
+	Purge_After_Days int // deleted entries older than that are purged from trash, 0 keeps them forever
+
+	// JWT authentication, tokens are not accepted when no key is set
+	JWT_HS256_Secret     string // secret of HS256 signed tokens
+	JWT_RS256_Public_Key string // PEM file with public key of RS256 signed tokens
+	JWT_Issuer           string // required issuer of tokens, empty - any
+	JWT_Audience         string // required audience of tokens, empty - any
+
+	Admins []string // IDs of principals which are always admins (e.g. to add first users), see auth.Principal.ID
+
+	API_Keys []string // "<name>:<key>" entries added to memory storage at startup ("apikey" command needs a database)
 }
 
 // Load config from .env file - returns pointer to config struct and error
@@ -24,11 +54,25 @@ func Load(envpath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to load config from environment file: %w", err)
 	}
 	config := &Config{
+		DB_Driver:   driver(os.Getenv("DB_DRIVER")),
 		DB_Host:     os.Getenv("DB_HOST"),
 		DB_Port:     os.Getenv("DB_PORT"),
 		DB_User:     os.Getenv("POSTGRES_USER"),
 		DB_Password: os.Getenv("POSTGRES_PASSWORD"),
 		DB_Name:     os.Getenv("POSTGRES_DB"),
+		DB_Path:     sqlitePath(os.Getenv("SQLITE_PATH")),
+
+		JWT_HS256_Secret:     os.Getenv("JWT_HS256_SECRET"),
+		JWT_RS256_Public_Key: os.Getenv("JWT_RS256_PUBLIC_KEY"),
+		JWT_Issuer:           os.Getenv("JWT_ISSUER"),
+		JWT_Audience:         os.Getenv("JWT_AUDIENCE"),
+
+		Admins:   names(os.Getenv("ADMINS")),
+		API_Keys: names(os.Getenv("API_KEYS")),
+	}
+	config.Purge_After_Days, err = purgeAfterDays(os.Getenv("PURGE_AFTER_DAYS"))
+	if err != nil {
+		return nil, err
 	}
 	return config, nil
 }
@@ -40,11 +84,50 @@ func LoadTest(envpath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to load config from environment file: %w", err)
 	}
 	config := &Config{
+		DB_Driver:   driver(os.Getenv("DB_DRIVER_TEST")),
 		DB_Host:     os.Getenv("DB_HOST_TEST"),
 		DB_Port:     os.Getenv("DB_PORT_TEST"),
 		DB_User:     os.Getenv("POSTGRES_USER_TEST"),
 		DB_Password: os.Getenv("POSTGRES_PASSWORD_TEST"),
 		DB_Name:     os.Getenv("POSTGRES_DB_TEST"),
+		DB_Path:     sqlitePath(os.Getenv("SQLITE_PATH_TEST")),
 	}
 	return config, nil
 }
+
+// driver defaults to PostgreSQL
+func driver(name string) string {
+	if name == "" {
+		return DriverPostgres
+	}
+	return name
+}
+
+func sqlitePath(path string) string {
+	if path == "" {
+		return DefaultSQLitePath
+	}
+	return path
+}
+
+func purgeAfterDays(value string) (int, error) {
+	if value == "" {
+		return DefaultPurgeAfterDays, nil
+	}
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 0 {
+		return 0, fmt.Errorf("invalid PURGE_AFTER_DAYS %q: expected number of days", value)
+	}
+	return days, nil
+}
+
+// names splits comma separated list of names
+func names(value string) []string {
+	var result []string
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			result = append(result, name)
+		}
+	}
+	return result
+}
@@ -0,0 +1,69 @@
+// Package dataloader collapses lookups of many keys, requested independently by nested GraphQL resolvers,
+// into single batch query (e.g. translations of all words on a page in one "IN (...)" query).
+package dataloader
+
+import "sync"
+
+// BatchFunc loads values of all given keys at once. Keys missing in result get zero value.
+type BatchFunc[K comparable, V any] func(keys []K) (map[K]V, error)
+
+// Loader collects keys requested with Load and loads them in one batch, when the first value is needed.
+// Loaded values are cached for loader lifetime - loader should live as long as single request.
+type Loader[K comparable, V any] struct {
+	batch BatchFunc[K, V]
+
+	mu      sync.Mutex
+	pending []K
+	queued  map[K]bool
+	cache   map[K]result[V]
+}
+
+type result[V any] struct {
+	value V
+	err   error
+}
+
+func New[K comparable, V any](batch BatchFunc[K, V]) *Loader[K, V] {
+	return &Loader[K, V]{
+		batch:  batch,
+		queued: map[K]bool{},
+		cache:  map[K]result[V]{},
+	}
+}
+
+// Load queues key for next batch and returns thunk reading its value.
+// Batch is dispatched by the first thunk called, so all keys queued before are loaded together.
+func (l *Loader[K, V]) Load(key K) func() (V, error) {
+	l.mu.Lock()
+	if _, cached := l.cache[key]; !cached && !l.queued[key] {
+		l.queued[key] = true
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (V, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if _, cached := l.cache[key]; !cached {
+			l.dispatch()
+		}
+		r := l.cache[key]
+		return r.value, r.err
+	}
+}
+
+// dispatch loads all pending keys, has to be called with mutex locked
+func (l *Loader[K, V]) dispatch() {
+	keys := l.pending
+	l.pending = nil
+	l.queued = map[K]bool{}
+	if len(keys) == 0 {
+		return
+	}
+
+	values, err := l.batch(keys)
+	for _, key := range keys {
+		l.cache[key] = result[V]{value: values[key], err: err}
+	}
+}
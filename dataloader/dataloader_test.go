@@ -0,0 +1,68 @@
+package dataloader_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tdawidzi/dictionary_app/dataloader"
+)
+
+func TestLoaderBatchesKeys(t *testing.T) {
+	var batches [][]int
+	loader := dataloader.New(func(keys []int) (map[int]string, error) {
+		batches = append(batches, keys)
+		values := map[int]string{}
+		for _, key := range keys {
+			if key != 3 {
+				values[key] = string(rune('a' + key))
+			}
+		}
+		return values, nil
+	})
+
+	first := loader.Load(0)
+	second := loader.Load(1)
+	missing := loader.Load(3)
+	again := loader.Load(0)
+
+	value, err := second()
+	assert.NoError(t, err)
+	assert.Equal(t, "b", value)
+
+	value, err = first()
+	assert.NoError(t, err)
+	assert.Equal(t, "a", value)
+
+	value, err = again()
+	assert.NoError(t, err)
+	assert.Equal(t, "a", value)
+
+	value, err = missing()
+	assert.NoError(t, err)
+	assert.Equal(t, "", value)
+
+	// Keys queued after dispatch go to the next batch, loaded keys are cached
+	third := loader.Load(2)
+	cached := loader.Load(1)
+	value, _ = third()
+	assert.Equal(t, "c", value)
+	value, _ = cached()
+	assert.Equal(t, "b", value)
+
+	assert.Equal(t, [][]int{{0, 1, 3}, {2}}, batches)
+}
+
+func TestLoaderBatchError(t *testing.T) {
+	loader := dataloader.New(func(keys []int) (map[int]string, error) {
+		return nil, errors.New("failed")
+	})
+
+	first := loader.Load(1)
+	second := loader.Load(2)
+
+	_, err := first()
+	assert.Error(t, err)
+	_, err = second()
+	assert.Error(t, err)
+}
@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/tdawidzi/dictionary_app/auth"
+	"github.com/tdawidzi/dictionary_app/models"
+	"github.com/tdawidzi/dictionary_app/repository"
+
+	"github.com/graphql-go/graphql"
+)
+
+// Every mutation records its changes in audit log, in transaction of the change - change is made only if it is recorded.
+// Values of entities before and after change are recorded as JSON, only fields set by users (no derived keys, no timestamps).
+
+// Anonymous - actor of changes made without authenticated principal (e.g. by jobs)
+const Anonymous = "anonymous"
+
+// actorFrom returns actor of request - ID of its principal
+func actorFrom(p graphql.ResolveParams) string {
+	if principal, ok := auth.PrincipalFrom(p.Context); ok {
+		return principal.ID()
+	}
+	return Anonymous
+}
+
+type languageValues struct {
+	Code         string `json:"code"`
+	Name         string `json:"name"`
+	Script       string `json:"script"`
+	Direction    string `json:"direction"`
+	SearchConfig string `json:"searchConfig"`
+}
+
+func newLanguageValues(l models.Language) languageValues {
+	return languageValues{Code: l.Code, Name: l.Name, Script: l.Script, Direction: l.Direction, SearchConfig: l.SearchConfig}
+}
+
+type dictionaryValues struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func newDictionaryValues(d models.Dictionary) dictionaryValues {
+	return dictionaryValues{Name: d.Name, Description: d.Description}
+}
+
+type wordValues struct {
+	Word         string `json:"word"`
+	Language     string `json:"language"`
+	Homograph    int    `json:"homograph"`
+	PartOfSpeech string `json:"partOfSpeech"`
+	Gender       string `json:"gender"`
+	Aspect       string `json:"aspect"`
+	Countability string `json:"countability"`
+	Transitivity string `json:"transitivity"`
+}
+
+func newWordValues(w models.Word) wordValues {
+	return wordValues{
+		Word:         w.Word,
+		Language:     w.Language,
+		Homograph:    w.Homograph,
+		PartOfSpeech: w.PartOfSpeech,
+		Gender:       w.Gender,
+		Aspect:       w.Aspect,
+		Countability: w.Countability,
+		Transitivity: w.Transitivity,
+	}
+}
+
+// apply sets values of word - language of word cannot change
+func (v wordValues) apply(w *models.Word) {
+	w.Word, w.Homograph = v.Word, v.Homograph
+	w.PartOfSpeech, w.Gender, w.Aspect = v.PartOfSpeech, v.Gender, v.Aspect
+	w.Countability, w.Transitivity = v.Countability, v.Transitivity
+}
+
+type formValues struct {
+	Form   string `json:"form"`
+	Case   string `json:"case"`
+	Number string `json:"number"`
+	Person string `json:"person"`
+	Tense  string `json:"tense"`
+	Mood   string `json:"mood"`
+	Aspect string `json:"aspect"`
+	Gender string `json:"gender"`
+	Degree string `json:"degree"`
+}
+
+// newParadigmValues - paradigm is recorded as list of forms (empty list when word has no forms)
+func newParadigmValues(forms []models.WordForm) []formValues {
+	values := make([]formValues, 0, len(forms))
+	for _, f := range forms {
+		values = append(values, formValues{
+			Form: f.Form, Case: f.Case, Number: f.Number, Person: f.Person, Tense: f.Tense,
+			Mood: f.Mood, Aspect: f.Aspect, Gender: f.Gender, Degree: f.Degree,
+		})
+	}
+	return values
+}
+
+func (v formValues) form(wordID uint) models.WordForm {
+	return models.WordForm{
+		WordID: wordID, Form: v.Form, Case: v.Case, Number: v.Number, Person: v.Person, Tense: v.Tense,
+		Mood: v.Mood, Aspect: v.Aspect, Gender: v.Gender, Degree: v.Degree,
+	}
+}
+
+type senseValues struct {
+	WordID     uint   `json:"wordId"`
+	Ordinal    int    `json:"ordinal"`
+	Definition string `json:"definition"`
+	Domain     string `json:"domain"`
+}
+
+func newSenseValues(s models.Sense) senseValues {
+	return senseValues{WordID: s.WordID, Ordinal: s.Ordinal, Definition: s.Definition, Domain: s.Domain}
+}
+
+type translationValues struct {
+	SourceWordID  uint  `json:"sourceWordId"`
+	TargetWordID  uint  `json:"targetWordId"`
+	SourceSenseID *uint `json:"sourceSenseId"`
+	TargetSenseID *uint `json:"targetSenseId"`
+}
+
+func newTranslationValues(t models.Translation) translationValues {
+	return translationValues{
+		SourceWordID:  t.SourceWordID,
+		TargetWordID:  t.TargetWordID,
+		SourceSenseID: t.SourceSenseID,
+		TargetSenseID: t.TargetSenseID,
+	}
+}
+
+type exampleValues struct {
+	WordID  uint   `json:"wordId"`
+	Example string `json:"example"`
+	SenseID *uint  `json:"senseId"`
+}
+
+func newExampleValues(e models.Example) exampleValues {
+	return exampleValues{WordID: e.WordID, Example: e.Example, SenseID: e.SenseID}
+}
+
+type userValues struct {
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+func newUserValues(u models.User) userValues {
+	return userValues{Name: u.Name, Role: u.Role}
+}
+
+// change of single entity
+type change struct {
+	entity    string
+	operation string
+	id        uint        // id of entity (word id for paradigm)
+	word      uint        // word the entity belongs to, source word of translation (0 - none)
+	target    uint        // target word of translation
+	before    interface{} // values of entity before change, nil when entity did not exist
+	after     interface{} // values of entity after change, nil when entity does not exist anymore
+	revertOf  *uint
+}
+
+func languageChange(operation string, before, after interface{}) change {
+	return change{entity: models.EntityLanguage, operation: operation, before: before, after: after}
+}
+
+func dictionaryChange(operation string, d models.Dictionary, before, after interface{}) change {
+	return change{entity: models.EntityDictionary, operation: operation, id: d.ID, before: before, after: after}
+}
+
+func wordChange(operation string, w models.Word, before, after interface{}) change {
+	return change{entity: models.EntityWord, operation: operation, id: w.ID, word: w.ID, before: before, after: after}
+}
+
+func paradigmChange(wordID uint, before, after []formValues) change {
+	return change{entity: models.EntityParadigm, operation: models.OperationUpdate, id: wordID, word: wordID, before: before, after: after}
+}
+
+func senseChange(operation string, s models.Sense, before, after interface{}) change {
+	return change{entity: models.EntitySense, operation: operation, id: s.ID, word: s.WordID, before: before, after: after}
+}
+
+func translationChange(operation string, t models.Translation, before, after interface{}) change {
+	return change{
+		entity:    models.EntityTranslation,
+		operation: operation,
+		id:        t.ID,
+		word:      t.SourceWordID,
+		target:    t.TargetWordID,
+		before:    before,
+		after:     after,
+	}
+}
+
+func exampleChange(operation string, e models.Example, before, after interface{}) change {
+	return change{entity: models.EntityExample, operation: operation, id: e.ID, word: e.WordID, before: before, after: after}
+}
+
+func userChange(operation string, u models.User, before, after interface{}) change {
+	return change{entity: models.EntityUser, operation: operation, id: u.ID, before: before, after: after}
+}
+
+// record appends change to audit log. Updates which did not change any value are not recorded.
+func record(tx repository.Store, p graphql.ResolveParams, c change) (models.AuditEntry, error) {
+	entry := models.AuditEntry{
+		Entity:    c.entity,
+		EntityID:  c.id,
+		Operation: c.operation,
+		Actor:     actorFrom(p),
+		RevertOf:  c.revertOf,
+	}
+	if c.word != 0 {
+		entry.WordID = &c.word
+	}
+	if c.target != 0 {
+		entry.TargetWordID = &c.target
+	}
+
+	var err error
+	if entry.Before, err = encodeValues(c.before); err != nil {
+		return entry, err
+	}
+	if entry.After, err = encodeValues(c.after); err != nil {
+		return entry, err
+	}
+	if entry.Operation == models.OperationUpdate && entry.Before == entry.After {
+		return entry, nil
+	}
+
+	if err := tx.Audit().Append(&entry); err != nil {
+		return entry, fmt.Errorf("failed to record change: %w", err)
+	}
+	return entry, nil
+}
+
+// encodeValues gives JSON of entity values, empty string for entity which does not exist
+func encodeValues(values interface{}) (string, error) {
+	if values == nil {
+		return "", nil
+	}
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode values: %w", err)
+	}
+	return string(encoded), nil
+}
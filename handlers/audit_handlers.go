@@ -0,0 +1,317 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tdawidzi/dictionary_app/apperrors"
+	"github.com/tdawidzi/dictionary_app/models"
+	"github.com/tdawidzi/dictionary_app/repository"
+
+	"github.com/graphql-go/graphql"
+)
+
+const (
+	defaultAuditLogLimit = 100
+	maxAuditLogLimit     = 1000
+)
+
+// GetHistory lists changes of word with given id and of its paradigm, senses, translations and examples, oldest first.
+// History stays when word is purged.
+func (h *Handlers) GetHistory(p graphql.ResolveParams) (interface{}, error) {
+	id, ok := p.Args["wordId"].(int)
+	if !ok {
+		return nil, apperrors.Validation("invalid or missing word ID")
+	}
+
+	entries, err := h.store.Audit().ForWord(uint(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history: %w", err)
+	}
+	return entries, nil
+}
+
+// GetAuditLog lists changes made since given time (optionally by given actor), oldest first.
+// Long logs are read in parts - next part starts at time of the last change read.
+func (h *Handlers) GetAuditLog(p graphql.ResolveParams) (interface{}, error) {
+	var filter repository.AuditFilter
+	if since, ok := p.Args["since"].(time.Time); ok {
+		filter.Since = since
+	}
+	filter.Actor, _ = p.Args["actor"].(string)
+	limit, ok := p.Args["limit"].(int)
+	if !ok {
+		limit = defaultAuditLogLimit
+	}
+	if limit <= 0 || limit > maxAuditLogLimit {
+		return nil, apperrors.Validation("limit must be between 1 and %d", maxAuditLogLimit)
+	}
+
+	entries, err := h.store.Audit().List(filter, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// RevertChange undoes change with given id - created entity is deleted, updated one gets its previous values,
+// deleted one is restored (deleted sense is added again, with new id). Entity cannot be changed after the change,
+// later changes have to be reverted first. Revert is recorded in audit log as new change, which is returned.
+func (h *Handlers) RevertChange(p graphql.ResolveParams) (interface{}, error) {
+	id, ok := p.Args["id"].(int)
+	if !ok {
+		return nil, apperrors.Validation("invalid or missing ID")
+	}
+
+	entry, err := h.store.Audit().Get(uint(id))
+	if err != nil {
+		return nil, lookupError("change", err)
+	}
+
+	var reverted models.AuditEntry
+	err = h.store.Transaction(func(tx repository.Store) error {
+		var c change
+		var err error
+		switch entry.Entity {
+		case models.EntityWord:
+			c, err = revertWord(tx, entry)
+		case models.EntityParadigm:
+			c, err = revertParadigm(tx, entry)
+		case models.EntitySense:
+			c, err = revertSense(tx, entry)
+		case models.EntityTranslation:
+			c, err = revertTranslation(tx, entry)
+		case models.EntityExample:
+			c, err = revertExample(tx, entry)
+		default:
+			return apperrors.Validation("changes of %s cannot be reverted", entry.Entity)
+		}
+		if err != nil {
+			return err
+		}
+		c.revertOf = &entry.ID
+		reverted, err = record(tx, p, c)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reverted, nil
+}
+
+func revertWord(tx repository.Store, entry models.AuditEntry) (change, error) {
+	var current interface{}
+	word, err := tx.Words().GetForUpdate(entry.EntityID)
+	if err == nil {
+		current = newWordValues(word)
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return change{}, fmt.Errorf("failed to query word: %w", err)
+	}
+	if err := unchangedSince(entry, current); err != nil {
+		return change{}, err
+	}
+
+	switch entry.Operation {
+	case models.OperationCreate, models.OperationRestore:
+		if err := tx.Words().Delete(word.ID); err != nil {
+			return change{}, fmt.Errorf("failed to delete word: %w", err)
+		}
+		return wordChange(models.OperationDelete, word, current, nil), nil
+	case models.OperationUpdate:
+		var before wordValues
+		if err := decodeValues(entry.Before, &before); err != nil {
+			return change{}, err
+		}
+		before.apply(&word)
+		if err := tx.Words().Save(&word); err != nil {
+			return change{}, writeError("update word", err)
+		}
+		return wordChange(models.OperationUpdate, word, current, newWordValues(word)), nil
+	}
+
+	word, err = tx.Words().Restore(entry.EntityID)
+	if err != nil {
+		return change{}, restoreError("word", err)
+	}
+	return wordChange(models.OperationRestore, word, nil, newWordValues(word)), nil
+}
+
+func revertParadigm(tx repository.Store, entry models.AuditEntry) (change, error) {
+	if err := lockWords(tx, entry.EntityID); err != nil {
+		return change{}, err
+	}
+	forms, err := tx.Forms().ForWords([]uint{entry.EntityID})
+	if err != nil {
+		return change{}, fmt.Errorf("failed to query forms: %w", err)
+	}
+	current := newParadigmValues(forms)
+	if err := unchangedSince(entry, current); err != nil {
+		return change{}, err
+	}
+
+	var before []formValues
+	if err := decodeValues(entry.Before, &before); err != nil {
+		return change{}, err
+	}
+	forms = make([]models.WordForm, 0, len(before))
+	for _, v := range before {
+		forms = append(forms, v.form(entry.EntityID))
+	}
+	if err := replaceForms(tx, entry.EntityID, forms); err != nil {
+		return change{}, err
+	}
+	return paradigmChange(entry.EntityID, current, newParadigmValues(forms)), nil
+}
+
+func revertSense(tx repository.Store, entry models.AuditEntry) (change, error) {
+	var current interface{}
+	sense, err := tx.Senses().GetForUpdate(entry.EntityID)
+	if err == nil {
+		current = newSenseValues(sense)
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return change{}, fmt.Errorf("failed to query sense: %w", err)
+	}
+	if err := unchangedSince(entry, current); err != nil {
+		return change{}, err
+	}
+
+	switch entry.Operation {
+	case models.OperationCreate:
+		if err := tx.Senses().Delete(sense.ID); err != nil {
+			return change{}, fmt.Errorf("failed to delete sense: %w", err)
+		}
+		return senseChange(models.OperationDelete, sense, current, nil), nil
+	case models.OperationUpdate:
+		var before senseValues
+		if err := decodeValues(entry.Before, &before); err != nil {
+			return change{}, err
+		}
+		sense.Ordinal, sense.Definition, sense.Domain = before.Ordinal, before.Definition, before.Domain
+		if err := tx.Senses().Save(&sense); err != nil {
+			return change{}, writeError("update sense", err)
+		}
+		return senseChange(models.OperationUpdate, sense, current, newSenseValues(sense)), nil
+	}
+
+	// Deleted sense is added again - translations and examples attached to it stay attached only to the word
+	var before senseValues
+	if err := decodeValues(entry.Before, &before); err != nil {
+		return change{}, err
+	}
+	if err := lockWords(tx, before.WordID); err != nil {
+		return change{}, err
+	}
+	if _, err := findSense(tx, before.WordID, before.Ordinal); err == nil {
+		return change{}, apperrors.Conflict("sense %d of the word already exists", before.Ordinal)
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return change{}, fmt.Errorf("failed to query sense: %w", err)
+	}
+	sense = models.Sense{WordID: before.WordID, Ordinal: before.Ordinal, Definition: before.Definition, Domain: before.Domain}
+	if err := tx.Senses().Create(&sense); err != nil {
+		return change{}, writeError("add sense", err)
+	}
+	return senseChange(models.OperationCreate, sense, nil, newSenseValues(sense)), nil
+}
+
+func revertTranslation(tx repository.Store, entry models.AuditEntry) (change, error) {
+	var current interface{}
+	translation, err := tx.Translations().Get(entry.EntityID)
+	if err == nil {
+		if err := lockWords(tx, translation.SourceWordID, translation.TargetWordID); err != nil {
+			return change{}, err
+		}
+		current = newTranslationValues(translation)
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return change{}, fmt.Errorf("failed to query translation: %w", err)
+	}
+	if err := unchangedSince(entry, current); err != nil {
+		return change{}, err
+	}
+
+	switch entry.Operation {
+	case models.OperationCreate, models.OperationRestore:
+		if err := tx.Translations().DeleteBetween(translation.SourceWordID, translation.TargetWordID); err != nil {
+			return change{}, fmt.Errorf("failed to delete translation: %w", err)
+		}
+		return translationChange(models.OperationDelete, translation, current, nil), nil
+	case models.OperationUpdate:
+		var before translationValues
+		if err := decodeValues(entry.Before, &before); err != nil {
+			return change{}, err
+		}
+		if err := lockWords(tx, before.SourceWordID, before.TargetWordID); err != nil {
+			return change{}, err
+		}
+		translation.SourceWordID, translation.TargetWordID = before.SourceWordID, before.TargetWordID
+		translation.SourceSenseID, translation.TargetSenseID = before.SourceSenseID, before.TargetSenseID
+		if err := saveTranslation(tx, &translation); err != nil {
+			return change{}, err
+		}
+		return translationChange(models.OperationUpdate, translation, current, newTranslationValues(translation)), nil
+	}
+
+	translation, err = tx.Translations().Restore(entry.EntityID)
+	if err != nil {
+		return change{}, restoreError("translation", err)
+	}
+	return translationChange(models.OperationRestore, translation, nil, newTranslationValues(translation)), nil
+}
+
+func revertExample(tx repository.Store, entry models.AuditEntry) (change, error) {
+	var current interface{}
+	example, err := tx.Examples().GetForUpdate(entry.EntityID)
+	if err == nil {
+		current = newExampleValues(example)
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return change{}, fmt.Errorf("failed to query example: %w", err)
+	}
+	if err := unchangedSince(entry, current); err != nil {
+		return change{}, err
+	}
+
+	switch entry.Operation {
+	case models.OperationCreate, models.OperationRestore:
+		if err := tx.Examples().Delete(example.ID); err != nil {
+			return change{}, fmt.Errorf("failed to delete example: %w", err)
+		}
+		return exampleChange(models.OperationDelete, example, current, nil), nil
+	case models.OperationUpdate:
+		var before exampleValues
+		if err := decodeValues(entry.Before, &before); err != nil {
+			return change{}, err
+		}
+		example.Example, example.SenseID = before.Example, before.SenseID
+		if err := tx.Examples().Save(&example); err != nil {
+			return change{}, writeError("update example", err)
+		}
+		return exampleChange(models.OperationUpdate, example, current, newExampleValues(example)), nil
+	}
+
+	example, err = tx.Examples().Restore(entry.EntityID)
+	if err != nil {
+		return change{}, restoreError("example", err)
+	}
+	return exampleChange(models.OperationRestore, example, nil, newExampleValues(example)), nil
+}
+
+// unchangedSince checks that entity has values it got by the change (current is nil when entity does not exist)
+func unchangedSince(entry models.AuditEntry, current interface{}) error {
+	values, err := encodeValues(current)
+	if err != nil {
+		return err
+	}
+	if values != entry.After {
+		return apperrors.Conflict("%s was changed after change %d, revert later changes first", entry.Entity, entry.ID)
+	}
+	return nil
+}
+
+// decodeValues reads recorded values of entity
+func decodeValues(values string, v interface{}) error {
+	if err := json.Unmarshal([]byte(values), v); err != nil {
+		return fmt.Errorf("failed to decode values: %w", err)
+	}
+	return nil
+}
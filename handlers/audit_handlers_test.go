@@ -0,0 +1,126 @@
+package handlers_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tdawidzi/dictionary_app/models"
+	"github.com/tdawidzi/dictionary_app/repository"
+)
+
+// lastChange gives the last change of word from its history
+func lastChange(t *testing.T, c client, wordID uint) map[string]interface{} {
+	t.Helper()
+
+	history, _ := c.data(t, `{ history(wordId: `+fmt.Sprint(wordID)+`) { id actor } }`)["history"].([]interface{})
+	if !assert.NotEmpty(t, history) {
+		t.FailNow()
+	}
+	return history[len(history)-1].(map[string]interface{})
+}
+
+// revertLastChange reverts the last change of word
+func revertLastChange(t *testing.T, c client, wordID uint) {
+	t.Helper()
+
+	c.data(t, `mutation { revertChange(id: `+fmt.Sprint(lastChange(t, c, wordID)["id"])+`) { id } }`)
+}
+
+func TestHistoryAndRevertWithEachStore(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store repository.Store) {
+		c := newClient(t, store)
+		kot := c.data(t, `mutation { addWord(word: "kot", language: "pl") { id } }`)["addWord"].(map[string]interface{})
+
+		// Every change is recorded with its actor
+		result := c.doAs("ola", models.RoleEditor, `mutation { updateWord(oldWord: "kot", language: "pl", newWord: "kott", expectedVersion: 1) { word } }`)
+		assert.Empty(t, result.Errors)
+		history, _ := c.data(t, `{ history(wordId: `+fmt.Sprint(kot["id"])+`) { id entity operation actor before after } }`)["history"].([]interface{})
+		if !assert.Len(t, history, 2) {
+			return
+		}
+		create := history[0].(map[string]interface{})
+		update := history[1].(map[string]interface{})
+		assert.Equal(t, []interface{}{"WORD", "CREATE", "apikey:ala"}, []interface{}{create["entity"], create["operation"], create["actor"]})
+		assert.Nil(t, create["before"])
+		assert.Equal(t, []interface{}{"WORD", "UPDATE", "apikey:ola"}, []interface{}{update["entity"], update["operation"], update["actor"]})
+		assert.Contains(t, update["before"], `"word":"kot"`)
+		assert.Contains(t, update["after"], `"word":"kott"`)
+
+		// Reverting change is a change of its own, which cannot be reverted twice
+		data := c.data(t, `mutation { revertChange(id: `+fmt.Sprint(update["id"])+`) { operation actor revertOf } }`)
+		assert.Equal(t, map[string]interface{}{"operation": "UPDATE", "actor": "apikey:ala", "revertOf": update["id"]}, data["revertChange"])
+		c.data(t, `{ word(word: "kot", language: "pl") { word } }`)
+		assertErrorCode(t, c.do(`mutation { revertChange(id: `+fmt.Sprint(update["id"])+`) { id } }`), "CONFLICT")
+
+		data = c.data(t, `{ auditLog(actor: "apikey:ola") { entity operation } }`)
+		assert.Equal(t, []interface{}{map[string]interface{}{"entity": "WORD", "operation": "UPDATE"}}, data["auditLog"])
+	})
+}
+
+func TestRevertParadigm(t *testing.T) {
+	store, _ := setupTestStore(t)
+	c := newClient(t, store)
+	kot := createWord(t, store, models.Word{Word: "kot", Language: "pl"})
+
+	// Paradigm gets previous forms
+	c.data(t, `mutation { setParadigm(word: "kot", language: "pl", forms: [{form: "kota", case: GENITIVE, number: SINGULAR}]) { form } }`)
+	c.data(t, `mutation { setParadigm(word: "kot", language: "pl", forms: []) { form } }`)
+	revertLastChange(t, c, kot.ID)
+	data := c.data(t, `{ word(word: "kot", language: "pl") { forms { form } } }`)
+	assert.Equal(t, []interface{}{map[string]interface{}{"form": "kota"}}, data["word"].(map[string]interface{})["forms"])
+}
+
+func TestRevertDeleteSense(t *testing.T) {
+	store, _ := setupTestStore(t)
+	c := newClient(t, store)
+	kot := createWord(t, store, models.Word{Word: "kot", Language: "pl"})
+
+	// Deleted sense is added again
+	sense := c.data(t, `mutation { addSense(word: "kot", language: "pl", definition: "zwierzę") { id } }`)["addSense"].(map[string]interface{})
+	c.data(t, `mutation { deleteSense(id: `+fmt.Sprint(sense["id"])+`) }`)
+	revertLastChange(t, c, kot.ID)
+	data := c.data(t, `{ word(word: "kot", language: "pl") { senses { ordinal definition } } }`)
+	assert.Equal(t, []interface{}{map[string]interface{}{"ordinal": 1, "definition": "zwierzę"}}, data["word"].(map[string]interface{})["senses"])
+}
+
+func TestRevertTranslationChanges(t *testing.T) {
+	store, _ := setupTestStore(t)
+	c := newClient(t, store)
+	kot := createWord(t, store, models.Word{Word: "kot", Language: "pl"})
+	createWord(t, store, models.Word{Word: "cat", Language: "en"})
+	createWord(t, store, models.Word{Word: "dog", Language: "en"})
+
+	// Moved translation goes back to its words, deleted one is restored
+	c.data(t, `mutation { addTranslation(sourceWord: "kot", sourceLanguage: "pl", targetWord: "cat", targetLanguage: "en") { id } }`)
+	c.data(t, `mutation { updateTranslation(sourceLanguage: "pl", targetLanguage: "en", oldSourceWord: "kot", oldTargetWord: "cat", newSourceWord: "kot", newTargetWord: "dog", expectedVersion: 1) { id } }`)
+	revertLastChange(t, c, kot.ID)
+	c.data(t, `mutation { deleteTranslation(sourceWord: "kot", sourceLanguage: "pl", targetWord: "cat", targetLanguage: "en") }`)
+	revertLastChange(t, c, kot.ID)
+	data := c.data(t, `{ word(word: "kot", language: "pl") { translations { word } } }`)
+	assert.Equal(t, []interface{}{map[string]interface{}{"word": "cat"}}, data["word"].(map[string]interface{})["translations"])
+}
+
+func TestRevertAddExample(t *testing.T) {
+	store, _ := setupTestStore(t)
+	c := newClient(t, store)
+	kot := createWord(t, store, models.Word{Word: "kot", Language: "pl"})
+
+	// Created example is deleted
+	c.data(t, `mutation { addExample(word: "kot", language: "pl", example: "Ala ma kota.") { id } }`)
+	revertLastChange(t, c, kot.ID)
+	data := c.data(t, `{ examplesForWord(word: "kot", language: "pl") { example } }`)
+	assert.Equal(t, []interface{}{}, data["examplesForWord"])
+}
+
+func TestRevertLanguageChange(t *testing.T) {
+	store, _ := setupTestStore(t)
+	c := newClient(t, store)
+
+	// Changes of languages cannot be reverted
+	c.data(t, `mutation { addLanguage(code: "de", name: "German") { code } }`)
+	log := c.data(t, `{ auditLog(limit: 1000) { id entity } }`)["auditLog"].([]interface{})
+	last := log[len(log)-1].(map[string]interface{})
+	assert.Equal(t, "LANGUAGE", last["entity"])
+	assertErrorCode(t, c.do(`mutation { revertChange(id: `+fmt.Sprint(last["id"])+`) { id } }`), "VALIDATION")
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/tdawidzi/dictionary_app/apperrors"
+	"github.com/tdawidzi/dictionary_app/models"
+	"github.com/tdawidzi/dictionary_app/repository"
+	"github.com/tdawidzi/dictionary_app/textutil"
+
+	"github.com/graphql-go/graphql"
+)
+
+const (
+	defaultAutocompleteLimit = 10
+	maxAutocompleteLimit     = 50
+)
+
+// Completion is single autocomplete result - word with its primary translation (nil if word is not translated)
+type Completion struct {
+	Word        models.Word
+	Translation *models.Word
+}
+
+// Autocomplete returns words of given dictionaries (the default one if not given) starting with given prefix
+// (case and diacritic insensitive). Exact match goes first, then alphabetically.
+// Optional "targetLanguage" limits primary translation to given language.
+func (h *Handlers) Autocomplete(p graphql.ResolveParams) (interface{}, error) {
+	prefix, _ := p.Args["prefix"].(string)
+	key := textutil.SearchKey(prefix)
+	if key == "" {
+		return nil, apperrors.Validation("missing prefix")
+	}
+	language, _ := p.Args["language"].(string)
+	targetLanguage, _ := p.Args["targetLanguage"].(string)
+	limit, ok := p.Args["limit"].(int)
+	if !ok {
+		limit = defaultAutocompleteLimit
+	}
+	if limit <= 0 || limit > maxAutocompleteLimit {
+		return nil, apperrors.Validation("limit must be between 1 and %d", maxAutocompleteLimit)
+	}
+	dictionaries, err := dictionariesArg(h.store, p)
+	if err != nil {
+		return nil, err
+	}
+
+	words, err := h.store.Words().Complete(key, language, dictionaries, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch words: %w", err)
+	}
+
+	translations, err := primaryTranslations(h.store, words, targetLanguage)
+	if err != nil {
+		return nil, err
+	}
+
+	completions := make([]Completion, 0, len(words))
+	for _, w := range words {
+		completions = append(completions, Completion{Word: w, Translation: translations[w.ID]})
+	}
+	return completions, nil
+}
+
+// primaryTranslations finds the first added translation of every given word (optionally into given language).
+// All translations are fetched in single query.
+func primaryTranslations(store repository.Store, words []models.Word, language string) (map[uint]*models.Word, error) {
+	primary := make(map[uint]*models.Word, len(words))
+	if len(words) == 0 {
+		return primary, nil
+	}
+
+	ids := make([]uint, 0, len(words))
+	for _, w := range words {
+		ids = append(ids, w.ID)
+	}
+
+	translations, err := store.Translations().ForWords(ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch translations: %w", err)
+	}
+
+	// Translations are symmetric - each one can be primary for both its words
+	setPrimary := func(wordID uint, translated models.Word) {
+		if _, ok := primary[wordID]; ok {
+			return
+		}
+		if language != "" && translated.Language != language {
+			return
+		}
+		primary[wordID] = &translated
+	}
+	for _, t := range translations {
+		setPrimary(t.SourceWordID, t.TargetWord)
+		setPrimary(t.TargetWordID, t.SourceWord)
+	}
+	return primary, nil
+}
+
+// GetCompletionTranslation resolves primary translation of autocomplete result
+// (word resolvers expect models.Word value, not a pointer)
+func (h *Handlers) GetCompletionTranslation(p graphql.ResolveParams) (interface{}, error) {
+	completion, ok := p.Source.(Completion)
+	if !ok {
+		return nil, fmt.Errorf("invalid source for translation")
+	}
+	if completion.Translation == nil {
+		return nil, nil
+	}
+	return *completion.Translation, nil
+}
@@ -0,0 +1,69 @@
+package handlers_test
+
+import (
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/stretchr/testify/assert"
+	"github.com/tdawidzi/dictionary_app/handlers"
+	"github.com/tdawidzi/dictionary_app/models"
+)
+
+func autocomplete(t *testing.T, h *handlers.Handlers, args map[string]interface{}) []handlers.Completion {
+	t.Helper()
+
+	result, err := h.Autocomplete(graphql.ResolveParams{Args: args})
+	assert.NoError(t, err)
+	completions, ok := result.([]handlers.Completion)
+	assert.True(t, ok)
+	return completions
+}
+
+func TestAutocomplete(t *testing.T) {
+	store, h := setupTestStore(t)
+
+	createWord(t, store, models.Word{Word: "kotek", Language: "pl"})
+	kot := createWord(t, store, models.Word{Word: "kot", Language: "pl"})
+	cat := createWord(t, store, models.Word{Word: "cat", Language: "en"})
+	createWord(t, store, models.Word{Word: "kołdra", Language: "pl"})
+	createWord(t, store, models.Word{Word: "pies", Language: "pl"})
+	createTranslation(t, store, models.Translation{SourceWordID: cat.ID, TargetWordID: kot.ID})
+
+	completions := autocomplete(t, h, map[string]interface{}{"prefix": "Ko", "language": "pl"})
+	assert.Len(t, completions, 3)
+	// Alphabetically by normalized spelling
+	assert.Equal(t, "kołdra", completions[0].Word.Word)
+	assert.Equal(t, "kot", completions[1].Word.Word)
+	assert.Equal(t, "kotek", completions[2].Word.Word)
+
+	// Primary translation (translations are symmetric)
+	if assert.NotNil(t, completions[1].Translation) {
+		assert.Equal(t, "cat", completions[1].Translation.Word)
+	}
+	assert.Nil(t, completions[2].Translation)
+
+	// Exact match first
+	completions = autocomplete(t, h, map[string]interface{}{"prefix": "kot"})
+	if assert.Len(t, completions, 2) {
+		assert.Equal(t, "kot", completions[0].Word.Word)
+	}
+
+	// Diacritics are ignored
+	completions = autocomplete(t, h, map[string]interface{}{"prefix": "kol", "limit": 1})
+	assert.Len(t, completions, 1)
+	assert.Equal(t, "kołdra", completions[0].Word.Word)
+
+	// Wildcards are matched literally
+	completions = autocomplete(t, h, map[string]interface{}{"prefix": "k%"})
+	assert.Len(t, completions, 0)
+}
+
+func TestAutocompleteInvalidArguments(t *testing.T) {
+	_, h := setupTestStore(t)
+
+	_, err := h.Autocomplete(graphql.ResolveParams{Args: map[string]interface{}{"prefix": " "}})
+	assert.Error(t, err)
+
+	_, err = h.Autocomplete(graphql.ResolveParams{Args: map[string]interface{}{"prefix": "k", "limit": 100}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/tdawidzi/dictionary_app/apperrors"
+	"github.com/tdawidzi/dictionary_app/models"
+	"github.com/tdawidzi/dictionary_app/repository"
+	"github.com/tdawidzi/dictionary_app/validation"
+
+	"github.com/graphql-go/graphql"
+)
+
+// GetDictionaries fetches all dictionaries, the default one first
+func (h *Handlers) GetDictionaries(p graphql.ResolveParams) (interface{}, error) {
+	dictionaries, err := h.store.Dictionaries().List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch dictionaries: %w", err)
+	}
+	return dictionaries, nil
+}
+
+// AddDictionary creates new, empty dictionary. Existing dictionary with the same name is returned unchanged.
+func (h *Handlers) AddDictionary(p graphql.ResolveParams) (interface{}, error) {
+	name, _ := p.Args["name"].(string)
+	description, _ := p.Args["description"].(string)
+
+	var v validation.Validator
+	name = v.Text("name", name, validation.MaxLabelLength)
+	description = v.OptionalText("description", description, validation.MaxDefinitionLength)
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+
+	if existing, err := h.store.Dictionaries().Find(name); err == nil {
+		return existing, nil
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return nil, fmt.Errorf("failed to query dictionary: %w", err)
+	}
+
+	dictionary := models.Dictionary{Name: name, Description: description}
+	err := h.store.Transaction(func(tx repository.Store) error {
+		if err := tx.Dictionaries().Create(&dictionary); err != nil {
+			return writeError("add dictionary", err)
+		}
+		_, err := record(tx, p, dictionaryChange(models.OperationCreate, dictionary, nil, newDictionaryValues(dictionary)))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dictionary, nil
+}
+
+// GetDictionaryForWord resolves dictionary of word. Dictionaries of all words in request are batch loaded (see Loaders).
+func (h *Handlers) GetDictionaryForWord(p graphql.ResolveParams) (interface{}, error) {
+	word, ok := sourceWord(p)
+	if !ok {
+		return nil, fmt.Errorf("invalid source for dictionary")
+	}
+
+	loaders, fromRequest := h.loadersFrom(p)
+	load := loaders.dictionaries.Load(word.DictionaryID)
+	return batched(fromRequest, func() (interface{}, error) {
+		dictionary, err := load()
+		if err != nil {
+			return nil, err
+		}
+		return dictionary, nil
+	})
+}
+
+// dictionaryArg resolves "dictionary" argument of mutation - name of dictionary the mutation changes (the default one if not given)
+func dictionaryArg(store repository.Store, p graphql.ResolveParams) (uint, error) {
+	name, ok := p.Args["dictionary"].(string)
+	if !ok {
+		return models.DefaultDictionaryID, nil
+	}
+	dictionary, err := findDictionary(store, name)
+	return dictionary.ID, err
+}
+
+// dictionariesArg resolves "dictionaries" argument of query - names of dictionaries to search (the default one if not given)
+func dictionariesArg(store repository.Store, p graphql.ResolveParams) ([]uint, error) {
+	names, ok := p.Args["dictionaries"].([]interface{})
+	if !ok {
+		return []uint{models.DefaultDictionaryID}, nil
+	}
+	if len(names) == 0 {
+		return nil, apperrors.Validation("at least one dictionary is required")
+	}
+
+	ids := make([]uint, 0, len(names))
+	for _, n := range names {
+		name, _ := n.(string)
+		dictionary, err := findDictionary(store, name)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, dictionary.ID)
+	}
+	return ids, nil
+}
+
+// findDictionary looks up dictionary by its name
+func findDictionary(store repository.Store, name string) (models.Dictionary, error) {
+	dictionary, err := store.Dictionaries().Find(validation.Normalize(name))
+	if errors.Is(err, repository.ErrNotFound) {
+		return dictionary, apperrors.NotFound("dictionary not found: %s", name)
+	}
+	if err != nil {
+		return dictionary, fmt.Errorf("failed to query dictionary: %w", err)
+	}
+	return dictionary, nil
+}
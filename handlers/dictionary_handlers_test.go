@@ -0,0 +1,39 @@
+package handlers_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tdawidzi/dictionary_app/models"
+	"github.com/tdawidzi/dictionary_app/repository"
+)
+
+func TestDictionariesWithEachStore(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store repository.Store) {
+		c := newClient(t, store)
+		kot := createWord(t, store, models.Word{Word: "kot", Language: "pl"})
+		cat := createWord(t, store, models.Word{Word: "cat", Language: "en"})
+		createTranslation(t, store, models.Translation{SourceWordID: kot.ID, TargetWordID: cat.ID})
+
+		data := c.data(t, `mutation { addDictionary(name: "medical", description: "Medical terms") { name description } }`)
+		assert.Equal(t, map[string]interface{}{"name": "medical", "description": "Medical terms"}, data["addDictionary"])
+
+		// Dictionaries are independent, queries and mutations use the default one unless told otherwise
+		data = c.data(t, `mutation { addWord(word: "kot", language: "pl", dictionary: "medical") { dictionary { name } } }`)
+		assert.Equal(t, map[string]interface{}{"dictionary": map[string]interface{}{"name": "medical"}}, data["addWord"])
+		assertErrorCode(t, c.do(`mutation { addTranslation(sourceWord: "kot", sourceLanguage: "pl", targetWord: "cat", targetLanguage: "en", dictionary: "medical") { id } }`), "NOT_FOUND")
+		assertErrorCode(t, c.do(`mutation { addWord(word: "kot", language: "pl", dictionary: "legal") { id } }`), "NOT_FOUND")
+
+		data = c.data(t, `{ dictionaries { name } words(language: "pl") { totalCount } }`)
+		assert.Equal(t, []interface{}{map[string]interface{}{"name": "default"}, map[string]interface{}{"name": "medical"}}, data["dictionaries"])
+		assert.Equal(t, map[string]interface{}{"totalCount": 1}, data["words"])
+		data = c.data(t, `{ word(word: "kot", language: "pl", dictionaries: ["medical"]) { translations { word } } }`)
+		assert.Equal(t, map[string]interface{}{"translations": []interface{}{}}, data["word"])
+
+		// Word is ambiguous in several dictionaries
+		result := c.do(`{ word(word: "kot", language: "pl", dictionaries: ["default", "medical"]) { id } }`)
+		if assert.Len(t, result.Errors, 1) {
+			assert.Contains(t, result.Errors[0].Message, "dictionary: medical")
+		}
+	})
+}
@@ -1,32 +1,42 @@
 package handlers
 
 import (
-	"errors"
 	"fmt"
+	"strings"
 
+	"github.com/tdawidzi/dictionary_app/apperrors"
 	"github.com/tdawidzi/dictionary_app/models"
-	"github.com/tdawidzi/dictionary_app/utils"
+	"github.com/tdawidzi/dictionary_app/repository"
+	"github.com/tdawidzi/dictionary_app/validation"
 
 	"github.com/graphql-go/graphql"
-	"gorm.io/gorm"
 )
 
 // GetExamplesForWord fetches example sentences for a given word.
-func GetExamplesForWord(p graphql.ResolveParams) (interface{}, error) {
+// Word is looked up like in GetWordByText - case and diacritic insensitive, unless exact match is requested.
+// Language and homograph number are optional, but needed when the text alone is ambiguous (also with several dictionaries).
+func (h *Handlers) GetExamplesForWord(p graphql.ResolveParams) (interface{}, error) {
 	wordText, ok := p.Args["word"].(string)
 	if !ok {
-		return nil, fmt.Errorf("invalid word input")
+		return nil, apperrors.Validation("invalid word input")
+	}
+	language, _ := p.Args["language"].(string)
+	homograph, _ := p.Args["homograph"].(int)
+	exact, _ := p.Args["exact"].(bool)
+	dictionaries, err := dictionariesArg(h.store, p)
+	if err != nil {
+		return nil, err
 	}
 
 	// Fetch the word by its text
-	var word models.Word
-	if err := utils.DB.Where("word = ?", wordText).First(&word).Error; err != nil {
-		return nil, fmt.Errorf("word not found: %w", err)
+	word, err := lookupWord(h.store, dictionaries, wordText, language, homograph, exact)
+	if err != nil {
+		return nil, lookupError("word", err)
 	}
 
 	// Fetch all examples in one query
-	var examples []models.Example
-	if err := utils.DB.Where("word_id = ?", word.ID).Find(&examples).Error; err != nil {
+	examples, err := h.store.Examples().ForWord(word.ID)
+	if err != nil {
 		return nil, fmt.Errorf("failed to fetch examples: %w", err)
 	}
 
@@ -34,82 +44,238 @@ func GetExamplesForWord(p graphql.ResolveParams) (interface{}, error) {
 }
 
 // AddExample adds an example to db associated with given word
-func AddExample(p graphql.ResolveParams) (interface{}, error) {
+func (h *Handlers) AddExample(p graphql.ResolveParams) (interface{}, error) {
 	wordText, _ := p.Args["word"].(string)
 	language, _ := p.Args["language"].(string)
+	homograph, _ := p.Args["homograph"].(int)
 	exampleText, _ := p.Args["example"].(string)
+	dictionaryID, err := dictionaryArg(h.store, p)
+	if err != nil {
+		return nil, err
+	}
+
+	word, err := findWord(h.store, dictionaryID, wordText, language, homograph)
+	if err != nil {
+		return nil, lookupError("word", err)
+	}
 
-	var word models.Word
-	if err := utils.DB.Where("word = ? AND language = ?", wordText, language).First(&word).Error; err != nil {
-		return nil, fmt.Errorf("word not found: %w", err)
+	// Optional sense of a word
+	senseID, err := senseIDArg(h.store, p, "sense", word.ID)
+	if err != nil {
+		return nil, err
 	}
 
-	// Check if record exists
-	var existing models.Example
-	if err := utils.DB.Where("example = ? AND word_id = ?", exampleText, word.ID).First(&existing).Error; err == nil {
-		return existing, nil
-	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, fmt.Errorf("failed to query example: %w", err)
+	exampleText, err = validExample(h.store, exampleText, word)
+	if err != nil {
+		return nil, err
 	}
 
-	// Create new record
 	example := models.Example{
 		WordID:  word.ID,
 		Example: exampleText,
+		SenseID: senseID,
 	}
-	if err := utils.DB.Create(&example).Error; err != nil {
-		return nil, fmt.Errorf("failed to create example: %w", err)
+	err = h.store.Transaction(func(tx repository.Store) error {
+		// Example is inserted only if word does not have it yet - concurrent requests insert it once
+		created, err := tx.Examples().Create(&example)
+		if err != nil {
+			return writeError("create example", err)
+		}
+		if created {
+			_, err := record(tx, p, exampleChange(models.OperationCreate, example, nil, newExampleValues(example)))
+			return err
+		}
+
+		// Record exists - attach it to given sense
+		existing, err := tx.Examples().Find(word.ID, exampleText)
+		if err != nil {
+			return fmt.Errorf("failed to query example: %w", err)
+		}
+		if example, err = tx.Examples().GetForUpdate(existing.ID); err != nil {
+			return fmt.Errorf("failed to query example: %w", err)
+		}
+		if senseID == nil {
+			return nil
+		}
+		before := newExampleValues(example)
+		example.SenseID = senseID
+		if err := tx.Examples().Save(&example); err != nil {
+			return writeError("update example", err)
+		}
+		_, err = record(tx, p, exampleChange(models.OperationUpdate, example, before, newExampleValues(example)))
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 	return example, nil
 }
 
 // UpdateExample - modifies example text with given example id
-func UpdateExample(p graphql.ResolveParams) (interface{}, error) {
+func (h *Handlers) UpdateExample(p graphql.ResolveParams) (interface{}, error) {
 	// Check for errors in id and convert it to integer
 	id, ok := p.Args["id"].(int)
 	if !ok {
-		return nil, fmt.Errorf("invalid or missing ID")
+		return nil, apperrors.Validation("invalid or missing ID")
 	}
 
 	// Check if argument example is given
 	newExample, hasExample := p.Args["example"].(string)
+	expectedVersion, err := expectedVersionArg(p)
+	if err != nil {
+		return nil, err
+	}
 
 	// Find example with given id
-	var example models.Example
-	if err := utils.DB.First(&example, id).Error; err != nil {
-		return nil, fmt.Errorf("example not found: %w", err)
+	example, err := h.store.Examples().Get(uint(id))
+	if err != nil {
+		return nil, lookupError("example", err)
 	}
 
-	// Update example if new example is not nil
 	if hasExample {
-		example.Example = newExample
+		newExample, err = validExample(h.store, newExample, example.Word)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Attach example to another sense of its word
+	_, hasSense := p.Args["sense"]
+	senseID, err := senseIDArg(h.store, p, "sense", example.WordID)
+	if err != nil {
+		return nil, err
 	}
 
-	// Save changes
-	if err := utils.DB.Save(&example).Error; err != nil {
-		return nil, fmt.Errorf("failed to update example: %w", err)
+	err = h.store.Transaction(func(tx repository.Store) error {
+		// Example is read again and locked, so concurrent updates of other fields are not lost
+		var err error
+		example, err = tx.Examples().GetForUpdate(uint(id))
+		if err != nil {
+			return lookupError("example", err)
+		}
+		if err := checkVersion("example", example.Version, expectedVersion); err != nil {
+			return err
+		}
+
+		// Update only given fields
+		before := newExampleValues(example)
+		if hasExample {
+			example.Example = newExample
+		}
+		if hasSense {
+			example.SenseID = senseID
+		}
+		if err := tx.Examples().Save(&example); err != nil {
+			return writeError("update example", err)
+		}
+		_, err = record(tx, p, exampleChange(models.OperationUpdate, example, before, newExampleValues(example)))
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return example, nil
 }
 
-// Deleting example with given id from db
-func DeleteExample(p graphql.ResolveParams) (interface{}, error) {
+// Deleting example with given id - it is moved to trash (see RestoreExample)
+func (h *Handlers) DeleteExample(p graphql.ResolveParams) (interface{}, error) {
 	id, ok := p.Args["id"].(int)
 	if !ok {
-		return nil, fmt.Errorf("invalid or missing ID")
+		return nil, apperrors.Validation("invalid or missing ID")
 	}
 
-	// Find example with given id
-	var example models.Example
-	if err := utils.DB.First(&example, id).Error; err != nil {
-		return false, fmt.Errorf("example not found: %w", err)
-	}
-
-	if err := utils.DB.Delete(&example).Error; err != nil {
-		return false, fmt.Errorf("failed to delete example: %w", err)
+	err := h.store.Transaction(func(tx repository.Store) error {
+		// Find example with given id
+		example, err := tx.Examples().GetForUpdate(uint(id))
+		if err != nil {
+			return lookupError("example", err)
+		}
+		if err := tx.Examples().Delete(example.ID); err != nil {
+			return fmt.Errorf("failed to delete example: %w", err)
+		}
+		_, err = record(tx, p, exampleChange(models.OperationDelete, example, newExampleValues(example), nil))
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Return true if succeeded
 	return true, nil
 }
+
+const (
+	defaultExampleSearchLimit = 20
+	maxExampleSearchLimit     = 100
+)
+
+// ExampleMatch is single full-text search result
+type ExampleMatch struct {
+	Example     models.Example
+	Word        models.Word
+	Highlighted string // example with matched terms highlighted
+	Rank        float64
+}
+
+// SearchExamples finds example sentences matching full-text query (web search syntax - words, "quoted phrases", -excluded, or).
+// Examples are searched with text search configuration of their language (e.g. english stemming for english examples),
+// in given dictionaries (the default one if not given). Results are ordered by relevance.
+func (h *Handlers) SearchExamples(p graphql.ResolveParams) (interface{}, error) {
+	query, _ := p.Args["query"].(string)
+	if strings.TrimSpace(query) == "" {
+		return nil, apperrors.Validation("missing query")
+	}
+	language, _ := p.Args["language"].(string)
+	limit, ok := p.Args["limit"].(int)
+	if !ok {
+		limit = defaultExampleSearchLimit
+	}
+	if limit <= 0 || limit > maxExampleSearchLimit {
+		return nil, apperrors.Validation("limit must be between 1 and %d", maxExampleSearchLimit)
+	}
+
+	// Unregistered language is reported as invalid input
+	if language != "" {
+		if _, err := findLanguage(h.store, language); err != nil {
+			return nil, err
+		}
+	}
+	dictionaries, err := dictionariesArg(h.store, p)
+	if err != nil {
+		return nil, err
+	}
+
+	hits, err := h.store.Examples().Search(query, language, dictionaries, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search examples: %w", err)
+	}
+
+	matches := make([]ExampleMatch, 0, len(hits))
+	for _, hit := range hits {
+		matches = append(matches, ExampleMatch{
+			Example:     hit.Example,
+			Word:        hit.Example.Word,
+			Highlighted: hit.Highlighted,
+			Rank:        hit.Rank,
+		})
+	}
+	return matches, nil
+}
+
+// validExample normalizes example text and checks if it uses the word (or one of its inflected forms)
+func validExample(store repository.Store, text string, word models.Word) (string, error) {
+	wordForms, err := store.Forms().ForWords([]uint{word.ID})
+	if err != nil {
+		return text, fmt.Errorf("failed to query forms: %w", err)
+	}
+	forms := make([]string, 0, len(wordForms))
+	for _, f := range wordForms {
+		forms = append(forms, f.Form)
+	}
+
+	var v validation.Validator
+	text = v.Text("example", text, validation.MaxExampleLength)
+	v.ContainsWord("example", text, word.Word, forms...)
+	return text, v.Err()
+}
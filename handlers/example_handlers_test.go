@@ -7,25 +7,16 @@ import (
 
 	"github.com/graphql-go/graphql"
 	"github.com/stretchr/testify/assert"
+	"github.com/tdawidzi/dictionary_app/apperrors"
 	"github.com/tdawidzi/dictionary_app/handlers"
 	"github.com/tdawidzi/dictionary_app/models"
-	"github.com/tdawidzi/dictionary_app/testresources"
-	"github.com/tdawidzi/dictionary_app/utils"
+	"github.com/tdawidzi/dictionary_app/repository"
 )
 
-func setupExampleTestDB(t *testing.T) {
-	utils.DB = testresources.NewSingleTestConnection(t)
-	err := utils.DB.AutoMigrate(&models.Word{}, &models.Example{})
-	if err != nil {
-		t.Fatalf("failed to migrate: %v", err)
-	}
-}
-
 func TestAddAndGetExample(t *testing.T) {
-	setupExampleTestDB(t)
+	store, h := setupTestStore(t)
 
-	word := models.Word{Word: "kot", Language: "pl"}
-	utils.DB.Create(&word)
+	createWord(t, store, models.Word{Word: "kot", Language: "pl"})
 
 	params := graphql.ResolveParams{
 		Args: map[string]interface{}{
@@ -35,7 +26,7 @@ func TestAddAndGetExample(t *testing.T) {
 		},
 	}
 
-	result, err := handlers.AddExample(params)
+	result, err := h.AddExample(params)
 	assert.NoError(t, err)
 
 	example, ok := result.(models.Example)
@@ -48,7 +39,7 @@ func TestAddAndGetExample(t *testing.T) {
 			"word": "kot",
 		},
 	}
-	result, err = handlers.GetExamplesForWord(getParams)
+	result, err = h.GetExamplesForWord(getParams)
 	assert.NoError(t, err)
 
 	examples, ok := result.([]models.Example)
@@ -57,23 +48,77 @@ func TestAddAndGetExample(t *testing.T) {
 	assert.Equal(t, "Kot siedzi na dachu.", examples[0].Example)
 }
 
+func TestGetExamplesForHomograph(t *testing.T) {
+	store, h := setupTestStore(t)
+
+	pl := createWord(t, store, models.Word{Word: "dom", Language: "pl"})
+	createWord(t, store, models.Word{Word: "dom", Language: "en"})
+	createExample(t, store, models.Example{WordID: pl.ID, Example: "Mój dom jest duży."})
+
+	// Text alone is ambiguous
+	_, err := h.GetExamplesForWord(graphql.ResolveParams{
+		Args: map[string]interface{}{"word": "dom"},
+	})
+	assert.Equal(t, apperrors.CodeValidation, apperrors.CodeOf(err))
+
+	result, err := h.GetExamplesForWord(graphql.ResolveParams{
+		Args: map[string]interface{}{"word": "dom", "language": "pl"},
+	})
+	assert.NoError(t, err)
+	examples, ok := result.([]models.Example)
+	assert.True(t, ok)
+	assert.Len(t, examples, 1)
+}
+
+func TestAddExampleMustContainWord(t *testing.T) {
+	store, h := setupTestStore(t)
+
+	word := createWord(t, store, models.Word{Word: "pies", Language: "pl"})
+
+	addExample := func(text string) error {
+		_, err := h.AddExample(graphql.ResolveParams{
+			Args: map[string]interface{}{"word": "pies", "language": "pl", "example": text},
+		})
+		return err
+	}
+
+	// Inflected form is recognized only if it is in paradigm
+	err := addExample("Widzę psa.")
+	assert.Equal(t, apperrors.CodeValidation, apperrors.CodeOf(err))
+	assert.NoError(t, store.Forms().Create([]models.WordForm{
+		{WordID: word.ID, Form: "psa", Case: models.CaseGenitive, Number: models.NumberSingular},
+	}))
+	assert.NoError(t, addExample("Widzę psa."))
+
+	assert.NoError(t, addExample("  Psy szczekają, pies też.  "))
+	err = addExample("Kot śpi.")
+	assert.Equal(t, apperrors.CodeValidation, apperrors.CodeOf(err))
+
+	examples, err := store.Examples().ForWord(word.ID)
+	assert.NoError(t, err)
+	var texts []string
+	for _, example := range examples {
+		texts = append(texts, example.Example)
+	}
+	assert.Equal(t, []string{"Widzę psa.", "Psy szczekają, pies też."}, texts)
+}
+
 func TestUpdateExample(t *testing.T) {
-	setupExampleTestDB(t)
+	store, h := setupTestStore(t)
 
-	word := models.Word{Word: "pies", Language: "pl"}
-	utils.DB.Create(&word)
+	word := createWord(t, store, models.Word{Word: "pies", Language: "pl"})
 
-	ex := models.Example{WordID: word.ID, Example: "Pies szczeka."}
-	utils.DB.Create(&ex)
+	ex := createExample(t, store, models.Example{WordID: word.ID, Example: "Pies szczeka."})
 
 	params := graphql.ResolveParams{
 		Args: map[string]interface{}{
-			"id":      int(ex.ID),
-			"example": "Pies głośno szczeka.",
+			"id":              int(ex.ID),
+			"example":         "Pies głośno szczeka.",
+			"expectedVersion": 1,
 		},
 	}
 
-	result, err := handlers.UpdateExample(params)
+	result, err := h.UpdateExample(params)
 	assert.NoError(t, err)
 
 	updated, ok := result.(models.Example)
@@ -82,13 +127,11 @@ func TestUpdateExample(t *testing.T) {
 }
 
 func TestDeleteExample(t *testing.T) {
-	setupExampleTestDB(t)
+	store, h := setupTestStore(t)
 
-	word := models.Word{Word: "mysz", Language: "pl"}
-	utils.DB.Create(&word)
+	word := createWord(t, store, models.Word{Word: "mysz", Language: "pl"})
 
-	ex := models.Example{WordID: word.ID, Example: "Mysz uciekła do nory."}
-	utils.DB.Create(&ex)
+	ex := createExample(t, store, models.Example{WordID: word.ID, Example: "Mysz uciekła do nory."})
 
 	params := graphql.ResolveParams{
 		Args: map[string]interface{}{
@@ -96,7 +139,7 @@ func TestDeleteExample(t *testing.T) {
 		},
 	}
 
-	result, err := handlers.DeleteExample(params)
+	result, err := h.DeleteExample(params)
 	assert.NoError(t, err)
 
 	deleted, ok := result.(bool)
@@ -104,23 +147,19 @@ func TestDeleteExample(t *testing.T) {
 	assert.True(t, deleted)
 
 	// Confirm deletion
-	var count int64
-	utils.DB.Model(&models.Example{}).Where("id = ?", ex.ID).Count(&count)
-	assert.Equal(t, int64(0), count)
+	_, err = store.Examples().Get(ex.ID)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
 }
 
 func TestConcurrentAddExample_RaceCondition(t *testing.T) {
-	utils.DB = testresources.NewSingleTestConnection(t)
-	err := utils.DB.AutoMigrate(&models.Word{}, &models.Example{})
-	assert.NoError(t, err)
+	store, h := setupTestStore(t)
 
 	// Dodaj słowo, do którego będą dodawane przykłady
-	word := models.Word{Word: "testowy", Language: "pl"}
-	utils.DB.Create(&word)
+	word := createWord(t, store, models.Word{Word: "testowy", Language: "pl"})
 
 	var wg sync.WaitGroup
 	concurrency := 10
-	exampleText := "To jest przykład wyścigu."
+	exampleText := "To jest testowy przykład wyścigu."
 
 	for i := 0; i < concurrency; i++ {
 		wg.Add(1)
@@ -135,35 +174,30 @@ func TestConcurrentAddExample_RaceCondition(t *testing.T) {
 				},
 			}
 
-			_, _ = handlers.AddExample(params)
+			_, _ = h.AddExample(params)
 		}()
 	}
 
 	wg.Wait()
 
 	// Check if only one example exists in db
-	var examples []models.Example
-	err = utils.DB.Where("example = ? AND word_id = ?", exampleText, word.ID).Find(&examples).Error
+	examples, err := store.Examples().ForWord(word.ID)
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(examples), "Should only have one example after concurrent insertions")
 }
 
 func TestConcurrentUpdateExample_RaceCondition(t *testing.T) {
-	utils.DB = testresources.NewSingleTestConnection(t)
-	err := utils.DB.AutoMigrate(&models.Word{}, &models.Example{})
-	assert.NoError(t, err)
+	store, h := setupTestStore(t)
 
 	// Prepare data
-	word := models.Word{Word: "testing", Language: "en"}
-	utils.DB.Create(&word)
+	word := createWord(t, store, models.Word{Word: "testing", Language: "en"})
 
-	example := models.Example{WordID: word.ID, Example: "Original text"}
-	utils.DB.Create(&example)
+	example := createExample(t, store, models.Example{WordID: word.ID, Example: "Original text"})
 
 	var wg sync.WaitGroup
 	concurrency := 5
 	newTexts := []string{
-		"Update A", "Update B", "Update C", "Update D", "Update E",
+		"Testing update A", "Testing update B", "Testing update C", "Testing update D", "Testing update E",
 	}
 
 	for i := 0; i < concurrency; i++ {
@@ -173,20 +207,20 @@ func TestConcurrentUpdateExample_RaceCondition(t *testing.T) {
 
 			params := graphql.ResolveParams{
 				Args: map[string]interface{}{
-					"id":      int(example.ID),
-					"example": text,
+					"id":              int(example.ID),
+					"example":         text,
+					"expectedVersion": 1,
 				},
 			}
 
-			_, _ = handlers.UpdateExample(params)
+			_, _ = h.UpdateExample(params)
 		}(newTexts[i])
 	}
 
 	wg.Wait()
 
 	// Check final version
-	var final models.Example
-	err = utils.DB.First(&final, example.ID).Error
+	final, err := store.Examples().Get(example.ID)
 	assert.NoError(t, err)
 
 	found := false
@@ -200,16 +234,12 @@ func TestConcurrentUpdateExample_RaceCondition(t *testing.T) {
 }
 
 func TestDeleteAndUpdateExample_RaceCondition(t *testing.T) {
-	utils.DB = testresources.NewSingleTestConnection(t)
-	err := utils.DB.AutoMigrate(&models.Word{}, &models.Example{})
-	assert.NoError(t, err)
+	store, h := setupTestStore(t)
 
 	// Prepare data
-	word := models.Word{Word: "testing", Language: "en"}
-	utils.DB.Create(&word)
+	word := createWord(t, store, models.Word{Word: "testing", Language: "en"})
 
-	example := models.Example{WordID: word.ID, Example: "To be deleted"}
-	utils.DB.Create(&example)
+	example := createExample(t, store, models.Example{WordID: word.ID, Example: "To be deleted"})
 
 	var wg sync.WaitGroup
 	wg.Add(2)
@@ -223,7 +253,7 @@ func TestDeleteAndUpdateExample_RaceCondition(t *testing.T) {
 				"id": int(example.ID),
 			},
 		}
-		_, _ = handlers.DeleteExample(params)
+		_, _ = h.DeleteExample(params)
 	}()
 
 	go func() {
@@ -231,25 +261,62 @@ func TestDeleteAndUpdateExample_RaceCondition(t *testing.T) {
 
 		params := graphql.ResolveParams{
 			Args: map[string]interface{}{
-				"id":      int(example.ID),
-				"example": "Updated text",
+				"id":              int(example.ID),
+				"example":         "Updated testing text",
+				"expectedVersion": 1,
 			},
 		}
-		_, _ = handlers.UpdateExample(params)
+		_, _ = h.UpdateExample(params)
 	}()
 
 	wg.Wait()
 
-	// Check if record still exists
-	var count int64
-	utils.DB.Model(&models.Example{}).Where("id = ?", example.ID).Count(&count)
-
-	// Can be 0 or 1, but if 1 exist, should have changed text
-	if count == 1 {
-		var updated models.Example
-		utils.DB.First(&updated, example.ID)
-		assert.Equal(t, "Updated text", updated.Example)
+	// Record can be deleted, but if it still exists, it should have changed text
+	updated, err := store.Examples().Get(example.ID)
+	if err == nil {
+		assert.Equal(t, "Updated testing text", updated.Example)
 	} else {
-		assert.Equal(t, int64(0), count)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
 	}
 }
+
+func TestSearchExamplesWithEachStore(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store repository.Store) {
+		h := handlers.New(store)
+		cat := createWord(t, store, models.Word{Word: "cat", Language: "en"})
+		kot := createWord(t, store, models.Word{Word: "kot", Language: "pl"})
+		createExample(t, store, models.Example{WordID: cat.ID, Example: "The cat is sleeping."})
+		createExample(t, store, models.Example{WordID: cat.ID, Example: "A dog chased the cat."})
+		createExample(t, store, models.Example{WordID: kot.ID, Example: "Kot śpi na kanapie."})
+		search := func(args map[string]interface{}) []handlers.ExampleMatch {
+			t.Helper()
+			result, err := h.SearchExamples(graphql.ResolveParams{Args: args})
+			assert.NoError(t, err)
+			matches, ok := result.([]handlers.ExampleMatch)
+			assert.True(t, ok)
+			return matches
+		}
+
+		// All words of query have to match, they are highlighted
+		matches := search(map[string]interface{}{"query": "cat sleeping", "language": "en"})
+		if assert.Len(t, matches, 1) {
+			assert.Equal(t, "The cat is sleeping.", matches[0].Example.Example)
+			assert.Equal(t, "The <b>cat</b> is <b>sleeping</b>.", matches[0].Highlighted)
+			assert.Equal(t, "cat", matches[0].Word.Word)
+		}
+
+		// All languages are searched when language is not given, diacritics are ignored
+		assert.Len(t, search(map[string]interface{}{"query": "spi or dog"}), 2)
+		assert.Len(t, search(map[string]interface{}{"query": "cat -dog", "language": "en"}), 1)
+		assert.Len(t, search(map[string]interface{}{"query": "kot", "language": "en"}), 0)
+
+		_, err := h.SearchExamples(graphql.ResolveParams{
+			Args: map[string]interface{}{"query": "kot", "language": "xx"},
+		})
+		assert.Error(t, err)
+		_, err = h.SearchExamples(graphql.ResolveParams{
+			Args: map[string]interface{}{"query": " "},
+		})
+		assert.Equal(t, apperrors.CodeValidation, apperrors.CodeOf(err))
+	})
+}
@@ -0,0 +1,144 @@
+package handlers_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/stretchr/testify/assert"
+	"github.com/tdawidzi/dictionary_app/auth"
+	"github.com/tdawidzi/dictionary_app/config"
+	"github.com/tdawidzi/dictionary_app/handlers"
+	"github.com/tdawidzi/dictionary_app/models"
+	"github.com/tdawidzi/dictionary_app/repository"
+	"github.com/tdawidzi/dictionary_app/schema"
+	"github.com/tdawidzi/dictionary_app/utils"
+)
+
+// Handlers are tested with in-memory store, so tests need no database server.
+// Entries handlers work on are added directly to the store.
+
+func setupTestStore(t *testing.T) (repository.Store, *handlers.Handlers) {
+	t.Helper()
+
+	store := repository.NewMemoryStore(utils.DefaultLanguages...)
+	return store, handlers.New(store)
+}
+
+func createWord(t *testing.T, store repository.Store, word models.Word) models.Word {
+	t.Helper()
+
+	if _, err := store.Words().Create(&word); err != nil {
+		t.Fatalf("failed to create word: %v", err)
+	}
+	return word
+}
+
+func createTranslation(t *testing.T, store repository.Store, translation models.Translation) models.Translation {
+	t.Helper()
+
+	if err := store.Translations().Create(&translation); err != nil {
+		t.Fatalf("failed to create translation: %v", err)
+	}
+	return translation
+}
+
+func createExample(t *testing.T, store repository.Store, example models.Example) models.Example {
+	t.Helper()
+
+	if _, err := store.Examples().Create(&example); err != nil {
+		t.Fatalf("failed to create example: %v", err)
+	}
+	return example
+}
+
+// findWords returns words with given spelling (not in trash)
+func findWords(t *testing.T, store repository.Store, text string) []models.Word {
+	t.Helper()
+
+	words, err := store.Words().Find(repository.WordLookup{Word: text})
+	if err != nil {
+		t.Fatalf("failed to find words: %v", err)
+	}
+	return words
+}
+
+// countTranslations counts translations of word (not in trash)
+func countTranslations(t *testing.T, store repository.Store, wordID uint) int {
+	t.Helper()
+
+	translations, err := store.Translations().ForWords([]uint{wordID})
+	if err != nil {
+		t.Fatalf("failed to fetch translations: %v", err)
+	}
+	return len(translations)
+}
+
+// forEachStore runs test with every store implementation - in-memory store and SQLite do not need database server
+func forEachStore(t *testing.T, test func(t *testing.T, store repository.Store)) {
+	t.Run("memory", func(t *testing.T) {
+		test(t, repository.NewMemoryStore(utils.DefaultLanguages...))
+	})
+	t.Run("sqlite", func(t *testing.T) {
+		db, err := utils.ConnectDB(&config.Config{
+			DB_Driver: config.DriverSQLite,
+			DB_Path:   filepath.Join(t.TempDir(), "dictionary.db"),
+		})
+		if err != nil {
+			t.Fatalf("failed to open database: %v", err)
+		}
+		sqlDB, _ := db.DB()
+		t.Cleanup(func() { sqlDB.Close() })
+		test(t, repository.NewGormStore(db))
+	})
+}
+
+// client executes GraphQL requests with schema of handlers, like the server does
+type client struct {
+	schema graphql.Schema
+}
+
+func newClient(t *testing.T, store repository.Store) client {
+	t.Helper()
+
+	s, err := schema.New(handlers.New(store))
+	if err != nil {
+		t.Fatalf("failed to build schema: %v", err)
+	}
+	return client{schema: s}
+}
+
+// doAs executes request of client authenticated by API key with given name, having given role
+func (c client) doAs(actor, role, request string) *graphql.Result {
+	principal := auth.Principal{Name: actor, Method: auth.MethodAPIKey, Role: role}
+	return graphql.Do(graphql.Params{
+		Schema:        c.schema,
+		Context:       auth.WithPrincipal(handlers.WithLoaders(context.Background()), principal),
+		RequestString: request,
+	})
+}
+
+// do executes request of admin "ala"
+func (c client) do(request string) *graphql.Result {
+	return c.doAs("ala", models.RoleAdmin, request)
+}
+
+// data executes request of admin, which has to succeed, and returns its data
+func (c client) data(t *testing.T, request string) map[string]interface{} {
+	t.Helper()
+
+	result := c.do(request)
+	assert.Empty(t, result.Errors, request)
+	data, _ := result.Data.(map[string]interface{})
+	return data
+}
+
+// assertErrorCode checks that request failed with single error of given code
+func assertErrorCode(t *testing.T, result *graphql.Result, code string) {
+	t.Helper()
+
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, code, result.Errors[0].Extensions["code"])
+	}
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/tdawidzi/dictionary_app/apperrors"
+	"github.com/tdawidzi/dictionary_app/models"
+	"github.com/tdawidzi/dictionary_app/repository"
+	"github.com/tdawidzi/dictionary_app/validation"
+
+	"github.com/graphql-go/graphql"
+)
+
+// GetFormsForWord fetches full paradigm (all inflected forms) of a word
+func (h *Handlers) GetFormsForWord(p graphql.ResolveParams) (interface{}, error) {
+	word, ok := sourceWord(p)
+	if !ok {
+		return nil, fmt.Errorf("invalid source for forms")
+	}
+
+	loaders, fromRequest := h.loadersFrom(p)
+	load := loaders.forms.Load(word.ID)
+	return batched(fromRequest, func() (interface{}, error) {
+		forms, err := load()
+		if err != nil {
+			return nil, err
+		}
+		return append([]models.WordForm{}, forms...), nil
+	})
+}
+
+// SetParadigm replaces all inflected forms of a word with given ones (empty list clears paradigm)
+func (h *Handlers) SetParadigm(p graphql.ResolveParams) (interface{}, error) {
+	wordText, _ := p.Args["word"].(string)
+	language, _ := p.Args["language"].(string)
+	homograph, _ := p.Args["homograph"].(int)
+	formArgs, _ := p.Args["forms"].([]interface{})
+	dictionaryID, err := dictionaryArg(h.store, p)
+	if err != nil {
+		return nil, err
+	}
+
+	word, err := findWord(h.store, dictionaryID, wordText, language, homograph)
+	if err != nil {
+		return nil, lookupError("word", err)
+	}
+	lang, err := findLanguage(h.store, word.Language)
+	if err != nil {
+		return nil, err
+	}
+
+	var v validation.Validator
+	forms := make([]models.WordForm, 0, len(formArgs))
+	for i, arg := range formArgs {
+		input, ok := arg.(map[string]interface{})
+		if !ok {
+			return nil, apperrors.Validation("invalid form input")
+		}
+		form := models.WordForm{WordID: word.ID}
+		form.Form, _ = input["form"].(string)
+		field := fmt.Sprintf("forms[%d].form", i)
+		form.Form = v.Text(field, form.Form, validation.MaxWordLength)
+		v.Script(field, form.Form, lang.Script)
+		form.Case, _ = input["case"].(string)
+		form.Number, _ = input["number"].(string)
+		form.Person, _ = input["person"].(string)
+		form.Tense, _ = input["tense"].(string)
+		form.Mood, _ = input["mood"].(string)
+		form.Aspect, _ = input["aspect"].(string)
+		form.Gender, _ = input["gender"].(string)
+		form.Degree, _ = input["degree"].(string)
+		forms = append(forms, form)
+	}
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+
+	// Old paradigm is removed only if the new one is saved
+	err = h.store.Transaction(func(tx repository.Store) error {
+		// Word is locked, so concurrent paradigms are not merged and previous paradigm is recorded correctly
+		if err := lockWords(tx, word.ID); err != nil {
+			return err
+		}
+		before, err := tx.Forms().ForWords([]uint{word.ID})
+		if err != nil {
+			return fmt.Errorf("failed to query forms: %w", err)
+		}
+		if err := replaceForms(tx, word.ID, forms); err != nil {
+			return err
+		}
+		_, err = record(tx, p, paradigmChange(word.ID, newParadigmValues(before), newParadigmValues(forms)))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return forms, nil
+}
+
+// replaceForms replaces paradigm of word with given forms
+func replaceForms(tx repository.Store, wordID uint, forms []models.WordForm) error {
+	if err := tx.Forms().DeleteForWord(wordID); err != nil {
+		return fmt.Errorf("failed to delete forms: %w", err)
+	}
+	if len(forms) == 0 {
+		return nil
+	}
+	if err := tx.Forms().Create(forms); err != nil {
+		return writeError("create forms", err)
+	}
+	return nil
+}
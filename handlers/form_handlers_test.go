@@ -0,0 +1,139 @@
+package handlers_test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/stretchr/testify/assert"
+	"github.com/tdawidzi/dictionary_app/models"
+)
+
+func TestSetAndGetParadigm(t *testing.T) {
+	store, h := setupTestStore(t)
+
+	kot := createWord(t, store, models.Word{Word: "kot", Language: "pl", PartOfSpeech: models.PartOfSpeechNoun})
+
+	params := graphql.ResolveParams{
+		Args: map[string]interface{}{
+			"word":     "kot",
+			"language": "pl",
+			"forms": []interface{}{
+				map[string]interface{}{"form": "kot", "case": models.CaseNominative, "number": models.NumberSingular},
+				map[string]interface{}{"form": "kota", "case": models.CaseGenitive, "number": models.NumberSingular},
+				map[string]interface{}{"form": "koty", "case": models.CaseNominative, "number": models.NumberPlural},
+			},
+		},
+	}
+
+	result, err := h.SetParadigm(params)
+	assert.NoError(t, err)
+	forms, ok := result.([]models.WordForm)
+	assert.True(t, ok)
+	assert.Len(t, forms, 3)
+
+	result, err = h.GetFormsForWord(graphql.ResolveParams{Source: kot})
+	assert.NoError(t, err)
+	forms, ok = result.([]models.WordForm)
+	assert.True(t, ok)
+	assert.Len(t, forms, 3)
+	assert.Equal(t, "kota", forms[1].Form)
+	assert.Equal(t, models.CaseGenitive, forms[1].Case)
+
+	// Setting paradigm again replaces previous one
+	params.Args["forms"] = []interface{}{
+		map[string]interface{}{"form": "kotu", "case": models.CaseDative, "number": models.NumberSingular},
+	}
+	_, err = h.SetParadigm(params)
+	assert.NoError(t, err)
+
+	forms, err = store.Forms().ForWords([]uint{kot.ID})
+	assert.NoError(t, err)
+	assert.Len(t, forms, 1)
+}
+
+func TestGetWordByInflectedForm(t *testing.T) {
+	store, h := setupTestStore(t)
+
+	kot := createWord(t, store, models.Word{Word: "kot", Language: "pl"})
+	assert.NoError(t, store.Forms().Create([]models.WordForm{
+		{WordID: kot.ID, Form: "kota", Case: models.CaseGenitive, Number: models.NumberSingular},
+	}))
+
+	result, err := h.GetWordByText(graphql.ResolveParams{
+		Args: map[string]interface{}{"word": "kota"},
+	})
+	assert.NoError(t, err)
+	word, ok := result.(models.Word)
+	assert.True(t, ok)
+	assert.Equal(t, "kot", word.Word)
+
+	// Forms are searched only within given language
+	_, err = h.GetWordByText(graphql.ResolveParams{
+		Args: map[string]interface{}{"word": "kota", "language": "en"},
+	})
+	assert.Error(t, err)
+}
+
+func TestGetWordByNormalizedInflectedForm(t *testing.T) {
+	store, h := setupTestStore(t)
+
+	zolw := createWord(t, store, models.Word{Word: "żółw", Language: "pl"})
+	assert.NoError(t, store.Forms().Create([]models.WordForm{
+		{WordID: zolw.ID, Form: "żółwia", Case: models.CaseGenitive, Number: models.NumberSingular},
+	}))
+
+	result, err := h.GetWordByText(graphql.ResolveParams{
+		Args: map[string]interface{}{"word": "Zolwia"},
+	})
+	assert.NoError(t, err)
+	word, ok := result.(models.Word)
+	assert.True(t, ok)
+	assert.Equal(t, "żółw", word.Word)
+
+	_, err = h.GetWordByText(graphql.ResolveParams{
+		Args: map[string]interface{}{"word": "Zolwia", "exact": true},
+	})
+	assert.Error(t, err)
+}
+
+func TestConcurrentSetParadigm(t *testing.T) {
+	store, h := setupTestStore(t)
+
+	kot := createWord(t, store, models.Word{Word: "kot", Language: "pl", PartOfSpeech: models.PartOfSpeechNoun})
+	cases := []string{models.CaseNominative, models.CaseGenitive, models.CaseDative, models.CaseAccusative, models.CaseInstrumental}
+
+	// Each request sets paradigm of different size
+	const concurrency = 5
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			forms := []interface{}{}
+			for _, c := range cases[:i+1] {
+				forms = append(forms, map[string]interface{}{"form": "kot", "case": c, "number": models.NumberSingular})
+			}
+			_, err := h.SetParadigm(graphql.ResolveParams{
+				Args: map[string]interface{}{"word": "kot", "language": "pl", "forms": forms},
+			})
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	// Paradigms are not merged, every change starts from the previous one
+	forms, err := store.Forms().ForWords([]uint{kot.ID})
+	assert.NoError(t, err)
+	entries, err := store.Audit().ForWord(kot.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, entries, concurrency) {
+		assert.Equal(t, "[]", entries[0].Before)
+		for i := 1; i < concurrency; i++ {
+			assert.Equal(t, entries[i-1].After, entries[i].Before, fmt.Sprintf("change %d", i))
+		}
+		assert.Equal(t, len(forms), strings.Count(entries[concurrency-1].After, `"form"`))
+	}
+}
@@ -0,0 +1,12 @@
+package handlers
+
+import "github.com/tdawidzi/dictionary_app/repository"
+
+// Handlers resolves GraphQL fields using given store
+type Handlers struct {
+	store repository.Store
+}
+
+func New(store repository.Store) *Handlers {
+	return &Handlers{store: store}
+}
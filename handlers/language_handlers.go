@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+
+	"github.com/tdawidzi/dictionary_app/apperrors"
+	"github.com/tdawidzi/dictionary_app/models"
+	"github.com/tdawidzi/dictionary_app/repository"
+	"github.com/tdawidzi/dictionary_app/validation"
+
+	"github.com/graphql-go/graphql"
+)
+
+// ISO 639-1 or ISO 639-3 code
+var languageCode = regexp.MustCompile(`^[a-z]{2,3}$`)
+
+// GetLanguages fetches all languages registered in dictionary
+func (h *Handlers) GetLanguages(p graphql.ResolveParams) (interface{}, error) {
+	languages, err := h.store.Languages().List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch languages: %w", err)
+	}
+	return languages, nil
+}
+
+// AddLanguage registers new language, so words in this language can be added
+func (h *Handlers) AddLanguage(p graphql.ResolveParams) (interface{}, error) {
+	code, _ := p.Args["code"].(string)
+	name, _ := p.Args["name"].(string)
+	script, hasScript := p.Args["script"].(string)
+	direction, hasDirection := p.Args["direction"].(string)
+	searchConfig, hasSearchConfig := p.Args["searchConfig"].(string)
+
+	var v validation.Validator
+	code = v.Text("code", code, 3)
+	if !v.Failed("code") && !languageCode.MatchString(code) {
+		v.Fail("code", "must be ISO 639 code - 2 or 3 lower case letters")
+	}
+	name = v.Text("name", name, validation.MaxLabelLength)
+	if hasScript {
+		script = validation.Normalize(script)
+		v.OneOf("script", script, slices.Sorted(maps.Keys(validation.Scripts))...)
+	}
+	if hasDirection {
+		direction = validation.Normalize(direction)
+		v.OneOf("direction", direction, "ltr", "rtl")
+	}
+	searchConfig = validation.Normalize(searchConfig)
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+
+	// Check if language is already registered
+	if existing, err := h.store.Languages().Find(code); err == nil {
+		return existing, nil
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return nil, fmt.Errorf("failed to query language: %w", err)
+	}
+
+	language := models.Language{Code: code, Name: name, Script: "Latn", Direction: "ltr", SearchConfig: "simple"}
+	if hasScript {
+		language.Script = script
+	}
+	if hasDirection {
+		language.Direction = direction
+	}
+	if hasSearchConfig {
+		// Text search configuration has to be installed in database
+		exists, err := h.store.Languages().SearchConfigExists(searchConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query search configuration: %w", err)
+		}
+		if !exists {
+			return nil, apperrors.Validation("unsupported search configuration: %s", searchConfig)
+		}
+		language.SearchConfig = searchConfig
+	}
+	err := h.store.Transaction(func(tx repository.Store) error {
+		if err := tx.Languages().Create(&language); err != nil {
+			return writeError("add language", err)
+		}
+		_, err := record(tx, p, languageChange(models.OperationCreate, nil, newLanguageValues(language)))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return language, nil
+}
+
+// validLanguage checks if language with given code is registered - unregistered language is reported as invalid field
+func validLanguage(store repository.Store, v *validation.Validator, field, code string) (models.Language, error) {
+	language, err := store.Languages().Find(code)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			v.Fail(field, "unsupported language: %s", code)
+			return language, nil
+		}
+		return language, fmt.Errorf("failed to query language: %w", err)
+	}
+	return language, nil
+}
+
+// findLanguage checks if language with given code is registered
+func findLanguage(store repository.Store, code string) (models.Language, error) {
+	language, err := store.Languages().Find(code)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return language, apperrors.Validation("unsupported language: %s", code)
+		}
+		return language, fmt.Errorf("failed to query language: %w", err)
+	}
+	return language, nil
+}
@@ -0,0 +1,86 @@
+package handlers_test
+
+import (
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/stretchr/testify/assert"
+	"github.com/tdawidzi/dictionary_app/apperrors"
+	"github.com/tdawidzi/dictionary_app/models"
+)
+
+func TestAddAndGetLanguages(t *testing.T) {
+	_, h := setupTestStore(t)
+
+	params := graphql.ResolveParams{
+		Args: map[string]interface{}{
+			"code":   "uk",
+			"name":   "Ukrainian",
+			"script": "Cyrl",
+		},
+	}
+
+	result, err := h.AddLanguage(params)
+	assert.NoError(t, err)
+
+	language, ok := result.(models.Language)
+	assert.True(t, ok)
+	assert.Equal(t, "uk", language.Code)
+	assert.Equal(t, "Cyrl", language.Script)
+	assert.Equal(t, "ltr", language.Direction)
+
+	// Get languages
+	result, err = h.GetLanguages(graphql.ResolveParams{})
+	assert.NoError(t, err)
+
+	languages, ok := result.([]models.Language)
+	assert.True(t, ok)
+	assert.Len(t, languages, 3)
+
+	// Words can be added in new language
+	wordParams := graphql.ResolveParams{
+		Args: map[string]interface{}{
+			"word":     "кіт",
+			"language": "uk",
+		},
+	}
+	_, err = h.AddWord(wordParams)
+	assert.NoError(t, err)
+}
+
+func TestAddLanguageValidation(t *testing.T) {
+	_, h := setupTestStore(t)
+
+	_, err := h.AddLanguage(graphql.ResolveParams{
+		Args: map[string]interface{}{
+			"code":      "DE",
+			"name":      " ",
+			"script":    "Latin",
+			"direction": "ttb",
+		},
+	})
+	assert.Equal(t, []apperrors.FieldError{
+		{Field: "code", Message: "must be ISO 639 code - 2 or 3 lower case letters"},
+		{Field: "name", Message: "cannot be empty"},
+		{Field: "script", Message: "must be one of: Arab, Armn, Cyrl, Deva, Geor, Grek, Hang, Hani, Hebr, Jpan, Kore, Latn, Thai"},
+		{Field: "direction", Message: "must be one of: ltr, rtl"},
+	}, fieldErrors(t, err))
+}
+
+func TestAddExistingLanguage(t *testing.T) {
+	_, h := setupTestStore(t)
+
+	params := graphql.ResolveParams{
+		Args: map[string]interface{}{
+			"code": "pl",
+			"name": "Polski",
+		},
+	}
+
+	result, err := h.AddLanguage(params)
+	assert.NoError(t, err)
+
+	language, ok := result.(models.Language)
+	assert.True(t, ok)
+	assert.Equal(t, "Polish", language.Name)
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/tdawidzi/dictionary_app/dataloader"
+	"github.com/tdawidzi/dictionary_app/models"
+	"github.com/tdawidzi/dictionary_app/repository"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// Loaders batch queries of nested fields (translations, senses, forms, examples, dictionaries) within single GraphQL request.
+// E.g. translations of all words on a page are fetched with one query instead of one query per word.
+type Loaders struct {
+	once              sync.Once
+	wordTranslations  *dataloader.Loader[uint, []models.Translation] // by word id
+	senses            *dataloader.Loader[uint, []models.Sense]       // by word id
+	forms             *dataloader.Loader[uint, []models.WordForm]    // by word id
+	senseTranslations *dataloader.Loader[uint, []models.Translation] // by sense id
+	senseExamples     *dataloader.Loader[uint, []models.Example]     // by sense id
+	dictionaries      *dataloader.Loader[uint, models.Dictionary]    // by dictionary id
+}
+
+type loadersKey struct{}
+
+// NewLoaders creates loaders for single request - loaded values are cached until request ends.
+// Loaders are bound to store of the first handler using them.
+func NewLoaders() *Loaders {
+	return &Loaders{}
+}
+
+// init binds loaders to store
+func (l *Loaders) init(store repository.Store) *Loaders {
+	l.once.Do(func() {
+		l.wordTranslations = dataloader.New(func(ids []uint) (map[uint][]models.Translation, error) {
+			return loadWordTranslations(store, ids)
+		})
+		l.senses = dataloader.New(func(ids []uint) (map[uint][]models.Sense, error) {
+			return loadSenses(store, ids)
+		})
+		l.forms = dataloader.New(func(ids []uint) (map[uint][]models.WordForm, error) {
+			return loadForms(store, ids)
+		})
+		l.senseTranslations = dataloader.New(func(ids []uint) (map[uint][]models.Translation, error) {
+			return loadSenseTranslations(store, ids)
+		})
+		l.senseExamples = dataloader.New(func(ids []uint) (map[uint][]models.Example, error) {
+			return loadSenseExamples(store, ids)
+		})
+		l.dictionaries = dataloader.New(func(ids []uint) (map[uint]models.Dictionary, error) {
+			return loadDictionaries(store)
+		})
+	})
+	return l
+}
+
+// WithLoaders attaches new loaders to request context
+func WithLoaders(ctx context.Context) context.Context {
+	return context.WithValue(ctx, loadersKey{}, NewLoaders())
+}
+
+// loadersFrom returns loaders of request. Resolvers called without them (e.g. directly) get new ones, so they load only their own key.
+// Mutations do not use request loaders - batched values are resolved after all mutation fields, so they would not
+// show changes of every field (GraphQL executes fields of mutation one by one).
+func (h *Handlers) loadersFrom(p graphql.ResolveParams) (*Loaders, bool) {
+	if operation, ok := p.Info.Operation.(*ast.OperationDefinition); ok && operation.Operation == ast.OperationTypeMutation {
+		return NewLoaders().init(h.store), false
+	}
+	if p.Context != nil {
+		if loaders, ok := p.Context.Value(loadersKey{}).(*Loaders); ok {
+			return loaders.init(h.store), true
+		}
+	}
+	return NewLoaders().init(h.store), false
+}
+
+// batched returns thunk of resolver using loaders from request - GraphQL executor calls it after all sibling fields queued their keys.
+// Without request loaders the thunk is called immediately.
+func batched(fromRequest bool, thunk func() (interface{}, error)) (interface{}, error) {
+	if !fromRequest {
+		return thunk()
+	}
+	return thunk, nil
+}
+
+func loadWordTranslations(store repository.Store, wordIDs []uint) (map[uint][]models.Translation, error) {
+	translations, err := store.Translations().ForWords(wordIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch translations: %w", err)
+	}
+
+	// Translations are symmetric - each one belongs to both its words
+	byWord := make(map[uint][]models.Translation, len(wordIDs))
+	for _, t := range translations {
+		byWord[t.SourceWordID] = append(byWord[t.SourceWordID], t)
+		if t.TargetWordID != t.SourceWordID {
+			byWord[t.TargetWordID] = append(byWord[t.TargetWordID], t)
+		}
+	}
+	return byWord, nil
+}
+
+func loadSenses(store repository.Store, wordIDs []uint) (map[uint][]models.Sense, error) {
+	senses, err := store.Senses().ForWords(wordIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch senses: %w", err)
+	}
+
+	byWord := make(map[uint][]models.Sense, len(wordIDs))
+	for _, s := range senses {
+		byWord[s.WordID] = append(byWord[s.WordID], s)
+	}
+	return byWord, nil
+}
+
+func loadForms(store repository.Store, wordIDs []uint) (map[uint][]models.WordForm, error) {
+	forms, err := store.Forms().ForWords(wordIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch forms: %w", err)
+	}
+
+	byWord := make(map[uint][]models.WordForm, len(wordIDs))
+	for _, f := range forms {
+		byWord[f.WordID] = append(byWord[f.WordID], f)
+	}
+	return byWord, nil
+}
+
+func loadSenseTranslations(store repository.Store, senseIDs []uint) (map[uint][]models.Translation, error) {
+	translations, err := store.Translations().ForSenses(senseIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch translations: %w", err)
+	}
+
+	bySense := make(map[uint][]models.Translation, len(senseIDs))
+	for _, t := range translations {
+		if t.SourceSenseID != nil {
+			bySense[*t.SourceSenseID] = append(bySense[*t.SourceSenseID], t)
+		}
+		if t.TargetSenseID != nil {
+			bySense[*t.TargetSenseID] = append(bySense[*t.TargetSenseID], t)
+		}
+	}
+	return bySense, nil
+}
+
+func loadSenseExamples(store repository.Store, senseIDs []uint) (map[uint][]models.Example, error) {
+	examples, err := store.Examples().ForSenses(senseIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch examples: %w", err)
+	}
+
+	bySense := make(map[uint][]models.Example, len(senseIDs))
+	for _, e := range examples {
+		bySense[*e.SenseID] = append(bySense[*e.SenseID], e)
+	}
+	return bySense, nil
+}
+
+// loadDictionaries fetches all dictionaries - there are few of them
+func loadDictionaries(store repository.Store) (map[uint]models.Dictionary, error) {
+	dictionaries, err := store.Dictionaries().List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch dictionaries: %w", err)
+	}
+
+	byID := make(map[uint]models.Dictionary, len(dictionaries))
+	for _, d := range dictionaries {
+		byID[d.ID] = d
+	}
+	return byID, nil
+}
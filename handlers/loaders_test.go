@@ -0,0 +1,166 @@
+package handlers_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/stretchr/testify/assert"
+	"github.com/tdawidzi/dictionary_app/handlers"
+	"github.com/tdawidzi/dictionary_app/models"
+	"github.com/tdawidzi/dictionary_app/repository"
+	"github.com/tdawidzi/dictionary_app/schema"
+)
+
+// countingStore counts queries of nested fields per table
+type countingStore struct {
+	repository.Store
+	queries *queryCounter
+}
+
+type queryCounter struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (c *queryCounter) add(table string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts[table]++
+}
+
+func (c *queryCounter) get(table string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counts[table]
+}
+
+func (s countingStore) Translations() repository.TranslationRepository {
+	return countingTranslations{s.Store.Translations(), s.queries}
+}
+
+func (s countingStore) Senses() repository.SenseRepository {
+	return countingSenses{s.Store.Senses(), s.queries}
+}
+
+func (s countingStore) Forms() repository.FormRepository {
+	return countingForms{s.Store.Forms(), s.queries}
+}
+
+func (s countingStore) Examples() repository.ExampleRepository {
+	return countingExamples{s.Store.Examples(), s.queries}
+}
+
+type countingTranslations struct {
+	repository.TranslationRepository
+	queries *queryCounter
+}
+
+func (r countingTranslations) ForWords(wordIDs []uint) ([]models.Translation, error) {
+	r.queries.add("translations")
+	return r.TranslationRepository.ForWords(wordIDs)
+}
+
+func (r countingTranslations) ForSenses(senseIDs []uint) ([]models.Translation, error) {
+	r.queries.add("translations")
+	return r.TranslationRepository.ForSenses(senseIDs)
+}
+
+type countingSenses struct {
+	repository.SenseRepository
+	queries *queryCounter
+}
+
+func (r countingSenses) ForWords(wordIDs []uint) ([]models.Sense, error) {
+	r.queries.add("senses")
+	return r.SenseRepository.ForWords(wordIDs)
+}
+
+type countingForms struct {
+	repository.FormRepository
+	queries *queryCounter
+}
+
+func (r countingForms) ForWords(wordIDs []uint) ([]models.WordForm, error) {
+	r.queries.add("word_forms")
+	return r.FormRepository.ForWords(wordIDs)
+}
+
+type countingExamples struct {
+	repository.ExampleRepository
+	queries *queryCounter
+}
+
+func (r countingExamples) ForSenses(senseIDs []uint) ([]models.Example, error) {
+	r.queries.add("examples")
+	return r.ExampleRepository.ForSenses(senseIDs)
+}
+
+func TestNestedFieldsAreBatchLoaded(t *testing.T) {
+	store, _ := setupTestStore(t)
+
+	for i := 0; i < 5; i++ {
+		pl := createWord(t, store, models.Word{Word: fmt.Sprintf("słowo%d", i), Language: "pl"})
+		en := createWord(t, store, models.Word{Word: fmt.Sprintf("word%d", i), Language: "en"})
+		createTranslation(t, store, models.Translation{SourceWordID: pl.ID, TargetWordID: en.ID})
+		assert.NoError(t, store.Senses().Create(&models.Sense{WordID: pl.ID, Ordinal: 1, Definition: fmt.Sprintf("znaczenie %d", i)}))
+	}
+
+	queries := &queryCounter{counts: map[string]int{}}
+	s, err := schema.New(handlers.New(countingStore{store, queries}))
+	assert.NoError(t, err)
+
+	result := graphql.Do(graphql.Params{
+		Schema:  s,
+		Context: handlers.WithLoaders(context.Background()),
+		RequestString: `{
+			words(language: "pl") {
+				edges {
+					node {
+						word
+						translations { word translations { word } }
+						senses { definition examples { example } }
+						forms { form }
+					}
+				}
+			}
+		}`,
+	})
+	assert.Empty(t, result.Errors)
+
+	// One query per nested field and level, regardless of number of words
+	assert.Equal(t, 2, queries.get("translations"))
+	assert.Equal(t, 1, queries.get("senses"))
+	assert.Equal(t, 1, queries.get("word_forms"))
+	assert.Equal(t, 1, queries.get("examples"))
+
+	edges := result.Data.(map[string]interface{})["words"].(map[string]interface{})["edges"].([]interface{})
+	assert.Len(t, edges, 5)
+	node := edges[0].(map[string]interface{})["node"].(map[string]interface{})
+	assert.Equal(t, "słowo0", node["word"])
+	translations := node["translations"].([]interface{})
+	if assert.Len(t, translations, 1) {
+		translation := translations[0].(map[string]interface{})
+		assert.Equal(t, "word0", translation["word"])
+		// Translations are symmetric
+		assert.Equal(t, []interface{}{map[string]interface{}{"word": "słowo0"}}, translation["translations"])
+	}
+}
+
+func TestMutationFieldsAreNotBatchedWithEachStore(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store repository.Store) {
+		c := newClient(t, store)
+
+		// Fields of mutation show changes of the previous ones
+		data := c.data(t, `mutation {
+			before: addWord(word: "pies", language: "pl") { translations { word } }
+			dog: addWord(word: "dog", language: "en") { id }
+			translation: addTranslation(sourceWord: "pies", sourceLanguage: "pl", targetWord: "dog", targetLanguage: "en") { id }
+			after: addWord(word: "pies", language: "pl") { translations { word } }
+		}`)
+		assert.Equal(t, map[string]interface{}{"translations": []interface{}{}}, data["before"])
+		assert.Equal(t, map[string]interface{}{"translations": []interface{}{map[string]interface{}{"word": "dog"}}}, data["after"])
+	})
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/tdawidzi/dictionary_app/apperrors"
+	"github.com/tdawidzi/dictionary_app/models"
+	"github.com/tdawidzi/dictionary_app/repository"
+)
+
+// Relay style connection of words (https://relay.dev/graphql/connections.htm)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// Sort orders of words list
+const (
+	WordSortAlphabetical = repository.SortAlphabetical
+	WordSortNewest       = repository.SortNewest
+	WordSortID           = repository.SortID
+)
+
+var WordSorts = []string{WordSortAlphabetical, WordSortNewest, WordSortID}
+
+// WordConnection is single page of words
+type WordConnection struct {
+	Edges      []WordEdge
+	PageInfo   PageInfo
+	TotalCount int64 // number of words matching filters, on all pages
+}
+
+type WordEdge struct {
+	Cursor string
+	Node   models.Word
+}
+
+type PageInfo struct {
+	HasNextPage     bool
+	HasPreviousPage bool
+	StartCursor     *string
+	EndCursor       *string
+}
+
+var errInvalidCursor = apperrors.Validation("invalid cursor")
+
+// wordCursor points at word in list sorted in given order - it holds the values the list is sorted by
+type wordCursor struct {
+	Sort    string `json:"s"`
+	SortKey []byte `json:"k,omitempty"`
+	ID      uint   `json:"i"`
+}
+
+func encodeWordCursor(sort string, word models.Word) string {
+	cursor := wordCursor{Sort: sort, ID: word.ID}
+	if sort == WordSortAlphabetical {
+		cursor.SortKey = word.SortKey
+	}
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeWordCursor reads cursor, which has to be created for the same sort order
+func decodeWordCursor(sort, encoded string) (wordCursor, error) {
+	var cursor wordCursor
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cursor, errInvalidCursor
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Sort != sort {
+		return cursor, errInvalidCursor
+	}
+	return cursor, nil
+}
@@ -0,0 +1,64 @@
+//go:build postgres
+
+package handlers_test
+
+// Full-text and trigram search exist only in PostgreSQL, so these tests run on embedded PostgreSQL:
+//	go test -tags postgres ./handlers
+
+import (
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/stretchr/testify/assert"
+	"github.com/tdawidzi/dictionary_app/handlers"
+	"github.com/tdawidzi/dictionary_app/models"
+	"github.com/tdawidzi/dictionary_app/repository"
+	"github.com/tdawidzi/dictionary_app/testresources"
+)
+
+func setupPostgresTestStore(t *testing.T) (repository.Store, *handlers.Handlers) {
+	store := repository.NewGormStore(testresources.NewSingleTestConnection(t))
+	return store, handlers.New(store)
+}
+
+func TestPostgresGetSuggestions(t *testing.T) {
+	store, _ := setupPostgresTestStore(t)
+	testGetSuggestions(t, suggestionHandlers(t, store))
+}
+
+func TestPostgresSearchExamples(t *testing.T) {
+	store, h := setupPostgresTestStore(t)
+
+	cat := createWord(t, store, models.Word{Word: "cat", Language: "en"})
+	kot := createWord(t, store, models.Word{Word: "kot", Language: "pl"})
+	createExample(t, store, models.Example{WordID: cat.ID, Example: "The cats are sleeping."})
+	createExample(t, store, models.Example{WordID: cat.ID, Example: "A dog chased the cat."})
+	createExample(t, store, models.Example{WordID: kot.ID, Example: "Kot śpi na kanapie."})
+
+	// English stemming - "sleep" matches "sleeping"
+	result, err := h.SearchExamples(graphql.ResolveParams{
+		Args: map[string]interface{}{"query": "cat sleep", "language": "en"},
+	})
+	assert.NoError(t, err)
+	matches, ok := result.([]handlers.ExampleMatch)
+	assert.True(t, ok)
+	if assert.Len(t, matches, 1) {
+		assert.Equal(t, "The cats are sleeping.", matches[0].Example.Example)
+		assert.Equal(t, "The <b>cats</b> are <b>sleeping</b>.", matches[0].Highlighted)
+		assert.Equal(t, "cat", matches[0].Word.Word)
+	}
+
+	// All languages are searched when language is not given
+	result, err = h.SearchExamples(graphql.ResolveParams{
+		Args: map[string]interface{}{"query": "kot or dog"},
+	})
+	assert.NoError(t, err)
+	matches, ok = result.([]handlers.ExampleMatch)
+	assert.True(t, ok)
+	assert.Len(t, matches, 2)
+
+	_, err = h.SearchExamples(graphql.ResolveParams{
+		Args: map[string]interface{}{"query": "kot", "language": "xx"},
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/tdawidzi/dictionary_app/apperrors"
+	"github.com/tdawidzi/dictionary_app/models"
+	"github.com/tdawidzi/dictionary_app/repository"
+	"github.com/tdawidzi/dictionary_app/validation"
+
+	"github.com/graphql-go/graphql"
+)
+
+// GetSensesForWord fetches all senses of a word, ordered by sense number
+func (h *Handlers) GetSensesForWord(p graphql.ResolveParams) (interface{}, error) {
+	word, ok := sourceWord(p)
+	if !ok {
+		return nil, fmt.Errorf("invalid source for senses")
+	}
+
+	loaders, fromRequest := h.loadersFrom(p)
+	load := loaders.senses.Load(word.ID)
+	return batched(fromRequest, func() (interface{}, error) {
+		senses, err := load()
+		if err != nil {
+			return nil, err
+		}
+		return append([]models.Sense{}, senses...), nil
+	})
+}
+
+// GetTranslationsForSense fetches words linked by translations attached to given sense
+func (h *Handlers) GetTranslationsForSense(p graphql.ResolveParams) (interface{}, error) {
+	sense, ok := p.Source.(models.Sense)
+	if !ok {
+		return nil, fmt.Errorf("invalid source for translations")
+	}
+
+	loaders, fromRequest := h.loadersFrom(p)
+	load := loaders.senseTranslations.Load(sense.ID)
+	return batched(fromRequest, func() (interface{}, error) {
+		translations, err := load()
+		if err != nil {
+			return nil, err
+		}
+		translatedWords := make([]models.Word, 0, len(translations))
+		for _, t := range translations {
+			if t.SourceSenseID != nil && *t.SourceSenseID == sense.ID {
+				translatedWords = append(translatedWords, t.TargetWord)
+			} else {
+				translatedWords = append(translatedWords, t.SourceWord)
+			}
+		}
+		return translatedWords, nil
+	})
+}
+
+// GetExamplesForSense fetches examples attached to given sense
+func (h *Handlers) GetExamplesForSense(p graphql.ResolveParams) (interface{}, error) {
+	sense, ok := p.Source.(models.Sense)
+	if !ok {
+		return nil, fmt.Errorf("invalid source for examples")
+	}
+
+	loaders, fromRequest := h.loadersFrom(p)
+	load := loaders.senseExamples.Load(sense.ID)
+	return batched(fromRequest, func() (interface{}, error) {
+		examples, err := load()
+		if err != nil {
+			return nil, err
+		}
+		return append([]models.Example{}, examples...), nil
+	})
+}
+
+// AddSense adds new meaning to a word. Sense number (ordinal) is optional - by default sense is added as the last one.
+func (h *Handlers) AddSense(p graphql.ResolveParams) (interface{}, error) {
+	wordText, _ := p.Args["word"].(string)
+	language, _ := p.Args["language"].(string)
+	homograph, _ := p.Args["homograph"].(int)
+	definition, _ := p.Args["definition"].(string)
+	domain, _ := p.Args["domain"].(string)
+	ordinal, hasOrdinal := p.Args["ordinal"].(int)
+
+	var v validation.Validator
+	definition = v.Text("definition", definition, validation.MaxDefinitionLength)
+	domain = v.OptionalText("domain", domain, validation.MaxLabelLength)
+	if hasOrdinal && ordinal < 1 {
+		v.Fail("ordinal", "must be positive")
+	}
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+	dictionaryID, err := dictionaryArg(h.store, p)
+	if err != nil {
+		return nil, err
+	}
+
+	word, err := findWord(h.store, dictionaryID, wordText, language, homograph)
+	if err != nil {
+		return nil, lookupError("word", err)
+	}
+
+	sense := models.Sense{
+		WordID:     word.ID,
+		Ordinal:    ordinal,
+		Definition: definition,
+		Domain:     domain,
+	}
+	err = h.store.Transaction(func(tx repository.Store) error {
+		// Senses of the word are numbered by one request at a time
+		if err := lockWords(tx, word.ID); err != nil {
+			return err
+		}
+
+		if hasOrdinal {
+			// Check if sense number is free
+			if _, err := findSense(tx, word.ID, ordinal); err == nil {
+				return apperrors.Conflict("sense %d of word %q already exists", ordinal, word.Word)
+			} else if !errors.Is(err, repository.ErrNotFound) {
+				return fmt.Errorf("failed to query sense: %w", err)
+			}
+		} else {
+			// Next free sense number
+			last, err := tx.Senses().LastOrdinal(word.ID)
+			if err != nil {
+				return fmt.Errorf("failed to query senses: %w", err)
+			}
+			sense.Ordinal = last + 1
+		}
+
+		if err := tx.Senses().Create(&sense); err != nil {
+			return writeError("add sense", err)
+		}
+		_, err := record(tx, p, senseChange(models.OperationCreate, sense, nil, newSenseValues(sense)))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sense, nil
+}
+
+// UpdateSense modifies definition, domain or number of sense with given id
+func (h *Handlers) UpdateSense(p graphql.ResolveParams) (interface{}, error) {
+	id, ok := p.Args["id"].(int)
+	if !ok {
+		return nil, apperrors.Validation("invalid or missing ID")
+	}
+
+	var v validation.Validator
+	definition, hasDefinition := p.Args["definition"].(string)
+	if hasDefinition {
+		definition = v.Text("definition", definition, validation.MaxDefinitionLength)
+	}
+	domain, hasDomain := p.Args["domain"].(string)
+	if hasDomain {
+		domain = v.OptionalText("domain", domain, validation.MaxLabelLength)
+	}
+	ordinal, hasOrdinal := p.Args["ordinal"].(int)
+	if hasOrdinal && ordinal < 1 {
+		v.Fail("ordinal", "must be positive")
+	}
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+	expectedVersion, err := expectedVersionArg(p)
+	if err != nil {
+		return nil, err
+	}
+
+	var sense models.Sense
+	err = h.store.Transaction(func(tx repository.Store) error {
+		// Sense is locked, so concurrent updates of other fields are not lost
+		var err error
+		sense, err = tx.Senses().GetForUpdate(uint(id))
+		if err != nil {
+			return lookupError("sense", err)
+		}
+		if err := checkVersion("sense", sense.Version, expectedVersion); err != nil {
+			return err
+		}
+
+		// Update only given fields
+		before := newSenseValues(sense)
+		if hasDefinition {
+			sense.Definition = definition
+		}
+		if hasDomain {
+			sense.Domain = domain
+		}
+		if hasOrdinal {
+			sense.Ordinal = ordinal
+		}
+		if err := tx.Senses().Save(&sense); err != nil {
+			return writeError("update sense", err)
+		}
+		_, err = record(tx, p, senseChange(models.OperationUpdate, sense, before, newSenseValues(sense)))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sense, nil
+}
+
+// DeleteSense deletes sense with given id - translations and examples attached to it stay attached to the word
+func (h *Handlers) DeleteSense(p graphql.ResolveParams) (interface{}, error) {
+	id, ok := p.Args["id"].(int)
+	if !ok {
+		return nil, apperrors.Validation("invalid or missing ID")
+	}
+
+	err := h.store.Transaction(func(tx repository.Store) error {
+		sense, err := tx.Senses().GetForUpdate(uint(id))
+		if err != nil {
+			return lookupError("sense", err)
+		}
+		if err := tx.Senses().Delete(sense.ID); err != nil {
+			return fmt.Errorf("failed to delete sense: %w", err)
+		}
+		_, err = record(tx, p, senseChange(models.OperationDelete, sense, newSenseValues(sense), nil))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return true, nil
+}
+
+// findSense looks up sense of a word by its number
+func findSense(store repository.Store, wordID uint, ordinal int) (models.Sense, error) {
+	return store.Senses().Find(wordID, ordinal)
+}
+
+// senseIDArg resolves optional sense number argument of a word to sense id (nil if argument is not given)
+func senseIDArg(store repository.Store, p graphql.ResolveParams, arg string, wordID uint) (*uint, error) {
+	ordinal, ok := p.Args[arg].(int)
+	if !ok {
+		return nil, nil
+	}
+	sense, err := findSense(store, wordID, ordinal)
+	if err != nil {
+		return nil, lookupError(fmt.Sprintf("sense %d", ordinal), err)
+	}
+	return &sense.ID, nil
+}
@@ -0,0 +1,202 @@
+package handlers_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/stretchr/testify/assert"
+	"github.com/tdawidzi/dictionary_app/apperrors"
+	"github.com/tdawidzi/dictionary_app/handlers"
+	"github.com/tdawidzi/dictionary_app/models"
+)
+
+func addSense(t *testing.T, h *handlers.Handlers, word, language, definition string) models.Sense {
+	t.Helper()
+
+	params := graphql.ResolveParams{
+		Args: map[string]interface{}{
+			"word":       word,
+			"language":   language,
+			"definition": definition,
+		},
+	}
+	result, err := h.AddSense(params)
+	assert.NoError(t, err)
+
+	sense, ok := result.(models.Sense)
+	assert.True(t, ok)
+	return sense
+}
+
+func TestAddAndGetSenses(t *testing.T) {
+	store, h := setupTestStore(t)
+
+	zamek := createWord(t, store, models.Word{Word: "zamek", Language: "pl"})
+
+	castle := addSense(t, h, "zamek", "pl", "warowna budowla")
+	lock := addSense(t, h, "zamek", "pl", "urządzenie do zamykania drzwi")
+	assert.Equal(t, 1, castle.Ordinal)
+	assert.Equal(t, 2, lock.Ordinal)
+
+	// Senses are ordered by number
+	result, err := h.GetSensesForWord(graphql.ResolveParams{Source: zamek})
+	assert.NoError(t, err)
+	senses, ok := result.([]models.Sense)
+	assert.True(t, ok)
+	assert.Len(t, senses, 2)
+	assert.Equal(t, "warowna budowla", senses[0].Definition)
+
+	// Sense number has to be unique within word
+	params := graphql.ResolveParams{
+		Args: map[string]interface{}{
+			"word":       "zamek",
+			"language":   "pl",
+			"definition": "suwak",
+			"ordinal":    2,
+		},
+	}
+	_, err = h.AddSense(params)
+	assert.Equal(t, apperrors.CodeConflict, apperrors.CodeOf(err))
+}
+
+func TestTranslationsAndExamplesForSense(t *testing.T) {
+	store, h := setupTestStore(t)
+
+	createWord(t, store, models.Word{Word: "zamek", Language: "pl"})
+	createWord(t, store, models.Word{Word: "castle", Language: "en"})
+	createWord(t, store, models.Word{Word: "lock", Language: "en"})
+
+	castle := addSense(t, h, "zamek", "pl", "warowna budowla")
+	lock := addSense(t, h, "zamek", "pl", "urządzenie do zamykania drzwi")
+
+	for ordinal, target := range map[int]string{1: "castle", 2: "lock"} {
+		params := graphql.ResolveParams{
+			Args: map[string]interface{}{
+				"sourceWord":     "zamek",
+				"sourceLanguage": "pl",
+				"sourceSense":    ordinal,
+				"targetWord":     target,
+				"targetLanguage": "en",
+			},
+		}
+		_, err := h.AddTranslation(params)
+		assert.NoError(t, err)
+	}
+
+	params := graphql.ResolveParams{
+		Args: map[string]interface{}{
+			"word":     "zamek",
+			"language": "pl",
+			"sense":    2,
+			"example":  "Zamek w drzwiach się zaciął.",
+		},
+	}
+	result, err := h.AddExample(params)
+	assert.NoError(t, err)
+	example, ok := result.(models.Example)
+	assert.True(t, ok)
+	if assert.NotNil(t, example.SenseID) {
+		assert.Equal(t, lock.ID, *example.SenseID)
+	}
+
+	// Each sense has its own translation
+	result, err = h.GetTranslationsForSense(graphql.ResolveParams{Source: castle})
+	assert.NoError(t, err)
+	translations, ok := result.([]models.Word)
+	assert.True(t, ok)
+	assert.Len(t, translations, 1)
+	assert.Equal(t, "castle", translations[0].Word)
+
+	result, err = h.GetTranslationsForSense(graphql.ResolveParams{Source: lock})
+	assert.NoError(t, err)
+	translations, ok = result.([]models.Word)
+	assert.True(t, ok)
+	assert.Len(t, translations, 1)
+	assert.Equal(t, "lock", translations[0].Word)
+
+	// Example is attached only to second sense
+	result, err = h.GetExamplesForSense(graphql.ResolveParams{Source: castle})
+	assert.NoError(t, err)
+	examples, ok := result.([]models.Example)
+	assert.True(t, ok)
+	assert.Len(t, examples, 0)
+
+	result, err = h.GetExamplesForSense(graphql.ResolveParams{Source: lock})
+	assert.NoError(t, err)
+	examples, ok = result.([]models.Example)
+	assert.True(t, ok)
+	assert.Len(t, examples, 1)
+}
+
+func TestConcurrentAddSenseNumbering(t *testing.T) {
+	store, h := setupTestStore(t)
+
+	zamek := createWord(t, store, models.Word{Word: "zamek", Language: "pl"})
+
+	// Concurrently added senses get consecutive numbers
+	const concurrency = 10
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			addSense(t, h, "zamek", "pl", fmt.Sprintf("znaczenie %d", i))
+		}(i)
+	}
+	wg.Wait()
+
+	senses, err := store.Senses().ForWords([]uint{zamek.ID})
+	assert.NoError(t, err)
+	var ordinals []int
+	for _, sense := range senses {
+		ordinals = append(ordinals, sense.Ordinal)
+	}
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, ordinals)
+}
+
+func TestUpdateSense(t *testing.T) {
+	store, h := setupTestStore(t)
+
+	createWord(t, store, models.Word{Word: "zamek", Language: "pl"})
+	sense := addSense(t, h, "zamek", "pl", "budowla")
+
+	params := graphql.ResolveParams{
+		Args: map[string]interface{}{
+			"id":              int(sense.ID),
+			"definition":      "warowna budowla",
+			"domain":          "architektura",
+			"expectedVersion": sense.Version,
+		},
+	}
+	result, err := h.UpdateSense(params)
+	assert.NoError(t, err)
+
+	updated, ok := result.(models.Sense)
+	assert.True(t, ok)
+	assert.Equal(t, "warowna budowla", updated.Definition)
+	assert.Equal(t, "architektura", updated.Domain)
+	assert.Equal(t, 1, updated.Ordinal)
+	assert.Equal(t, sense.Version+1, updated.Version)
+}
+
+func TestDeleteSenseKeepsTranslation(t *testing.T) {
+	store, h := setupTestStore(t)
+
+	pl := createWord(t, store, models.Word{Word: "zamek", Language: "pl"})
+	en := createWord(t, store, models.Word{Word: "castle", Language: "en"})
+	sense := addSense(t, h, "zamek", "pl", "warowna budowla")
+	translation := createTranslation(t, store, models.Translation{SourceWordID: pl.ID, TargetWordID: en.ID, SourceSenseID: &sense.ID})
+
+	result, err := h.DeleteSense(graphql.ResolveParams{
+		Args: map[string]interface{}{"id": int(sense.ID)},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, true, result)
+
+	// Translation stays attached to the word
+	remaining, err := store.Translations().Get(translation.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, remaining.SourceSenseID)
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/tdawidzi/dictionary_app/apperrors"
+	"github.com/tdawidzi/dictionary_app/models"
+	"github.com/tdawidzi/dictionary_app/repository"
+	"github.com/tdawidzi/dictionary_app/textutil"
+
+	"github.com/graphql-go/graphql"
+)
+
+const (
+	defaultSuggestionLimit  = 5
+	maxSuggestionLimit      = 50
+	notFoundSuggestionLimit = 3
+	// Number of trigram candidates fetched per requested suggestion, re-ranked by edit distance afterwards
+	suggestionCandidateFactor = 4
+)
+
+// WordNotFoundError is returned by word lookups when no word matches given text.
+// Closest words are reported in GraphQL error extensions ("did you mean").
+type WordNotFoundError struct {
+	Word        string
+	Suggestions []models.Word
+	Err         error
+}
+
+func (e *WordNotFoundError) Error() string {
+	return fmt.Sprintf("word not found: %v", e.Err)
+}
+
+func (e *WordNotFoundError) Unwrap() error {
+	return e.Err
+}
+
+// Extensions implements gqlerrors.ExtendedError, error code is the same as of other not found errors
+func (e *WordNotFoundError) Extensions() map[string]interface{} {
+	suggestions := make([]map[string]interface{}, 0, len(e.Suggestions))
+	for _, w := range e.Suggestions {
+		suggestions = append(suggestions, map[string]interface{}{
+			"id":        w.ID,
+			"word":      w.Word,
+			"language":  w.Language,
+			"homograph": w.Homograph,
+		})
+	}
+	return map[string]interface{}{"code": string(apperrors.CodeNotFound), "suggestions": suggestions}
+}
+
+// GetSuggestions returns words of given dictionaries (the default one if not given) closest to given text, e.g. for misspelled word.
+// Words are compared by normalized text (case and diacritics are ignored).
+func (h *Handlers) GetSuggestions(p graphql.ResolveParams) (interface{}, error) {
+	text, ok := p.Args["word"].(string)
+	if !ok {
+		return nil, apperrors.Validation("missing word")
+	}
+	language, _ := p.Args["language"].(string)
+	limit, ok := p.Args["limit"].(int)
+	if !ok {
+		limit = defaultSuggestionLimit
+	}
+	if limit <= 0 || limit > maxSuggestionLimit {
+		return nil, apperrors.Validation("limit must be between 1 and %d", maxSuggestionLimit)
+	}
+	dictionaries, err := dictionariesArg(h.store, p)
+	if err != nil {
+		return nil, err
+	}
+
+	return suggestWords(h.store, text, language, dictionaries, limit)
+}
+
+// wordNotFound builds not found error for looked up text, with suggestions of similar words from the same dictionaries
+func wordNotFound(store repository.Store, text, language string, dictionaries []uint, err error) error {
+	// Missing suggestions should not hide the original error
+	suggestions, _ := suggestWords(store, text, language, dictionaries, notFoundSuggestionLimit)
+	return &WordNotFoundError{Word: text, Suggestions: suggestions, Err: err}
+}
+
+// suggestWords finds at most limit words closest to given text.
+// Candidates are preselected by store (e.g. by trigram similarity) and ordered by edit distance of search keys.
+func suggestWords(store repository.Store, text, language string, dictionaries []uint, limit int) ([]models.Word, error) {
+	key := textutil.SearchKey(text)
+	if key == "" {
+		return []models.Word{}, nil
+	}
+
+	candidates, err := store.Words().Similar(key, language, dictionaries, limit*suggestionCandidateFactor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch suggestions: %w", err)
+	}
+
+	return rankSuggestions(key, candidates, limit), nil
+}
+
+// rankSuggestions orders words by edit distance to search key, dropping the ones too different to be typos
+func rankSuggestions(key string, candidates []models.Word, limit int) []models.Word {
+	maxDistance := min(len([]rune(key))/3+1, 3)
+
+	distances := make(map[uint]int, len(candidates))
+	suggestions := make([]models.Word, 0, limit)
+	for _, c := range candidates {
+		distance := textutil.EditDistance(key, c.SearchKey)
+		if distance > maxDistance {
+			continue
+		}
+		distances[c.ID] = distance
+		suggestions = append(suggestions, c)
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return distances[suggestions[i].ID] < distances[suggestions[j].ID]
+	})
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions
+}
@@ -0,0 +1,85 @@
+package handlers_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/stretchr/testify/assert"
+	"github.com/tdawidzi/dictionary_app/handlers"
+	"github.com/tdawidzi/dictionary_app/models"
+	"github.com/tdawidzi/dictionary_app/repository"
+)
+
+// suggestionHandlers adds suggestionWords to store
+func suggestionHandlers(t *testing.T, store repository.Store) *handlers.Handlers {
+	for _, word := range suggestionWords {
+		createWord(t, store, word)
+	}
+	return handlers.New(store)
+}
+
+var suggestionWords = []models.Word{
+	{Word: "kot", Language: "pl"},
+	{Word: "kąt", Language: "pl"},
+	{Word: "kotek", Language: "pl"},
+	{Word: "pies", Language: "pl"},
+	{Word: "cat", Language: "en"},
+}
+
+func TestGetSuggestionsWithEachStore(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store repository.Store) {
+		testGetSuggestions(t, suggestionHandlers(t, store))
+	})
+}
+
+// testGetSuggestions checks suggestions for suggestionWords (shared with PostgreSQL trigram search test)
+func testGetSuggestions(t *testing.T, h *handlers.Handlers) {
+	result, err := h.GetSuggestions(graphql.ResolveParams{
+		Args: map[string]interface{}{"word": "kto", "language": "pl"},
+	})
+	assert.NoError(t, err)
+
+	words, ok := result.([]models.Word)
+	assert.True(t, ok)
+	if assert.NotEmpty(t, words) {
+		// Closest word goes first, unrelated words are not suggested
+		assert.Equal(t, "kot", words[0].Word)
+	}
+	for _, w := range words {
+		assert.NotEqual(t, "pies", w.Word)
+	}
+
+	// Limit
+	result, err = h.GetSuggestions(graphql.ResolveParams{
+		Args: map[string]interface{}{"word": "kot", "language": "pl", "limit": 1},
+	})
+	assert.NoError(t, err)
+	words, ok = result.([]models.Word)
+	assert.True(t, ok)
+	assert.Len(t, words, 1)
+
+	_, err = h.GetSuggestions(graphql.ResolveParams{
+		Args: map[string]interface{}{"word": "kot", "limit": 0},
+	})
+	assert.Error(t, err)
+}
+
+func TestGetWordNotFoundSuggestionsWithEachStore(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store repository.Store) {
+		h := suggestionHandlers(t, store)
+
+		_, err := h.GetWordByText(graphql.ResolveParams{
+			Args: map[string]interface{}{"word": "koty", "language": "pl"},
+		})
+
+		var notFound *handlers.WordNotFoundError
+		if assert.True(t, errors.As(err, &notFound)) {
+			suggestions, ok := notFound.Extensions()["suggestions"].([]map[string]interface{})
+			assert.True(t, ok)
+			if assert.NotEmpty(t, suggestions) {
+				assert.Equal(t, "kot", suggestions[0]["word"])
+			}
+		}
+	})
+}
@@ -4,162 +4,317 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/tdawidzi/dictionary_app/apperrors"
 	"github.com/tdawidzi/dictionary_app/models"
-	"github.com/tdawidzi/dictionary_app/utils"
+	"github.com/tdawidzi/dictionary_app/repository"
 
 	"github.com/graphql-go/graphql"
-	"gorm.io/gorm"
 )
 
-func GetTranslationsForWord(p graphql.ResolveParams) (interface{}, error) {
-	word, ok := p.Source.(models.Word)
+// GetTranslationsForWord fetches all words linked with given word by translation.
+// Translations are symmetric - word can be either source or target of translation.
+// Optional "language" argument limits result to translations into given language.
+// Translations of all words in request are batch loaded (see Loaders).
+func (h *Handlers) GetTranslationsForWord(p graphql.ResolveParams) (interface{}, error) {
+	word, ok := sourceWord(p)
 	if !ok {
 		return nil, fmt.Errorf("invalid source for translations")
 	}
+	language, _ := p.Args["language"].(string)
 
-	var translations []models.Translation
-	var err error
-
-	if word.Language == "pl" {
-		err = utils.DB.Preload("WordEn").
-			Where("word_id_pl = ?", word.ID).
-			Find(&translations).Error
-	} else if word.Language == "en" {
-		err = utils.DB.Preload("WordPl").
-			Where("word_id_en = ?", word.ID).
-			Find(&translations).Error
-	} else {
-		return nil, fmt.Errorf("unsupported language: %s", word.Language)
-	}
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch translations: %w", err)
-	}
-
-	translatedWords := make([]models.Word, 0, len(translations))
-	for _, t := range translations {
-		if word.Language == "pl" {
-			translatedWords = append(translatedWords, t.WordEn)
-		} else {
-			translatedWords = append(translatedWords, t.WordPl)
+	loaders, fromRequest := h.loadersFrom(p)
+	load := loaders.wordTranslations.Load(word.ID)
+	return batched(fromRequest, func() (interface{}, error) {
+		translations, err := load()
+		if err != nil {
+			return nil, err
 		}
-	}
-
-	return translatedWords, nil
+		translatedWords := make([]models.Word, 0, len(translations))
+		for _, t := range translations {
+			translated := t.TargetWord
+			if t.TargetWordID == word.ID {
+				translated = t.SourceWord
+			}
+			if language != "" && translated.Language != language {
+				continue
+			}
+			translatedWords = append(translatedWords, translated)
+		}
+		return translatedWords, nil
+	})
 }
 
 // Adds translation to db
-func AddTranslation(p graphql.ResolveParams) (interface{}, error) {
-	wordPl, _ := p.Args["wordPl"].(string)
-	wordEn, _ := p.Args["wordEn"].(string)
+func (h *Handlers) AddTranslation(p graphql.ResolveParams) (interface{}, error) {
+	sourceText, _ := p.Args["sourceWord"].(string)
+	sourceLanguage, _ := p.Args["sourceLanguage"].(string)
+	sourceHomograph, _ := p.Args["sourceHomograph"].(int)
+	targetText, _ := p.Args["targetWord"].(string)
+	targetLanguage, _ := p.Args["targetLanguage"].(string)
+	targetHomograph, _ := p.Args["targetHomograph"].(int)
 
-	var wordPL models.Word
-	var wordEN models.Word
-
-	// Check if words exists
-	if err := utils.DB.Where("word = ? AND language = 'pl'", wordPl).First(&wordPL).Error; err != nil {
-		return nil, fmt.Errorf("polish word not found: %w", err)
+	if sourceLanguage == targetLanguage {
+		return nil, apperrors.Validation("source and target language must differ")
 	}
-	if err := utils.DB.Where("word = ? AND language = 'en'", wordEn).First(&wordEN).Error; err != nil {
-		return nil, fmt.Errorf("english word not found: %w", err)
+	dictionaryID, err := dictionaryArg(h.store, p)
+	if err != nil {
+		return nil, err
 	}
 
-	// Check if translation exists
-	var existing models.Translation
-	if err := utils.DB.Where("word_id_pl = ? AND word_id_en = ?", wordPL.ID, wordEN.ID).First(&existing).Error; err == nil {
-		return existing, nil
-	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, fmt.Errorf("failed to query translation: %w", err)
+	// Check if words exists (translations link words of the same dictionary)
+	source, err := findWord(h.store, dictionaryID, sourceText, sourceLanguage, sourceHomograph)
+	if err != nil {
+		return nil, lookupError("source word", err)
+	}
+	target, err := findWord(h.store, dictionaryID, targetText, targetLanguage, targetHomograph)
+	if err != nil {
+		return nil, lookupError("target word", err)
 	}
 
-	// Create new translation
-	translation := models.Translation{
-		WordIDPl: wordPL.ID,
-		WordIDEn: wordEN.ID,
+	// Optional senses of both words
+	sourceSenseID, err := senseIDArg(h.store, p, "sourceSense", source.ID)
+	if err != nil {
+		return nil, err
+	}
+	targetSenseID, err := senseIDArg(h.store, p, "targetSense", target.ID)
+	if err != nil {
+		return nil, err
 	}
-	if err := utils.DB.Create(&translation).Error; err != nil {
-		return nil, fmt.Errorf("failed to create translation: %w", err)
+
+	var translation models.Translation
+	err = h.store.Transaction(func(tx repository.Store) error {
+		// Concurrent requests for the same words (in any direction) wait for each other
+		if err := lockWords(tx, source.ID, target.ID); err != nil {
+			return err
+		}
+
+		// Check if translation exists (in any direction)
+		existing, err := tx.Translations().Find(source.ID, target.ID)
+		if err == nil {
+			// Attach existing translation to given senses
+			translation = existing
+			if !setTranslationSenses(&translation, source.ID, sourceSenseID, targetSenseID) {
+				return nil
+			}
+			if err := saveTranslation(tx, &translation); err != nil {
+				return err
+			}
+			_, err := record(tx, p, translationChange(models.OperationUpdate, translation,
+				newTranslationValues(existing), newTranslationValues(translation)))
+			return err
+		} else if !errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("failed to query translation: %w", err)
+		}
+
+		// Create new translation
+		translation = models.Translation{
+			SourceWordID:  source.ID,
+			TargetWordID:  target.ID,
+			SourceSenseID: sourceSenseID,
+			TargetSenseID: targetSenseID,
+		}
+		if err := tx.Translations().Create(&translation); err != nil {
+			return writeError("create translation", err)
+		}
+		_, err = record(tx, p, translationChange(models.OperationCreate, translation, nil, newTranslationValues(translation)))
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 	return translation, nil
 }
 
 // Modify translation existing in db
-func UpdateTranslation(p graphql.ResolveParams) (interface{}, error) {
-	oldWordPl, _ := p.Args["oldWordPl"].(string)
-	oldWordEn, _ := p.Args["oldWordEn"].(string)
-	newWordPl, _ := p.Args["newWordPl"].(string)
-	newWordEn, _ := p.Args["newWordEn"].(string)
+func (h *Handlers) UpdateTranslation(p graphql.ResolveParams) (interface{}, error) {
+	sourceLanguage, _ := p.Args["sourceLanguage"].(string)
+	targetLanguage, _ := p.Args["targetLanguage"].(string)
+	oldSourceText, _ := p.Args["oldSourceWord"].(string)
+	oldSourceHomograph, _ := p.Args["oldSourceHomograph"].(int)
+	oldTargetText, _ := p.Args["oldTargetWord"].(string)
+	oldTargetHomograph, _ := p.Args["oldTargetHomograph"].(int)
+	newSourceText, _ := p.Args["newSourceWord"].(string)
+	newSourceHomograph, _ := p.Args["newSourceHomograph"].(int)
+	newTargetText, _ := p.Args["newTargetWord"].(string)
+	newTargetHomograph, _ := p.Args["newTargetHomograph"].(int)
 
-	var oldPL, oldEN, newPL, newEN models.Word
+	if sourceLanguage == targetLanguage {
+		return nil, apperrors.Validation("source and target language must differ")
+	}
+	expectedVersion, err := expectedVersionArg(p)
+	if err != nil {
+		return nil, err
+	}
+	dictionaryID, err := dictionaryArg(h.store, p)
+	if err != nil {
+		return nil, err
+	}
 
 	// Check if all given words exists in db
-	if err := utils.DB.Where("word = ? AND language = 'pl'", oldWordPl).First(&oldPL).Error; err != nil {
-		return nil, fmt.Errorf("old Polish word not found: %w", err)
+	oldSource, err := findWord(h.store, dictionaryID, oldSourceText, sourceLanguage, oldSourceHomograph)
+	if err != nil {
+		return nil, lookupError("old source word", err)
 	}
 
-	if err := utils.DB.Where("word = ? AND language = 'en'", oldWordEn).First(&oldEN).Error; err != nil {
-		return nil, fmt.Errorf("old English word not found: %w", err)
+	oldTarget, err := findWord(h.store, dictionaryID, oldTargetText, targetLanguage, oldTargetHomograph)
+	if err != nil {
+		return nil, lookupError("old target word", err)
 	}
 
-	if err := utils.DB.Where("word = ? AND language = 'pl'", newWordPl).First(&newPL).Error; err != nil {
-		return nil, fmt.Errorf("new Polish word not found: %w", err)
+	newSource, err := findWord(h.store, dictionaryID, newSourceText, sourceLanguage, newSourceHomograph)
+	if err != nil {
+		return nil, lookupError("new source word", err)
 	}
 
-	if err := utils.DB.Where("word = ? AND language = 'en'", newWordEn).First(&newEN).Error; err != nil {
-		return nil, fmt.Errorf("new English word not found: %w", err)
+	newTarget, err := findWord(h.store, dictionaryID, newTargetText, targetLanguage, newTargetHomograph)
+	if err != nil {
+		return nil, lookupError("new target word", err)
 	}
 
-	// Check if old translation exists
-	var translation models.Translation
-	if err := utils.DB.Where("word_id_pl = ? AND word_id_en = ?", oldPL.ID, oldEN.ID).First(&translation).Error; err != nil {
-		return nil, fmt.Errorf("translation not found: %w", err)
+	// Optional senses of new words
+	sourceSenseID, err := senseIDArg(h.store, p, "sourceSense", newSource.ID)
+	if err != nil {
+		return nil, err
 	}
-
-	// Check if new translation does not exist
-	var existing models.Translation
-	if err := utils.DB.Where("word_id_pl = ? AND word_id_en = ?", newPL.ID, newEN.ID).First(&existing).Error; err == nil {
-		return existing, nil
-	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, fmt.Errorf("failed to query translation: %w", err)
+	targetSenseID, err := senseIDArg(h.store, p, "targetSense", newTarget.ID)
+	if err != nil {
+		return nil, err
 	}
 
-	// Modify and save translation
-	translation.WordIDPl = newPL.ID
-	translation.WordIDEn = newEN.ID
+	var translation models.Translation
+	err = h.store.Transaction(func(tx repository.Store) error {
+		// Translations of old and new words cannot be changed concurrently
+		if err := lockWords(tx, oldSource.ID, oldTarget.ID, newSource.ID, newTarget.ID); err != nil {
+			return err
+		}
+
+		// Check if old translation exists
+		var err error
+		translation, err = tx.Translations().Find(oldSource.ID, oldTarget.ID)
+		if err != nil {
+			return lookupError("translation", err)
+		}
+		if err := checkVersion("translation", translation.Version, expectedVersion); err != nil {
+			return err
+		}
+
+		// Check if new translation does not exist (it is the same one, when only senses are changed)
+		if existing, err := tx.Translations().Find(newSource.ID, newTarget.ID); err == nil {
+			if existing.ID != translation.ID {
+				translation = existing
+				return nil
+			}
+		} else if !errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("failed to query translation: %w", err)
+		}
 
-	if err := utils.DB.Model(&models.Translation{}).
-		Where("id = ?", translation.ID).
-		Updates(models.Translation{
-			WordIDPl: newPL.ID,
-			WordIDEn: newEN.ID,
-		}).Error; err != nil {
-		return nil, fmt.Errorf("failed to update translation: %w", err)
+		before := newTranslationValues(translation)
+		moveTranslation(&translation, oldSource.ID, oldTarget.ID, newSource.ID, newTarget.ID)
+		setTranslationSenses(&translation, newSource.ID, sourceSenseID, targetSenseID)
+		if err := saveTranslation(tx, &translation); err != nil {
+			return err
+		}
+		_, err = record(tx, p, translationChange(models.OperationUpdate, translation, before, newTranslationValues(translation)))
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return translation, nil
 }
 
-// Delete existing translation from db
-func DeleteTranslation(p graphql.ResolveParams) (interface{}, error) {
-	wordPl, _ := p.Args["wordPl"].(string)
-	wordEn, _ := p.Args["wordEn"].(string)
+// moveTranslation changes words of translation from old to new ones
+func moveTranslation(translation *models.Translation, oldSourceID, oldTargetID, newSourceID, newTargetID uint) {
+	// Senses are kept only for words which did not change (translation can be stored in reversed direction)
+	oldSourceSenseID, oldTargetSenseID := translation.SourceSenseID, translation.TargetSenseID
+	if translation.SourceWordID != oldSourceID {
+		oldSourceSenseID, oldTargetSenseID = oldTargetSenseID, oldSourceSenseID
+	}
+	translation.SourceSenseID, translation.TargetSenseID = nil, nil
+	if newSourceID == oldSourceID {
+		translation.SourceSenseID = oldSourceSenseID
+	}
+	if newTargetID == oldTargetID {
+		translation.TargetSenseID = oldTargetSenseID
+	}
+
+	translation.SourceWordID = newSourceID
+	translation.TargetWordID = newTargetID
+}
 
-	var wordPL models.Word
-	var wordEN models.Word
+// Delete existing translation from db
+func (h *Handlers) DeleteTranslation(p graphql.ResolveParams) (interface{}, error) {
+	sourceText, _ := p.Args["sourceWord"].(string)
+	sourceLanguage, _ := p.Args["sourceLanguage"].(string)
+	sourceHomograph, _ := p.Args["sourceHomograph"].(int)
+	targetText, _ := p.Args["targetWord"].(string)
+	targetLanguage, _ := p.Args["targetLanguage"].(string)
+	targetHomograph, _ := p.Args["targetHomograph"].(int)
+	dictionaryID, err := dictionaryArg(h.store, p)
+	if err != nil {
+		return nil, err
+	}
 
 	// Check for words in db
-	if err := utils.DB.Where("word = ? AND language = 'pl'", wordPl).First(&wordPL).Error; err != nil {
-		return nil, fmt.Errorf("polish word not found: %w", err)
+	source, err := findWord(h.store, dictionaryID, sourceText, sourceLanguage, sourceHomograph)
+	if err != nil {
+		return nil, lookupError("source word", err)
 	}
 
-	if err := utils.DB.Where("word = ? AND language = 'en'", wordEn).First(&wordEN).Error; err != nil {
-		return nil, fmt.Errorf("english word not found: %w", err)
+	target, err := findWord(h.store, dictionaryID, targetText, targetLanguage, targetHomograph)
+	if err != nil {
+		return nil, lookupError("target word", err)
 	}
 
-	// Delete translation
-	if err := utils.DB.Where("word_id_pl = ? AND word_id_en = ?", wordPL.ID, wordEN.ID).Delete(&models.Translation{}).Error; err != nil {
-		return nil, fmt.Errorf("failed to delete translation: %w", err)
+	// Delete translation (in any direction)
+	err = h.store.Transaction(func(tx repository.Store) error {
+		if err := lockWords(tx, source.ID, target.ID); err != nil {
+			return err
+		}
+		translation, err := tx.Translations().Find(source.ID, target.ID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to query translation: %w", err)
+		}
+		if err := tx.Translations().DeleteBetween(source.ID, target.ID); err != nil {
+			return fmt.Errorf("failed to delete translation: %w", err)
+		}
+		_, err = record(tx, p, translationChange(models.OperationDelete, translation, newTranslationValues(translation), nil))
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return true, nil
 }
+
+// setTranslationSenses attaches translation to given senses (nil leaves sense unchanged).
+// Senses are given in direction of sourceWordID, which can be reversed to stored direction of translation.
+// Returns true if translation was modified.
+func setTranslationSenses(t *models.Translation, sourceWordID uint, sourceSenseID, targetSenseID *uint) bool {
+	if t.SourceWordID != sourceWordID {
+		sourceSenseID, targetSenseID = targetSenseID, sourceSenseID
+	}
+
+	changed := false
+	if sourceSenseID != nil {
+		t.SourceSenseID = sourceSenseID
+		changed = true
+	}
+	if targetSenseID != nil {
+		t.TargetSenseID = targetSenseID
+		changed = true
+	}
+	return changed
+}
+
+// saveTranslation writes words and senses of translation to db
+func saveTranslation(tx repository.Store, t *models.Translation) error {
+	if err := tx.Translations().Save(t); err != nil {
+		return writeError("update translation", err)
+	}
+	return nil
+}
@@ -6,124 +6,227 @@ import (
 
 	"github.com/graphql-go/graphql"
 	"github.com/stretchr/testify/assert"
-	"github.com/tdawidzi/dictionary_app/handlers"
+	"github.com/tdawidzi/dictionary_app/apperrors"
 	"github.com/tdawidzi/dictionary_app/models"
-	"github.com/tdawidzi/dictionary_app/testresources"
-	"github.com/tdawidzi/dictionary_app/utils"
 )
 
-func setupTranslationTestDB(t *testing.T) {
-	utils.DB = testresources.NewSingleTestConnection(t)
-	err := utils.DB.AutoMigrate(&models.Word{}, &models.Translation{})
-	if err != nil {
-		t.Fatalf("failed to migrate: %v", err)
-	}
-}
-
 func TestAddAndGetTranslations(t *testing.T) {
-	setupTranslationTestDB(t)
+	store, h := setupTestStore(t)
 
-	pl := models.Word{Word: "kot", Language: "pl"}
-	en := models.Word{Word: "cat", Language: "en"}
-	utils.DB.Create(&pl)
-	utils.DB.Create(&en)
+	pl := createWord(t, store, models.Word{Word: "kot", Language: "pl"})
+	en := createWord(t, store, models.Word{Word: "cat", Language: "en"})
 
 	params := graphql.ResolveParams{
 		Args: map[string]interface{}{
-			"wordPl": "kot",
-			"wordEn": "cat",
+			"sourceWord":     "kot",
+			"sourceLanguage": "pl",
+			"targetWord":     "cat",
+			"targetLanguage": "en",
 		},
 	}
 
 	// Add translation
-	result, err := handlers.AddTranslation(params)
+	result, err := h.AddTranslation(params)
 	assert.NoError(t, err)
 	translation, ok := result.(models.Translation)
 	assert.True(t, ok)
-	assert.Equal(t, pl.ID, translation.WordIDPl)
-	assert.Equal(t, en.ID, translation.WordIDEn)
+	assert.Equal(t, pl.ID, translation.SourceWordID)
+	assert.Equal(t, en.ID, translation.TargetWordID)
 
 	// Get translations for "kot"
 	sourceParams := graphql.ResolveParams{Source: pl}
-	result, err = handlers.GetTranslationsForWord(sourceParams)
+	result, err = h.GetTranslationsForWord(sourceParams)
 	assert.NoError(t, err)
 	translations, ok := result.([]models.Word)
 	assert.True(t, ok)
 	assert.Len(t, translations, 1)
 	assert.Equal(t, "cat", translations[0].Word)
+
+	// Translations work both ways - get translations for "cat"
+	sourceParams = graphql.ResolveParams{Source: en}
+	result, err = h.GetTranslationsForWord(sourceParams)
+	assert.NoError(t, err)
+	translations, ok = result.([]models.Word)
+	assert.True(t, ok)
+	assert.Len(t, translations, 1)
+	assert.Equal(t, "kot", translations[0].Word)
 }
 
-func TestUpdateTranslation(t *testing.T) {
-	setupTranslationTestDB(t)
+func TestAddTranslationBetweenRegisteredLanguages(t *testing.T) {
+	store, h := setupTestStore(t)
+	assert.NoError(t, store.Languages().Create(&models.Language{Code: "de", Name: "German", Script: "Latn", Direction: "ltr"}))
+	assert.NoError(t, store.Languages().Create(&models.Language{Code: "uk", Name: "Ukrainian", Script: "Cyrl", Direction: "ltr"}))
+
+	de := createWord(t, store, models.Word{Word: "Katze", Language: "de"})
+	uk := createWord(t, store, models.Word{Word: "кіт", Language: "uk"})
+	pl := createWord(t, store, models.Word{Word: "kot", Language: "pl"})
+
+	for _, target := range []models.Word{uk, pl} {
+		params := graphql.ResolveParams{
+			Args: map[string]interface{}{
+				"sourceWord":     de.Word,
+				"sourceLanguage": de.Language,
+				"targetWord":     target.Word,
+				"targetLanguage": target.Language,
+			},
+		}
+		_, err := h.AddTranslation(params)
+		assert.NoError(t, err)
+	}
+
+	// Translations can be limited to one language
+	sourceParams := graphql.ResolveParams{
+		Source: de,
+		Args:   map[string]interface{}{"language": "uk"},
+	}
+	result, err := h.GetTranslationsForWord(sourceParams)
+	assert.NoError(t, err)
+	translations, ok := result.([]models.Word)
+	assert.True(t, ok)
+	assert.Len(t, translations, 1)
+	assert.Equal(t, "кіт", translations[0].Word)
+}
+
+func TestAddTranslationSameLanguage(t *testing.T) {
+	store, h := setupTestStore(t)
 
-	oldPl := models.Word{Word: "pies", Language: "pl"}
-	oldEn := models.Word{Word: "dog", Language: "en"}
-	newPl := models.Word{Word: "kundel", Language: "pl"}
-	newEn := models.Word{Word: "mongrel", Language: "en"}
+	createWord(t, store, models.Word{Word: "kot", Language: "pl"})
+	createWord(t, store, models.Word{Word: "kocur", Language: "pl"})
+
+	params := graphql.ResolveParams{
+		Args: map[string]interface{}{
+			"sourceWord":     "kot",
+			"sourceLanguage": "pl",
+			"targetWord":     "kocur",
+			"targetLanguage": "pl",
+		},
+	}
+	_, err := h.AddTranslation(params)
+	assert.Equal(t, apperrors.CodeValidation, apperrors.CodeOf(err))
+}
+
+func TestAddTranslationMissingWord(t *testing.T) {
+	store, h := setupTestStore(t)
+
+	createWord(t, store, models.Word{Word: "kot", Language: "pl"})
+
+	params := graphql.ResolveParams{
+		Args: map[string]interface{}{
+			"sourceWord":     "kot",
+			"sourceLanguage": "pl",
+			"targetWord":     "cat",
+			"targetLanguage": "en",
+		},
+	}
+	_, err := h.AddTranslation(params)
+	assert.Equal(t, apperrors.CodeNotFound, apperrors.CodeOf(err))
+	assert.EqualError(t, err, "target word not found: record not found")
+}
+
+func TestUpdateTranslation(t *testing.T) {
+	store, h := setupTestStore(t)
 
-	utils.DB.Create(&oldPl)
-	utils.DB.Create(&oldEn)
-	utils.DB.Create(&newPl)
-	utils.DB.Create(&newEn)
+	oldPl := createWord(t, store, models.Word{Word: "pies", Language: "pl"})
+	oldEn := createWord(t, store, models.Word{Word: "dog", Language: "en"})
+	newPl := createWord(t, store, models.Word{Word: "kundel", Language: "pl"})
+	newEn := createWord(t, store, models.Word{Word: "mongrel", Language: "en"})
 
 	// Create old translation
-	utils.DB.Create(&models.Translation{WordIDPl: oldPl.ID, WordIDEn: oldEn.ID})
+	createTranslation(t, store, models.Translation{SourceWordID: oldPl.ID, TargetWordID: oldEn.ID})
 
 	params := graphql.ResolveParams{
 		Args: map[string]interface{}{
-			"oldWordPl": "pies",
-			"oldWordEn": "dog",
-			"newWordPl": "kundel",
-			"newWordEn": "mongrel",
+			"sourceLanguage":  "pl",
+			"targetLanguage":  "en",
+			"oldSourceWord":   "pies",
+			"oldTargetWord":   "dog",
+			"newSourceWord":   "kundel",
+			"newTargetWord":   "mongrel",
+			"expectedVersion": 1,
 		},
 	}
 
-	result, err := handlers.UpdateTranslation(params)
+	result, err := h.UpdateTranslation(params)
 	assert.NoError(t, err)
 	updated, ok := result.(models.Translation)
 	assert.True(t, ok)
-	assert.Equal(t, newPl.ID, updated.WordIDPl)
-	assert.Equal(t, newEn.ID, updated.WordIDEn)
+	assert.Equal(t, newPl.ID, updated.SourceWordID)
+	assert.Equal(t, newEn.ID, updated.TargetWordID)
 }
 
-func TestDeleteTranslation(t *testing.T) {
-	setupTranslationTestDB(t)
+func TestUpdateTranslationSenses(t *testing.T) {
+	store, h := setupTestStore(t)
 
-	pl := models.Word{Word: "mysz", Language: "pl"}
-	en := models.Word{Word: "mouse", Language: "en"}
+	pl := createWord(t, store, models.Word{Word: "zamek", Language: "pl"})
+	en := createWord(t, store, models.Word{Word: "lock", Language: "en"})
+	castle := models.Sense{WordID: pl.ID, Ordinal: 1, Definition: "warowna budowla"}
+	lock := models.Sense{WordID: pl.ID, Ordinal: 2, Definition: "urządzenie do zamykania drzwi"}
+	assert.NoError(t, store.Senses().Create(&castle))
+	assert.NoError(t, store.Senses().Create(&lock))
+	translation := createTranslation(t, store, models.Translation{SourceWordID: pl.ID, TargetWordID: en.ID, SourceSenseID: &castle.ID})
 
-	utils.DB.Create(&pl)
-	utils.DB.Create(&en)
-	utils.DB.Create(&models.Translation{WordIDPl: pl.ID, WordIDEn: en.ID})
+	// Words stay the same, only sense is changed
+	result, err := h.UpdateTranslation(graphql.ResolveParams{
+		Args: map[string]interface{}{
+			"sourceLanguage":  "pl",
+			"targetLanguage":  "en",
+			"oldSourceWord":   "zamek",
+			"oldTargetWord":   "lock",
+			"newSourceWord":   "zamek",
+			"newTargetWord":   "lock",
+			"sourceSense":     2,
+			"expectedVersion": 1,
+		},
+	})
+	assert.NoError(t, err)
+	updated, ok := result.(models.Translation)
+	assert.True(t, ok)
+	assert.Equal(t, translation.ID, updated.ID)
+	assert.Equal(t, 2, updated.Version)
+
+	saved, err := store.Translations().Get(translation.ID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, saved.SourceSenseID) {
+		assert.Equal(t, lock.ID, *saved.SourceSenseID)
+	}
+	entries, err := store.Audit().ForWord(pl.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, models.OperationUpdate, entries[0].Operation)
+	}
+}
+
+func TestDeleteTranslation(t *testing.T) {
+	store, h := setupTestStore(t)
+
+	pl := createWord(t, store, models.Word{Word: "mysz", Language: "pl"})
+	en := createWord(t, store, models.Word{Word: "mouse", Language: "en"})
+	createTranslation(t, store, models.Translation{SourceWordID: pl.ID, TargetWordID: en.ID})
 
 	params := graphql.ResolveParams{
 		Args: map[string]interface{}{
-			"wordPl": "mysz",
-			"wordEn": "mouse",
+			"sourceWord":     "mouse",
+			"sourceLanguage": "en",
+			"targetWord":     "mysz",
+			"targetLanguage": "pl",
 		},
 	}
 
-	result, err := handlers.DeleteTranslation(params)
+	result, err := h.DeleteTranslation(params)
 	assert.NoError(t, err)
 	deleted, ok := result.(bool)
 	assert.True(t, ok)
 	assert.True(t, deleted)
 
 	// Check if translation is gone
-	var count int64
-	utils.DB.Model(&models.Translation{}).
-		Where("word_id_pl = ? AND word_id_en = ?", pl.ID, en.ID).
-		Count(&count)
-	assert.Equal(t, int64(0), count)
+	assert.Equal(t, 0, countTranslations(t, store, pl.ID))
 }
 
 func TestConcurrentAddTranslation_RaceCondition(t *testing.T) {
-	setupTranslationTestDB(t)
+	store, h := setupTestStore(t)
 
-	pl := models.Word{Word: "lew", Language: "pl"}
-	en := models.Word{Word: "lion", Language: "en"}
-	utils.DB.Create(&pl)
-	utils.DB.Create(&en)
+	pl := createWord(t, store, models.Word{Word: "lew", Language: "pl"})
+	createWord(t, store, models.Word{Word: "lion", Language: "en"})
 
 	var wg sync.WaitGroup
 	concurrency := 10
@@ -135,35 +238,75 @@ func TestConcurrentAddTranslation_RaceCondition(t *testing.T) {
 
 			params := graphql.ResolveParams{
 				Args: map[string]interface{}{
-					"wordPl": "lew",
-					"wordEn": "lion",
+					"sourceWord":     "lew",
+					"sourceLanguage": "pl",
+					"targetWord":     "lion",
+					"targetLanguage": "en",
 				},
 			}
-			_, _ = handlers.AddTranslation(params)
+			_, _ = h.AddTranslation(params)
 		}()
 	}
 
 	wg.Wait()
 
 	// Only one record should exist
-	var count int64
-	err := utils.DB.Model(&models.Translation{}).
-		Where("word_id_pl = ? AND word_id_en = ?", pl.ID, en.ID).
-		Count(&count).Error
+	assert.Equal(t, 1, countTranslations(t, store, pl.ID), "Only one translation should exist after concurrent insertions")
+}
 
-	assert.NoError(t, err)
-	assert.Equal(t, int64(1), count, "Only one translation should exist after concurrent insertions")
+func TestConcurrentAddTranslationIsIdempotent(t *testing.T) {
+	store, h := setupTestStore(t)
+
+	pl := createWord(t, store, models.Word{Word: "tygrys", Language: "pl"})
+	createWord(t, store, models.Word{Word: "tiger", Language: "en"})
+
+	// Half of requests adds translation in reversed direction
+	const concurrency = 20
+	ids := make([]uint, concurrency)
+	errs := make([]error, concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			args := map[string]interface{}{
+				"sourceWord":     "tygrys",
+				"sourceLanguage": "pl",
+				"targetWord":     "tiger",
+				"targetLanguage": "en",
+			}
+			if i%2 == 1 {
+				args = map[string]interface{}{
+					"sourceWord":     "tiger",
+					"sourceLanguage": "en",
+					"targetWord":     "tygrys",
+					"targetLanguage": "pl",
+				}
+			}
+			result, err := h.AddTranslation(graphql.ResolveParams{Args: args})
+			errs[i] = err
+			if translation, ok := result.(models.Translation); ok {
+				ids[i] = translation.ID
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// Every request succeeds and returns the same translation
+	for i := 0; i < concurrency; i++ {
+		assert.NoError(t, errs[i])
+		assert.Equal(t, ids[0], ids[i])
+	}
+	assert.Equal(t, 1, countTranslations(t, store, pl.ID))
 }
 
 func TestConcurrentDeleteTranslation_RaceCondition(t *testing.T) {
-	setupTranslationTestDB(t)
-
-	pl := models.Word{Word: "wilk", Language: "pl"}
-	en := models.Word{Word: "wolf", Language: "en"}
+	store, h := setupTestStore(t)
 
-	utils.DB.Create(&pl)
-	utils.DB.Create(&en)
-	utils.DB.Create(&models.Translation{WordIDPl: pl.ID, WordIDEn: en.ID})
+	pl := createWord(t, store, models.Word{Word: "wilk", Language: "pl"})
+	en := createWord(t, store, models.Word{Word: "wolf", Language: "en"})
+	createTranslation(t, store, models.Translation{SourceWordID: pl.ID, TargetWordID: en.ID})
 
 	var wg sync.WaitGroup
 	concurrency := 10
@@ -174,20 +317,18 @@ func TestConcurrentDeleteTranslation_RaceCondition(t *testing.T) {
 			defer wg.Done()
 			params := graphql.ResolveParams{
 				Args: map[string]interface{}{
-					"wordPl": "wilk",
-					"wordEn": "wolf",
+					"sourceWord":     "wilk",
+					"sourceLanguage": "pl",
+					"targetWord":     "wolf",
+					"targetLanguage": "en",
 				},
 			}
-			_, _ = handlers.DeleteTranslation(params)
+			_, _ = h.DeleteTranslation(params)
 		}()
 	}
 
 	wg.Wait()
 
 	// Check, if translation was deleted correctly
-	var count int64
-	utils.DB.Model(&models.Translation{}).
-		Where("word_id_pl = ? AND word_id_en = ?", pl.ID, en.ID).
-		Count(&count)
-	assert.Equal(t, int64(0), count, "Translation should be deleted exactly once")
+	assert.Equal(t, 0, countTranslations(t, store, pl.ID), "Translation should be deleted exactly once")
 }
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/tdawidzi/dictionary_app/apperrors"
+	"github.com/tdawidzi/dictionary_app/jobs"
+	"github.com/tdawidzi/dictionary_app/models"
+	"github.com/tdawidzi/dictionary_app/repository"
+
+	"github.com/graphql-go/graphql"
+)
+
+// Types of entries in trash
+const (
+	TrashWord        = "word"
+	TrashTranslation = "translation"
+	TrashExample     = "example"
+)
+
+var TrashEntryTypes = []string{TrashWord, TrashTranslation, TrashExample}
+
+const (
+	defaultTrashLimit = 50
+	maxTrashLimit     = 200
+)
+
+// TrashEntry is deleted word, translation or example
+type TrashEntry struct {
+	Type       string
+	ID         uint
+	DeletedAt  time.Time
+	Word       *models.Word // deleted word, word of deleted example or source word of deleted translation
+	TargetWord *models.Word // target word of deleted translation
+	Example    *models.Example
+}
+
+// GetTrash lists deleted words, translations and examples of given dictionaries, recently deleted first.
+// Entries stay in trash until they are restored or purged (see jobs.Purge).
+func (h *Handlers) GetTrash(p graphql.ResolveParams) (interface{}, error) {
+	limit, ok := p.Args["limit"].(int)
+	if !ok {
+		limit = defaultTrashLimit
+	}
+	if limit <= 0 || limit > maxTrashLimit {
+		return nil, apperrors.Validation("limit must be between 1 and %d", maxTrashLimit)
+	}
+	dictionaries, err := dictionariesArg(h.store, p)
+	if err != nil {
+		return nil, err
+	}
+
+	words, err := h.store.Words().Deleted(dictionaries, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deleted words: %w", err)
+	}
+	translations, err := h.store.Translations().Deleted(dictionaries, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deleted translations: %w", err)
+	}
+	examples, err := h.store.Examples().Deleted(dictionaries, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deleted examples: %w", err)
+	}
+
+	entries := make([]TrashEntry, 0, len(words)+len(translations)+len(examples))
+	for _, w := range words {
+		entries = append(entries, TrashEntry{Type: TrashWord, ID: w.ID, DeletedAt: w.DeletedAt.Time, Word: &w})
+	}
+	for _, t := range translations {
+		entries = append(entries, TrashEntry{
+			Type:       TrashTranslation,
+			ID:         t.ID,
+			DeletedAt:  t.DeletedAt.Time,
+			Word:       &t.SourceWord,
+			TargetWord: &t.TargetWord,
+		})
+	}
+	for _, e := range examples {
+		entries = append(entries, TrashEntry{Type: TrashExample, ID: e.ID, DeletedAt: e.DeletedAt.Time, Word: &e.Word, Example: &e})
+	}
+
+	// Entries deleted together (word with its translations and examples) stay in order of types
+	slices.SortStableFunc(entries, func(a, b TrashEntry) int { return cmp.Compare(b.DeletedAt.UnixNano(), a.DeletedAt.UnixNano()) })
+	return entries[:min(limit, len(entries))], nil
+}
+
+// RestoreWord brings word with given id back from trash, with translations and examples deleted together with it
+func (h *Handlers) RestoreWord(p graphql.ResolveParams) (interface{}, error) {
+	id, ok := p.Args["id"].(int)
+	if !ok {
+		return nil, apperrors.Validation("invalid or missing ID")
+	}
+
+	var word models.Word
+	err := h.store.Transaction(func(tx repository.Store) error {
+		var err error
+		if word, err = tx.Words().Restore(uint(id)); err != nil {
+			return restoreError("word", err)
+		}
+		_, err = record(tx, p, wordChange(models.OperationRestore, word, nil, newWordValues(word)))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return word, nil
+}
+
+// RestoreExample brings example with given id back from trash - its word cannot be in trash
+func (h *Handlers) RestoreExample(p graphql.ResolveParams) (interface{}, error) {
+	id, ok := p.Args["id"].(int)
+	if !ok {
+		return nil, apperrors.Validation("invalid or missing ID")
+	}
+
+	var example models.Example
+	err := h.store.Transaction(func(tx repository.Store) error {
+		var err error
+		if example, err = tx.Examples().Restore(uint(id)); err != nil {
+			return restoreError("example", err)
+		}
+		_, err = record(tx, p, exampleChange(models.OperationRestore, example, nil, newExampleValues(example)))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return example, nil
+}
+
+// PurgeTrash permanently deletes entries of given dictionary moved to trash before given time (all entries by default),
+// without waiting for the purge job
+func (h *Handlers) PurgeTrash(p graphql.ResolveParams) (interface{}, error) {
+	before, ok := p.Args["before"].(time.Time)
+	if !ok {
+		before = time.Now()
+	}
+	dictionaryID, err := dictionaryArg(h.store, p)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := jobs.Purge(h.store, before, []uint{dictionaryID})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// restoreError describes failed restore - entry taken by other one meanwhile or deleted word are conflicts
+func restoreError(what string, err error) error {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return apperrors.NotFound("deleted %s not found: %w", what, err)
+	case errors.Is(err, repository.ErrWordDeleted):
+		return apperrors.Conflict("failed to restore %s (restore its word first): %w", what, err)
+	}
+	return writeError("restore "+what, err)
+}
@@ -0,0 +1,92 @@
+package handlers_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tdawidzi/dictionary_app/models"
+	"github.com/tdawidzi/dictionary_app/repository"
+)
+
+func TestDeleteAndRestoreWordWithEachStore(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store repository.Store) {
+		c := newClient(t, store)
+		kot := createWord(t, store, models.Word{Word: "kot", Language: "pl"})
+		cat := createWord(t, store, models.Word{Word: "cat", Language: "en"})
+		createTranslation(t, store, models.Translation{SourceWordID: kot.ID, TargetWordID: cat.ID})
+		createExample(t, store, models.Example{WordID: kot.ID, Example: "Ala ma kota."})
+
+		// Deleting word deletes its translations and examples
+		c.data(t, `mutation { deleteWord(word: "kot", language: "pl") }`)
+		data := c.data(t, `{ words(language: "en") { edges { node { translations { word } } } } }`)
+		assert.Equal(t, map[string]interface{}{
+			"edges": []interface{}{
+				map[string]interface{}{"node": map[string]interface{}{"translations": []interface{}{}}},
+			},
+		}, data["words"])
+
+		// Deleted entries are in trash until restored
+		entries, _ := c.data(t, `{ trash { type id word { word } example { example } } }`)["trash"].([]interface{})
+		types := map[string]map[string]interface{}{}
+		for _, entry := range entries {
+			types[entry.(map[string]interface{})["type"].(string)] = entry.(map[string]interface{})
+		}
+		if !assert.Len(t, types, 3) {
+			return
+		}
+		assert.Equal(t, map[string]interface{}{"word": "kot"}, types["WORD"]["word"])
+		assert.Equal(t, map[string]interface{}{"example": "Ala ma kota."}, types["EXAMPLE"]["example"])
+		assert.Nil(t, types["WORD"]["example"])
+
+		// Entries cannot be restored before their word
+		assertErrorCode(t, c.do(`mutation { restoreExample(id: `+fmt.Sprint(types["EXAMPLE"]["id"])+`) { id } }`), "CONFLICT")
+		data = c.data(t, `mutation { restoreWord(id: `+fmt.Sprint(types["WORD"]["id"])+`) { word translations { word } } }`)
+		assert.Equal(t, map[string]interface{}{
+			"word":         "kot",
+			"translations": []interface{}{map[string]interface{}{"word": "cat"}},
+		}, data["restoreWord"])
+		assertErrorCode(t, c.do(`mutation { restoreWord(id: `+fmt.Sprint(types["WORD"]["id"])+`) { word } }`), "NOT_FOUND")
+		assert.Equal(t, []interface{}{}, c.data(t, `{ trash { id } }`)["trash"])
+	})
+}
+
+func TestPurgeTrashWithEachStore(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store repository.Store) {
+		c := newClient(t, store)
+		createWord(t, store, models.Word{Word: "pies", Language: "pl"})
+		createWord(t, store, models.Word{Word: "kot", Language: "pl"})
+
+		c.data(t, `mutation { deleteWord(word: "pies", language: "pl") }`)
+		data := c.data(t, `mutation { purgeTrash { words translations examples } }`)
+		assert.Equal(t, map[string]interface{}{"words": 1, "translations": 0, "examples": 0}, data["purgeTrash"])
+		assert.Equal(t, []interface{}{}, c.data(t, `{ trash { id } }`)["trash"])
+		assert.Len(t, findWords(t, store, "kot"), 1)
+	})
+}
+
+func TestTrashOfDictionariesWithEachStore(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store repository.Store) {
+		c := newClient(t, store)
+		medical := models.Dictionary{Name: "medical"}
+		assert.NoError(t, store.Dictionaries().Create(&medical))
+		createWord(t, store, models.Word{Word: "kot", Language: "pl"})
+		createWord(t, store, models.Word{Word: "kot", Language: "pl", DictionaryID: medical.ID})
+		c.data(t, `mutation { deleteWord(word: "kot", language: "pl") }`)
+		c.data(t, `mutation { deleteWord(word: "kot", language: "pl", dictionary: "medical") }`)
+
+		// Trash and purge are limited to given dictionaries, the default one unless told otherwise
+		data := c.data(t, `{ trash { word { dictionary { name } } } }`)
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"word": map[string]interface{}{"dictionary": map[string]interface{}{"name": "default"}}},
+		}, data["trash"])
+		data = c.data(t, `{ trash(dictionaries: ["default", "medical"]) { id } }`)
+		assert.Len(t, data["trash"], 2)
+		assertErrorCode(t, c.do(`{ trash(dictionaries: ["legal"]) { id } }`), "NOT_FOUND")
+
+		data = c.data(t, `mutation { purgeTrash(dictionary: "medical") { words } }`)
+		assert.Equal(t, map[string]interface{}{"words": 1}, data["purgeTrash"])
+		assert.Equal(t, []interface{}{}, c.data(t, `{ trash(dictionaries: ["medical"]) { id } }`)["trash"])
+		assert.Len(t, c.data(t, `{ trash { id } }`)["trash"], 1)
+	})
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/tdawidzi/dictionary_app/apperrors"
+	"github.com/tdawidzi/dictionary_app/auth"
+	"github.com/tdawidzi/dictionary_app/models"
+	"github.com/tdawidzi/dictionary_app/repository"
+	"github.com/tdawidzi/dictionary_app/validation"
+
+	"github.com/graphql-go/graphql"
+)
+
+// GetUsers lists users with their roles, ordered by name
+func (h *Handlers) GetUsers(p graphql.ResolveParams) (interface{}, error) {
+	users, err := h.store.Users().List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query users: %w", err)
+	}
+	return users, nil
+}
+
+// SetUserRole gives role to principal with given ID ("apikey:<name>" or "jwt:<issuer>/<subject>"), adding user if needed.
+// Principals cannot change their own role, so the last admin cannot lock everyone out.
+func (h *Handlers) SetUserRole(p graphql.ResolveParams) (interface{}, error) {
+	name, _ := p.Args["name"].(string)
+	role, _ := p.Args["role"].(string)
+
+	var v validation.Validator
+	name = v.Text("name", name, validation.MaxPrincipalLength)
+	if !v.Failed("name") && !auth.ValidID(name) {
+		v.Fail("name", "must be ID of principal: apikey:<name> or jwt:<issuer>/<subject>")
+	}
+	v.OneOf("role", role, models.Roles...)
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+	if err := checkNotSelf(p, name, "change own role"); err != nil {
+		return nil, err
+	}
+
+	var user models.User
+	err := h.store.Transaction(func(tx repository.Store) error {
+		var err error
+		user, err = tx.Users().Find(name)
+		if errors.Is(err, repository.ErrNotFound) {
+			user = models.User{Name: name, Role: role}
+			if err := tx.Users().Create(&user); err != nil {
+				return writeError("add user", err)
+			}
+			_, err = record(tx, p, userChange(models.OperationCreate, user, nil, newUserValues(user)))
+			return err
+		}
+		if err != nil {
+			return fmt.Errorf("failed to query user: %w", err)
+		}
+
+		before := newUserValues(user)
+		user.Role = role
+		if err := tx.Users().Save(&user); err != nil {
+			return writeError("update user", err)
+		}
+		_, err = record(tx, p, userChange(models.OperationUpdate, user, before, newUserValues(user)))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// DeleteUser removes user with given principal ID - the principal becomes viewer
+func (h *Handlers) DeleteUser(p graphql.ResolveParams) (interface{}, error) {
+	name, _ := p.Args["name"].(string)
+	if err := checkNotSelf(p, name, "delete own user"); err != nil {
+		return nil, err
+	}
+
+	err := h.store.Transaction(func(tx repository.Store) error {
+		user, err := tx.Users().Find(name)
+		if err != nil {
+			return lookupError("user", err)
+		}
+		if err := tx.Users().Delete(user.ID); err != nil {
+			return fmt.Errorf("failed to delete user: %w", err)
+		}
+		_, err = record(tx, p, userChange(models.OperationDelete, user, newUserValues(user), nil))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return true, nil
+}
+
+// checkNotSelf rejects changes of user of request principal
+func checkNotSelf(p graphql.ResolveParams, name, action string) error {
+	if principal, ok := auth.PrincipalFrom(p.Context); ok && principal.ID() == name {
+		return apperrors.Validation("cannot %s", action)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package handlers_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tdawidzi/dictionary_app/models"
+	"github.com/tdawidzi/dictionary_app/repository"
+)
+
+func TestRolesWithEachStore(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store repository.Store) {
+		c := newClient(t, store)
+		createWord(t, store, models.Word{Word: "kot", Language: "pl"})
+
+		// Viewers only query, editors add and update, admins delete and manage users
+		result := c.doAs("ela", models.RoleViewer, `{ word(word: "kot", language: "pl") { word } }`)
+		assert.Empty(t, result.Errors)
+		assertErrorCode(t, c.doAs("ela", models.RoleViewer, `mutation { addWord(word: "pies", language: "pl") { id } }`), "FORBIDDEN")
+		assertErrorCode(t, c.doAs("ela", models.RoleViewer, `{ auditLog { id } }`), "FORBIDDEN")
+		assertErrorCode(t, c.doAs("ela", models.RoleViewer, `{ history(wordId: 1) { id } }`), "FORBIDDEN")
+		result = c.doAs("ola", models.RoleEditor, `mutation { addWord(word: "pies", language: "pl") { id } }`)
+		assert.Empty(t, result.Errors)
+		assertErrorCode(t, c.doAs("ola", models.RoleEditor, `mutation { deleteWord(word: "pies", language: "pl") }`), "FORBIDDEN")
+		assertErrorCode(t, c.doAs("ola", models.RoleEditor, `mutation { setUserRole(name: "apikey:ola", role: ADMIN) { name } }`), "FORBIDDEN")
+		assertErrorCode(t, c.doAs("ola", models.RoleEditor, `mutation { addDictionary(name: "medical") { id } }`), "FORBIDDEN")
+	})
+}
+
+func TestManageUsersWithEachStore(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store repository.Store) {
+		c := newClient(t, store)
+
+		data := c.data(t, `mutation { setUserRole(name: "apikey:ola", role: EDITOR) { name role } }`)
+		assert.Equal(t, map[string]interface{}{"name": "apikey:ola", "role": "EDITOR"}, data["setUserRole"])
+
+		// Admins cannot change their own role, users are identified by authentication method
+		assertErrorCode(t, c.do(`mutation { setUserRole(name: "apikey:ala", role: VIEWER) { name } }`), "VALIDATION")
+		assertErrorCode(t, c.do(`mutation { setUserRole(name: "ola", role: EDITOR) { name } }`), "VALIDATION")
+
+		data = c.data(t, `{ users { name role } }`)
+		assert.Equal(t, []interface{}{map[string]interface{}{"name": "apikey:ola", "role": "EDITOR"}}, data["users"])
+		c.data(t, `mutation { deleteUser(name: "apikey:ola") }`)
+		assert.Equal(t, []interface{}{}, c.data(t, `{ users { name } }`)["users"])
+		data = c.data(t, `{ auditLog(actor: "apikey:ala") { entity operation } }`)
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"entity": "USER", "operation": "CREATE"},
+			map[string]interface{}{"entity": "USER", "operation": "DELETE"},
+		}, data["auditLog"])
+	})
+}
@@ -3,96 +3,436 @@ package handlers
 import (
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 
+	"github.com/tdawidzi/dictionary_app/apperrors"
 	"github.com/tdawidzi/dictionary_app/models"
-	"github.com/tdawidzi/dictionary_app/utils"
+	"github.com/tdawidzi/dictionary_app/repository"
+	"github.com/tdawidzi/dictionary_app/textutil"
+	"github.com/tdawidzi/dictionary_app/validation"
 
 	"github.com/graphql-go/graphql"
-	"gorm.io/gorm"
 )
 
-// GetWords fetches all words in database - for display of dictionary content
-func GetWords(p graphql.ResolveParams) (interface{}, error) {
-	var words []models.Word
-	err := utils.DB.Find(&words).Error
+// GetWords fetches page of words - for display of dictionary content.
+// Words can be filtered by dictionaries (the default one if not given), language, prefix (case and diacritic insensitive), grammatical attributes and having translations.
+// Pages are read forward - "first" words after "after" cursor (keyset pagination, stable when words are added).
+func (h *Handlers) GetWords(p graphql.ResolveParams) (interface{}, error) {
+	first, ok := p.Args["first"].(int)
+	if !ok {
+		first = defaultPageSize
+	}
+	if first < 0 || first > maxPageSize {
+		return nil, apperrors.Validation("first must be between 0 and %d", maxPageSize)
+	}
+	sort, ok := p.Args["sort"].(string)
+	if !ok {
+		sort = WordSortAlphabetical
+	}
+	if !slices.Contains(WordSorts, sort) {
+		return nil, apperrors.Validation("unsupported sort order: %s", sort)
+	}
+
+	// Filters
+	var filter repository.WordFilter
+	dictionaries, err := dictionariesArg(h.store, p)
+	if err != nil {
+		return nil, err
+	}
+	filter.Dictionaries = dictionaries
+	filter.Language, _ = p.Args["language"].(string)
+	if prefix, ok := p.Args["prefix"].(string); ok {
+		filter.Prefix = textutil.SearchKey(prefix)
+	}
+	filter.PartOfSpeech, _ = p.Args["partOfSpeech"].(string)
+	filter.Gender, _ = p.Args["gender"].(string)
+	filter.Aspect, _ = p.Args["aspect"].(string)
+	filter.Countability, _ = p.Args["countability"].(string)
+	filter.Transitivity, _ = p.Args["transitivity"].(string)
+	if hasTranslations, ok := p.Args["hasTranslations"].(bool); ok {
+		filter.HasTranslations = &hasTranslations
+	}
+
+	totalCount, err := h.store.Words().Count(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count words: %w", err)
+	}
+
+	// Position after cursor
+	after, hasAfter := p.Args["after"].(string)
+	var position *repository.WordPosition
+	if hasAfter {
+		cursor, err := decodeWordCursor(sort, after)
+		if err != nil {
+			return nil, err
+		}
+		position = &repository.WordPosition{SortKey: cursor.SortKey, ID: cursor.ID}
+	}
+
+	// One more word tells if there is next page
+	words, err := h.store.Words().List(filter, sort, position, first+1)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch words: %w", err)
 	}
-	return words, nil
+
+	connection := WordConnection{
+		Edges:      make([]WordEdge, 0, first),
+		TotalCount: totalCount,
+		PageInfo:   PageInfo{HasNextPage: len(words) > first, HasPreviousPage: hasAfter},
+	}
+	if len(words) > first {
+		words = words[:first]
+	}
+	for _, w := range words {
+		connection.Edges = append(connection.Edges, WordEdge{Cursor: encodeWordCursor(sort, w), Node: w})
+	}
+	if len(connection.Edges) > 0 {
+		connection.PageInfo.StartCursor = &connection.Edges[0].Cursor
+		connection.PageInfo.EndCursor = &connection.Edges[len(connection.Edges)-1].Cursor
+	}
+	return connection, nil
 }
 
-// Adds Word to database
-func AddWord(p graphql.ResolveParams) (interface{}, error) {
+// Adds Word to database (to given dictionary, the default one if not given)
+// Homograph number (default: 1) allows adding the same spelling several times in one language
+func (h *Handlers) AddWord(p graphql.ResolveParams) (interface{}, error) {
 	word, _ := p.Args["word"].(string)
 	language, _ := p.Args["language"].(string)
+	homograph, hasHomograph := p.Args["homograph"].(int)
+	if !hasHomograph {
+		homograph = 1
+	}
 
-	var existing models.Word
-	if err := utils.DB.Where("word = ? AND language = ?", word, language).First(&existing).Error; err == nil {
-		// If record exists - return it
-		return existing, nil
-	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
-		// Other errors
-		return nil, fmt.Errorf("failed to query word: %w", err)
+	var v validation.Validator
+	word = v.Text("word", word, validation.MaxWordLength)
+	if homograph < 1 {
+		v.Fail("homograph", "must be positive")
+	}
+
+	dictionaryID, err := dictionaryArg(h.store, p)
+	if err != nil {
+		return nil, err
+	}
+
+	// Words can be added only in registered languages, in script of the language
+	lang, err := validLanguage(h.store, &v, "language", validation.Normalize(language))
+	if err != nil {
+		return nil, err
+	}
+	language = lang.Code
+	v.Script("word", word, lang.Script)
+	if err := v.Err(); err != nil {
+		return nil, err
 	}
 
-	// Record not found - create new record
-	newWord := models.Word{Word: word, Language: language}
-	if err := utils.DB.Create(&newWord).Error; err != nil {
-		return nil, fmt.Errorf("failed to add word: %w", err)
+	newWord := models.Word{DictionaryID: dictionaryID, Word: word, Language: language, Homograph: homograph}
+	setWordAttributes(&newWord, p.Args)
+	err = h.store.Transaction(func(tx repository.Store) error {
+		// Word is inserted only if it does not exist yet - concurrent requests adding the same word insert it once
+		created, err := tx.Words().Create(&newWord)
+		if err != nil {
+			return writeError("add word", err)
+		}
+		if created {
+			_, err := record(tx, p, wordChange(models.OperationCreate, newWord, nil, newWordValues(newWord)))
+			return err
+		}
+
+		// If record exists - return it
+		newWord, err = findWord(tx, dictionaryID, word, language, homograph)
+		if err != nil {
+			return fmt.Errorf("failed to query word: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return newWord, nil
 }
 
 // Modify existing word in db
-func UpdateWord(p graphql.ResolveParams) (interface{}, error) {
+func (h *Handlers) UpdateWord(p graphql.ResolveParams) (interface{}, error) {
 	oldWord, _ := p.Args["oldWord"].(string)
 	language, _ := p.Args["language"].(string)
-	newWord, _ := p.Args["newWord"].(string)
+	homograph, _ := p.Args["homograph"].(int)
+	newWord, hasNewWord := p.Args["newWord"].(string)
+	expectedVersion, err := expectedVersionArg(p)
+	if err != nil {
+		return nil, err
+	}
+	dictionaryID, err := dictionaryArg(h.store, p)
+	if err != nil {
+		return nil, err
+	}
 
 	// Check if word exists
-	var word models.Word
-	if err := utils.DB.Where("word = ? AND language = ?", oldWord, language).First(&word).Error; err != nil {
-		return nil, fmt.Errorf("word not found: %w", err)
+	word, err := findWord(h.store, dictionaryID, oldWord, language, homograph)
+	if err != nil {
+		return nil, lookupError("word", err)
 	}
 
-	// Modify and save word
-	word.Word = newWord
-	if err := utils.DB.Save(&word).Error; err != nil {
-		return nil, fmt.Errorf("failed to update word: %w", err)
+	if hasNewWord {
+		var v validation.Validator
+		newWord = v.Text("newWord", newWord, validation.MaxWordLength)
+		lang, err := findLanguage(h.store, word.Language)
+		if err != nil {
+			return nil, err
+		}
+		v.Script("newWord", newWord, lang.Script)
+		if err := v.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	err = h.store.Transaction(func(tx repository.Store) error {
+		// Word is read again and locked, so concurrent updates of other fields are not lost
+		word, err = tx.Words().GetForUpdate(word.ID)
+		if err != nil {
+			return lookupError("word", err)
+		}
+		if err := checkVersion("word", word.Version, expectedVersion); err != nil {
+			return err
+		}
+
+		// Modify and save word - only given fields are changed
+		before := newWordValues(word)
+		if hasNewWord {
+			word.Word = newWord
+		}
+		setWordAttributes(&word, p.Args)
+		if err := tx.Words().Save(&word); err != nil {
+			return writeError("update word", err)
+		}
+		_, err := record(tx, p, wordChange(models.OperationUpdate, word, before, newWordValues(word)))
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return word, nil
 }
 
-// Delete existing word from database
-func DeleteWord(p graphql.ResolveParams) (interface{}, error) {
+// Delete existing word - it is moved to trash with its translations and examples (see RestoreWord)
+func (h *Handlers) DeleteWord(p graphql.ResolveParams) (interface{}, error) {
 	wordValue, _ := p.Args["word"].(string)
 	language, _ := p.Args["language"].(string)
+	homograph, _ := p.Args["homograph"].(int)
+	dictionaryID, err := dictionaryArg(h.store, p)
+	if err != nil {
+		return nil, err
+	}
 
 	// Check if word exists
-	var word models.Word
-	if err := utils.DB.Where("word = ? AND language = ?", wordValue, language).First(&word).Error; err != nil {
-		return nil, fmt.Errorf("word not found: %w", err)
+	word, err := findWord(h.store, dictionaryID, wordValue, language, homograph)
+	if err != nil {
+		return nil, lookupError("word", err)
 	}
 
 	// Delete the word
-	if err := utils.DB.Delete(&word).Error; err != nil {
-		return nil, fmt.Errorf("failed to delete word: %w", err)
+	err = h.store.Transaction(func(tx repository.Store) error {
+		if err := tx.Words().Delete(word.ID); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return lookupError("word", err)
+			}
+			return fmt.Errorf("failed to delete word: %w", err)
+		}
+		_, err := record(tx, p, wordChange(models.OperationDelete, word, newWordValues(word), nil))
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Return true if succeeded
 	return true, nil
 }
-func GetWordByText(p graphql.ResolveParams) (interface{}, error) {
+
+// GetWordByText fetches single word by its text (case and diacritic insensitive, unless exact match is requested).
+// Language and homograph number are optional, but needed when the text alone is ambiguous.
+// Word is looked up in given dictionaries (the default one if not given) - with several dictionaries the text can be ambiguous too.
+// Inflected form (e.g. "kota") is resolved to its lemma ("kot") if no word is spelled like that.
+// If word does not exist, similar words are suggested in error extensions.
+func (h *Handlers) GetWordByText(p graphql.ResolveParams) (interface{}, error) {
 	wordStr, ok := p.Args["word"].(string)
 	if !ok {
-		return nil, errors.New("missing word")
+		return nil, apperrors.Validation("missing word")
+	}
+	language, _ := p.Args["language"].(string)
+	homograph, _ := p.Args["homograph"].(int)
+	exact, _ := p.Args["exact"].(bool)
+	dictionaries, err := dictionariesArg(h.store, p)
+	if err != nil {
+		return nil, err
 	}
 
-	var word models.Word
-	if err := utils.DB.Where("word = ?", wordStr).First(&word).Error; err != nil {
-		return nil, fmt.Errorf("word not found: %w", err)
+	word, err := lookupWord(h.store, dictionaries, wordStr, language, homograph, exact)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, wordNotFound(h.store, wordStr, language, dictionaries, err)
+	} else if err != nil {
+		return nil, lookupError("word", err)
 	}
 
 	return word, nil
 }
+
+// setWordAttributes copies grammatical attributes given as GraphQL arguments to word
+func setWordAttributes(word *models.Word, args map[string]interface{}) {
+	attributes := map[string]*string{
+		"partOfSpeech": &word.PartOfSpeech,
+		"gender":       &word.Gender,
+		"aspect":       &word.Aspect,
+		"countability": &word.Countability,
+		"transitivity": &word.Transitivity,
+	}
+	for arg, attribute := range attributes {
+		if value, ok := args[arg].(string); ok {
+			if value == models.AttributeNone {
+				value = ""
+			}
+			*attribute = value
+		}
+	}
+}
+
+// lockWords locks given words until end of transaction, so they cannot be deleted meanwhile
+// and concurrent transactions locking any of them wait
+func lockWords(tx repository.Store, ids ...uint) error {
+	if err := tx.Words().Lock(ids...); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return lookupError("word", err)
+		}
+		return fmt.Errorf("failed to lock words: %w", err)
+	}
+	return nil
+}
+
+// errAmbiguousWord - more than one word matches lookup criteria
+var errAmbiguousWord = errors.New("word is ambiguous")
+
+// lookupWord resolves text typed by user to single word. Candidates are checked in order:
+// exact spelling, normalized search key, exact inflected form, normalized inflected form.
+// With exact set only exact spelling of word and its forms is matched.
+func lookupWord(store repository.Store, dictionaries []uint, text, language string, homograph int, exact bool) (models.Word, error) {
+	text = validation.Normalize(text)
+	key := textutil.SearchKey(text)
+	lookups := []repository.WordLookup{
+		{Word: text},
+		{SearchKey: key},
+		{Form: text},
+		{FormSearchKey: key},
+	}
+
+	for i, lookup := range lookups {
+		// Every second lookup is the normalized one
+		if exact && i%2 == 1 {
+			continue
+		}
+		lookup.Dictionaries, lookup.Language, lookup.Homograph = dictionaries, language, homograph
+		word, err := findSingleWord(store, lookup)
+		if !errors.Is(err, repository.ErrNotFound) {
+			return word, err
+		}
+	}
+	return models.Word{}, repository.ErrNotFound
+}
+
+// findWord looks up single word of dictionary by its text (normalized like saved words). Empty language and zero homograph
+// mean "any" - if more than one word matches, errAmbiguousWord listing all candidates is returned.
+func findWord(store repository.Store, dictionaryID uint, text, language string, homograph int) (models.Word, error) {
+	return findSingleWord(store, repository.WordLookup{
+		Word:         validation.Normalize(text),
+		Dictionaries: []uint{dictionaryID},
+		Language:     language,
+		Homograph:    homograph,
+	})
+}
+
+// findSingleWord expects exactly one word matching lookup
+func findSingleWord(store repository.Store, lookup repository.WordLookup) (models.Word, error) {
+	words, err := store.Words().Find(lookup)
+	if err != nil {
+		return models.Word{}, err
+	}
+
+	switch len(words) {
+	case 0:
+		return models.Word{}, repository.ErrNotFound
+	case 1:
+		return words[0], nil
+	}
+
+	// Candidates from several dictionaries are told apart by names of dictionaries
+	var names map[uint]models.Dictionary
+	if slices.ContainsFunc(words, func(w models.Word) bool { return w.DictionaryID != words[0].DictionaryID }) {
+		if names, err = loadDictionaries(store); err != nil {
+			return models.Word{}, err
+		}
+	}
+	candidates := make([]string, 0, len(words))
+	for _, w := range words {
+		if names != nil {
+			candidates = append(candidates, fmt.Sprintf("%s (dictionary: %s, language: %s, homograph: %d)",
+				w.Word, names[w.DictionaryID].Name, w.Language, w.Homograph))
+			continue
+		}
+		candidates = append(candidates, fmt.Sprintf("%s (language: %s, homograph: %d)", w.Word, w.Language, w.Homograph))
+	}
+	hint := "language or homograph"
+	if names != nil {
+		hint = "dictionary, language or homograph"
+	}
+	return models.Word{}, fmt.Errorf("%w, specify %s: %s", errAmbiguousWord, hint, strings.Join(candidates, ", "))
+}
+
+// lookupError describes failed lookup of "what" (e.g. "source word")
+func lookupError(what string, err error) error {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return apperrors.NotFound("%s not found: %w", what, err)
+	case errors.Is(err, errAmbiguousWord):
+		return apperrors.Validation("%s: %w", what, err)
+	default:
+		return fmt.Errorf("failed to query %s: %w", what, err)
+	}
+}
+
+// writeError describes failed write - unique constraint violations and stale versions are conflicts, other errors are internal
+func writeError(action string, err error) error {
+	if errors.Is(err, repository.ErrDuplicate) || errors.Is(err, repository.ErrStaleVersion) {
+		return apperrors.Conflict("failed to %s: %w", action, err)
+	}
+	return fmt.Errorf("failed to %s: %w", action, err)
+}
+
+// sourceWord returns word which field is resolved - words in trash entries are given by pointers
+func sourceWord(p graphql.ResolveParams) (models.Word, bool) {
+	switch word := p.Source.(type) {
+	case models.Word:
+		return word, true
+	case *models.Word:
+		if word != nil {
+			return *word, true
+		}
+	}
+	return models.Word{}, false
+}
+
+// expectedVersionArg reads version of entity the update is based on
+func expectedVersionArg(p graphql.ResolveParams) (int, error) {
+	version, ok := p.Args["expectedVersion"].(int)
+	if !ok {
+		return 0, apperrors.Validation("invalid or missing expected version")
+	}
+	return version, nil
+}
+
+// checkVersion rejects update based on outdated version of entity (read for update in current version)
+func checkVersion(what string, current, expected int) error {
+	if current != expected {
+		return apperrors.StaleVersion(current, "%s was modified meanwhile: current version is %d, expected %d", what, current, expected)
+	}
+	return nil
+}
@@ -1,27 +1,21 @@
 package handlers_test
 
 import (
+	"errors"
+	"strings"
 	"sync"
 	"testing"
 
 	"github.com/graphql-go/graphql"
 	"github.com/stretchr/testify/assert"
+	"github.com/tdawidzi/dictionary_app/apperrors"
 	"github.com/tdawidzi/dictionary_app/handlers"
 	"github.com/tdawidzi/dictionary_app/models"
-	"github.com/tdawidzi/dictionary_app/testresources"
-	"github.com/tdawidzi/dictionary_app/utils"
+	"github.com/tdawidzi/dictionary_app/repository"
 )
 
-func setupTestDB(t *testing.T) {
-	utils.DB = testresources.NewSingleTestConnection(t)
-	err := utils.DB.AutoMigrate(&models.Word{})
-	if err != nil {
-		t.Fatalf("failed to migrate: %v", err)
-	}
-}
-
 func TestAddAndGetWord(t *testing.T) {
-	setupTestDB(t)
+	_, h := setupTestStore(t)
 
 	// Add word
 	params := graphql.ResolveParams{
@@ -31,7 +25,7 @@ func TestAddAndGetWord(t *testing.T) {
 		},
 	}
 
-	result, err := handlers.AddWord(params)
+	result, err := h.AddWord(params)
 	assert.NoError(t, err)
 
 	word, ok := result.(models.Word)
@@ -46,7 +40,7 @@ func TestAddAndGetWord(t *testing.T) {
 		},
 	}
 
-	result, err = handlers.GetWordByText(getParams)
+	result, err = h.GetWordByText(getParams)
 	assert.NoError(t, err)
 
 	word, ok = result.(models.Word)
@@ -54,49 +48,458 @@ func TestAddAndGetWord(t *testing.T) {
 	assert.Equal(t, "kot", word.Word)
 }
 
+func TestGetWordIgnoresCaseAndDiacritics(t *testing.T) {
+	store, h := setupTestStore(t)
+
+	createWord(t, store, models.Word{Word: "żółw", Language: "pl"})
+
+	for _, text := range []string{"żółw", "zolw", "ŻÓŁW", "Zółw"} {
+		result, err := h.GetWordByText(graphql.ResolveParams{
+			Args: map[string]interface{}{"word": text},
+		})
+		assert.NoError(t, err, text)
+
+		word, ok := result.(models.Word)
+		assert.True(t, ok)
+		assert.Equal(t, "żółw", word.Word)
+	}
+
+	// Exact match requires the same spelling
+	_, err := h.GetWordByText(graphql.ResolveParams{
+		Args: map[string]interface{}{"word": "zolw", "exact": true},
+	})
+	assert.Error(t, err)
+}
+
+func TestGetWordPrefersExactSpelling(t *testing.T) {
+	store, h := setupTestStore(t)
+
+	// "kot" and "kót" have the same search key
+	createWord(t, store, models.Word{Word: "kot", Language: "pl"})
+	createWord(t, store, models.Word{Word: "kót", Language: "pl"})
+
+	result, err := h.GetWordByText(graphql.ResolveParams{
+		Args: map[string]interface{}{"word": "kót"},
+	})
+	assert.NoError(t, err)
+	word, ok := result.(models.Word)
+	assert.True(t, ok)
+	assert.Equal(t, "kót", word.Word)
+
+	// Normalized text alone is ambiguous
+	_, err = h.GetWordByText(graphql.ResolveParams{
+		Args: map[string]interface{}{"word": "KOT"},
+	})
+	assert.Error(t, err)
+}
+
+func TestAddHomographsInDifferentLanguages(t *testing.T) {
+	store, h := setupTestStore(t)
+
+	// "pies" is both polish (dog) and english (plural of pie) word
+	for _, language := range []string{"pl", "en"} {
+		params := graphql.ResolveParams{
+			Args: map[string]interface{}{
+				"word":     "pies",
+				"language": language,
+			},
+		}
+		_, err := h.AddWord(params)
+		assert.NoError(t, err)
+	}
+
+	assert.Len(t, findWords(t, store, "pies"), 2)
+
+	// Lookup by text only is ambiguous
+	_, err := h.GetWordByText(graphql.ResolveParams{
+		Args: map[string]interface{}{"word": "pies"},
+	})
+	assert.Error(t, err)
+
+	// Language makes it unambiguous
+	result, err := h.GetWordByText(graphql.ResolveParams{
+		Args: map[string]interface{}{"word": "pies", "language": "en"},
+	})
+	assert.NoError(t, err)
+	word, ok := result.(models.Word)
+	assert.True(t, ok)
+	assert.Equal(t, "en", word.Language)
+}
+
+func TestAddHomographsInOneLanguage(t *testing.T) {
+	store, h := setupTestStore(t)
+
+	// "zamek" - castle (1) and lock (2)
+	for _, homograph := range []int{1, 2, 2} {
+		params := graphql.ResolveParams{
+			Args: map[string]interface{}{
+				"word":      "zamek",
+				"language":  "pl",
+				"homograph": homograph,
+			},
+		}
+		result, err := h.AddWord(params)
+		assert.NoError(t, err)
+		word, ok := result.(models.Word)
+		assert.True(t, ok)
+		assert.Equal(t, homograph, word.Homograph)
+	}
+
+	assert.Len(t, findWords(t, store, "zamek"), 2)
+
+	_, err := h.GetWordByText(graphql.ResolveParams{
+		Args: map[string]interface{}{"word": "zamek", "language": "pl"},
+	})
+	assert.Error(t, err)
+
+	result, err := h.GetWordByText(graphql.ResolveParams{
+		Args: map[string]interface{}{"word": "zamek", "language": "pl", "homograph": 2},
+	})
+	assert.NoError(t, err)
+	word, ok := result.(models.Word)
+	assert.True(t, ok)
+	assert.Equal(t, 2, word.Homograph)
+}
+
 func TestGetWords(t *testing.T) {
-	setupTestDB(t)
+	store, h := setupTestStore(t)
 
 	// Insert test words
-	utils.DB.Create(&models.Word{Word: "pies", Language: "pl"})
-	utils.DB.Create(&models.Word{Word: "dog", Language: "en"})
+	createWord(t, store, models.Word{Word: "pies", Language: "pl"})
+	createWord(t, store, models.Word{Word: "dog", Language: "en"})
+
+	connection := getWords(t, h, map[string]interface{}{})
+	assert.Len(t, connection.Edges, 2)
+	assert.Equal(t, int64(2), connection.TotalCount)
+	assert.False(t, connection.PageInfo.HasNextPage)
+}
+
+func getWords(t *testing.T, h *handlers.Handlers, args map[string]interface{}) handlers.WordConnection {
+	t.Helper()
+
+	result, err := h.GetWords(graphql.ResolveParams{Args: args})
+	assert.NoError(t, err)
+	connection, ok := result.(handlers.WordConnection)
+	assert.True(t, ok)
+	return connection
+}
+
+func edgeWords(connection handlers.WordConnection) []string {
+	words := make([]string, 0, len(connection.Edges))
+	for _, edge := range connection.Edges {
+		words = append(words, edge.Node.Word)
+	}
+	return words
+}
+
+func TestGetWordsPagination(t *testing.T) {
+	store, h := setupTestStore(t)
+
+	for _, word := range []string{"żaba", "zebra", "łąka", "lama", "ćma", "cześć", "ąkać", "kot"} {
+		createWord(t, store, models.Word{Word: word, Language: "pl"})
+	}
+
+	// Alphabetical order with polish collation, page by page
+	var words []string
+	args := map[string]interface{}{"first": 3}
+	for {
+		connection := getWords(t, h, args)
+		assert.Equal(t, int64(8), connection.TotalCount)
+		words = append(words, edgeWords(connection)...)
+		if !connection.PageInfo.HasNextPage {
+			break
+		}
+		args["after"] = *connection.PageInfo.EndCursor
+	}
+	assert.Equal(t, []string{"ąkać", "cześć", "ćma", "kot", "lama", "łąka", "zebra", "żaba"}, words)
+
+	// Newest first
+	connection := getWords(t, h, map[string]interface{}{"first": 2, "sort": handlers.WordSortNewest})
+	assert.Equal(t, []string{"kot", "ąkać"}, edgeWords(connection))
+	connection = getWords(t, h, map[string]interface{}{
+		"first": 2, "sort": handlers.WordSortNewest, "after": *connection.PageInfo.EndCursor,
+	})
+	assert.Equal(t, []string{"cześć", "ćma"}, edgeWords(connection))
+	assert.True(t, connection.PageInfo.HasPreviousPage)
+
+	// Cursor is valid only for the sort order it was created for
+	_, err := h.GetWords(graphql.ResolveParams{
+		Args: map[string]interface{}{"sort": handlers.WordSortID, "after": *connection.PageInfo.EndCursor},
+	})
+	assert.Error(t, err)
+
+	_, err = h.GetWords(graphql.ResolveParams{Args: map[string]interface{}{"first": 1000}})
+	assert.Error(t, err)
+}
+
+func TestGetWordsFilteredByPrefixAndTranslations(t *testing.T) {
+	store, h := setupTestStore(t)
+
+	kot := createWord(t, store, models.Word{Word: "kot", Language: "pl"})
+	cat := createWord(t, store, models.Word{Word: "cat", Language: "en"})
+	createWord(t, store, models.Word{Word: "kołdra", Language: "pl"})
+	createWord(t, store, models.Word{Word: "pies", Language: "pl"})
+	createTranslation(t, store, models.Translation{SourceWordID: kot.ID, TargetWordID: cat.ID})
+
+	connection := getWords(t, h, map[string]interface{}{"prefix": "KO"})
+	assert.Equal(t, []string{"kołdra", "kot"}, edgeWords(connection))
+
+	connection = getWords(t, h, map[string]interface{}{"hasTranslations": true, "language": "pl"})
+	assert.Equal(t, []string{"kot"}, edgeWords(connection))
+
+	connection = getWords(t, h, map[string]interface{}{"hasTranslations": false})
+	assert.Equal(t, []string{"kołdra", "pies"}, edgeWords(connection))
+	assert.Equal(t, int64(2), connection.TotalCount)
+}
+
+func TestAddWordUnsupportedLanguage(t *testing.T) {
+	store, h := setupTestStore(t)
+
+	params := graphql.ResolveParams{
+		Args: map[string]interface{}{
+			"word":     "Katze",
+			"language": "de",
+		},
+	}
+
+	_, err := h.AddWord(params)
+	assert.Error(t, err)
+
+	count, err := store.Words().Count(repository.WordFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestAddWordWithGrammaticalAttributes(t *testing.T) {
+	_, h := setupTestStore(t)
+
+	params := graphql.ResolveParams{
+		Args: map[string]interface{}{
+			"word":         "napisać",
+			"language":     "pl",
+			"partOfSpeech": models.PartOfSpeechVerb,
+			"aspect":       models.AspectPerfective,
+			"transitivity": models.TransitivityTransitive,
+		},
+	}
+
+	result, err := h.AddWord(params)
+	assert.NoError(t, err)
+
+	word, ok := result.(models.Word)
+	assert.True(t, ok)
+	assert.Equal(t, models.PartOfSpeechVerb, word.PartOfSpeech)
+	assert.Equal(t, models.AspectPerfective, word.Aspect)
+	assert.Equal(t, models.TransitivityTransitive, word.Transitivity)
+	assert.Equal(t, "", word.Gender)
+}
+
+func TestGetWordsFilteredByGrammaticalAttributes(t *testing.T) {
+	store, h := setupTestStore(t)
+
+	createWord(t, store, models.Word{Word: "kot", Language: "pl", PartOfSpeech: models.PartOfSpeechNoun, Gender: models.GenderMasculineAnimate})
+	createWord(t, store, models.Word{Word: "książka", Language: "pl", PartOfSpeech: models.PartOfSpeechNoun, Gender: models.GenderFeminine})
+	createWord(t, store, models.Word{Word: "czytać", Language: "pl", PartOfSpeech: models.PartOfSpeechVerb, Aspect: models.AspectImperfective})
+	createWord(t, store, models.Word{Word: "book", Language: "en", PartOfSpeech: models.PartOfSpeechNoun})
+
+	connection := getWords(t, h, map[string]interface{}{"language": "pl", "partOfSpeech": models.PartOfSpeechNoun})
+	assert.Len(t, connection.Edges, 2)
+
+	connection = getWords(t, h, map[string]interface{}{"gender": models.GenderFeminine})
+	assert.Equal(t, []string{"książka"}, edgeWords(connection))
+}
+
+func TestUpdateWordGrammaticalAttributes(t *testing.T) {
+	store, h := setupTestStore(t)
+
+	createWord(t, store, models.Word{Word: "kot", Language: "pl"})
+
+	// Without newWord only attributes are changed
+	params := graphql.ResolveParams{
+		Args: map[string]interface{}{
+			"oldWord":         "kot",
+			"language":        "pl",
+			"expectedVersion": 1,
+			"partOfSpeech":    models.PartOfSpeechNoun,
+			"gender":          models.GenderMasculineAnimate,
+			"countability":    models.CountabilityCountable,
+		},
+	}
+
+	result, err := h.UpdateWord(params)
+	assert.NoError(t, err)
+
+	updatedWord, ok := result.(models.Word)
+	assert.True(t, ok)
+	assert.Equal(t, "kot", updatedWord.Word)
+	assert.Equal(t, models.PartOfSpeechNoun, updatedWord.PartOfSpeech)
+	assert.Equal(t, models.GenderMasculineAnimate, updatedWord.Gender)
+	assert.Equal(t, models.CountabilityCountable, updatedWord.Countability)
 
-	params := graphql.ResolveParams{}
-	result, err := handlers.GetWords(params)
+	// None clears attribute, other ones stay
+	result, err = h.UpdateWord(graphql.ResolveParams{
+		Args: map[string]interface{}{
+			"oldWord":         "kot",
+			"language":        "pl",
+			"expectedVersion": 2,
+			"gender":          models.AttributeNone,
+		},
+	})
 	assert.NoError(t, err)
 
-	words, ok := result.([]models.Word)
+	updatedWord, ok = result.(models.Word)
 	assert.True(t, ok)
-	assert.Len(t, words, 2)
+	assert.Empty(t, updatedWord.Gender)
+	assert.Equal(t, models.CountabilityCountable, updatedWord.Countability)
 }
 
 func TestUpdateWord(t *testing.T) {
-	setupTestDB(t)
+	store, h := setupTestStore(t)
 
 	// Add word to update
-	utils.DB.Create(&models.Word{Word: "stary", Language: "pl"})
+	createWord(t, store, models.Word{Word: "stary", Language: "pl"})
 
 	params := graphql.ResolveParams{
 		Args: map[string]interface{}{
-			"oldWord":  "stary",
-			"language": "pl",
-			"newWord":  "nowy",
+			"oldWord":         "stary",
+			"language":        "pl",
+			"newWord":         "nowy",
+			"expectedVersion": 1,
 		},
 	}
 
-	result, err := handlers.UpdateWord(params)
+	result, err := h.UpdateWord(params)
 	assert.NoError(t, err)
 
 	updatedWord, ok := result.(models.Word)
 	assert.True(t, ok)
 	assert.Equal(t, "nowy", updatedWord.Word)
+	assert.Equal(t, 2, updatedWord.Version)
+}
+
+func TestUpdateWordWithOutdatedVersion(t *testing.T) {
+	store, h := setupTestStore(t)
+
+	createWord(t, store, models.Word{Word: "stary", Language: "pl"})
+	update := func(newWord string) error {
+		_, err := h.UpdateWord(graphql.ResolveParams{
+			Args: map[string]interface{}{
+				"oldWord":         "stary",
+				"language":        "pl",
+				"newWord":         newWord,
+				"expectedVersion": 1,
+			},
+		})
+		return err
+	}
+	assert.NoError(t, update("stary"))
+
+	// Word was modified after version 1 was read - current version is reported
+	err := update("nowy")
+	assert.Equal(t, apperrors.CodeConflict, apperrors.CodeOf(err))
+	var appErr *apperrors.Error
+	if assert.ErrorAs(t, err, &appErr) {
+		assert.Equal(t, 2, appErr.Extensions()["currentVersion"])
+	}
+}
+
+func TestUpdateWordToExistingWord(t *testing.T) {
+	store, h := setupTestStore(t)
+
+	createWord(t, store, models.Word{Word: "stary", Language: "pl"})
+	createWord(t, store, models.Word{Word: "nowy", Language: "pl"})
+
+	_, err := h.UpdateWord(graphql.ResolveParams{
+		Args: map[string]interface{}{
+			"oldWord":         "stary",
+			"language":        "pl",
+			"newWord":         "nowy",
+			"expectedVersion": 1,
+		},
+	})
+	assert.Equal(t, apperrors.CodeConflict, apperrors.CodeOf(err))
+}
+
+// fieldErrors returns invalid fields reported in validation error
+func fieldErrors(t *testing.T, err error) []apperrors.FieldError {
+	t.Helper()
+
+	var e *apperrors.Error
+	if !errors.As(err, &e) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	assert.Equal(t, apperrors.CodeValidation, e.Code)
+	return e.Fields
+}
+
+func TestAddWordIsNormalized(t *testing.T) {
+	_, h := setupTestStore(t)
+
+	// "ż" written as "z" + combining dot above, padded with spaces
+	result, err := h.AddWord(graphql.ResolveParams{
+		Args: map[string]interface{}{
+			"word":     "  z\u0307ółw ",
+			"language": "pl",
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "żółw", result.(models.Word).Word)
+
+	// The same word typed differently is found
+	result, err = h.GetWordByText(graphql.ResolveParams{
+		Args: map[string]interface{}{"word": "z\u0307ółw", "exact": true},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "żółw", result.(models.Word).Word)
+}
+
+func TestAddWordValidation(t *testing.T) {
+	store, h := setupTestStore(t)
+
+	_, err := h.AddWord(graphql.ResolveParams{
+		Args: map[string]interface{}{
+			"word":      "   ",
+			"language":  "pl",
+			"homograph": 0,
+		},
+	})
+	assert.Equal(t, []apperrors.FieldError{
+		{Field: "word", Message: "cannot be empty"},
+		{Field: "homograph", Message: "must be positive"},
+	}, fieldErrors(t, err))
+
+	// Cyrillic "о" in polish word
+	_, err = h.AddWord(graphql.ResolveParams{
+		Args: map[string]interface{}{
+			"word":     "kоt",
+			"language": "pl",
+		},
+	})
+	assert.Equal(t, []apperrors.FieldError{
+		{Field: "word", Message: "letter 'о' does not belong to Latn script"},
+	}, fieldErrors(t, err))
+
+	_, err = h.AddWord(graphql.ResolveParams{
+		Args: map[string]interface{}{
+			"word":     strings.Repeat("a", 101),
+			"language": "xx",
+		},
+	})
+	assert.Equal(t, []apperrors.FieldError{
+		{Field: "word", Message: "must be at most 100 characters long (is 101)"},
+		{Field: "language", Message: "unsupported language: xx"},
+	}, fieldErrors(t, err))
+
+	count, err := store.Words().Count(repository.WordFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count)
 }
 
 func TestDeleteWord(t *testing.T) {
-	setupTestDB(t)
+	store, h := setupTestStore(t)
 
 	// Add word to delete
-	utils.DB.Create(&models.Word{Word: "usun", Language: "pl"})
+	createWord(t, store, models.Word{Word: "usun", Language: "pl"})
 
 	params := graphql.ResolveParams{
 		Args: map[string]interface{}{
@@ -105,7 +508,7 @@ func TestDeleteWord(t *testing.T) {
 		},
 	}
 
-	result, err := handlers.DeleteWord(params)
+	result, err := h.DeleteWord(params)
 	assert.NoError(t, err)
 
 	deleted, ok := result.(bool)
@@ -113,13 +516,11 @@ func TestDeleteWord(t *testing.T) {
 	assert.True(t, deleted)
 
 	// Confirm deletion
-	var count int64
-	utils.DB.Model(&models.Word{}).Where("word = ?", "usun").Count(&count)
-	assert.Equal(t, int64(0), count)
+	assert.Empty(t, findWords(t, store, "usun"))
 }
 
 func TestAddWordRaceCondition(t *testing.T) {
-	setupTestDB(t)
+	store, h := setupTestStore(t)
 
 	const goroutines = 20
 	var wg sync.WaitGroup
@@ -134,22 +535,98 @@ func TestAddWordRaceCondition(t *testing.T) {
 					"language": "pl",
 				},
 			}
-			_, _ = handlers.AddWord(params)
+			_, _ = h.AddWord(params)
 		}()
 	}
 	wg.Wait()
 
 	// Expect only one entry
-	var count int64
-	utils.DB.Model(&models.Word{}).Where("word = ? AND language = ?", "kot", "pl").Count(&count)
-	assert.Equal(t, int64(1), count)
+	assert.Len(t, findWords(t, store, "kot"), 1)
+}
+
+func TestConcurrentAddWordIsIdempotent(t *testing.T) {
+	_, h := setupTestStore(t)
+
+	const goroutines = 20
+	ids := make([]uint, goroutines)
+	errs := make([]error, goroutines)
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			result, err := h.AddWord(graphql.ResolveParams{
+				Args: map[string]interface{}{
+					"word":     "pies",
+					"language": "pl",
+				},
+			})
+			errs[i] = err
+			if word, ok := result.(models.Word); ok {
+				ids[i] = word.ID
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// Every request succeeds and returns the same word
+	for i := 0; i < goroutines; i++ {
+		assert.NoError(t, errs[i])
+		assert.NotZero(t, ids[i])
+		assert.Equal(t, ids[0], ids[i])
+	}
+}
+
+func TestConcurrentUpdatesOfDifferentFields(t *testing.T) {
+	store, h := setupTestStore(t)
+
+	createWord(t, store, models.Word{Word: "zamek", Language: "pl"})
+
+	// Each request changes other attribute of the same version - only one succeeds, other ones are conflicts
+	attributes := map[string]string{
+		"partOfSpeech": models.PartOfSpeechNoun,
+		"gender":       models.GenderMasculineInanimate,
+		"countability": models.CountabilityCountable,
+	}
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var updated []string
+	for attribute, value := range attributes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := h.UpdateWord(graphql.ResolveParams{
+				Args: map[string]interface{}{
+					"oldWord":         "zamek",
+					"language":        "pl",
+					"expectedVersion": 1,
+					attribute:         value,
+				},
+			})
+			if err != nil {
+				assert.Equal(t, apperrors.CodeConflict, apperrors.CodeOf(err))
+				return
+			}
+			mu.Lock()
+			updated = append(updated, attribute)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	word := findWords(t, store, "zamek")[0]
+	assert.Equal(t, 2, word.Version)
+	if assert.Len(t, updated, 1) {
+		values := map[string]string{"partOfSpeech": word.PartOfSpeech, "gender": word.Gender, "countability": word.Countability}
+		assert.Equal(t, attributes[updated[0]], values[updated[0]])
+	}
 }
 
 func TestUpdateWordRaceCondition(t *testing.T) {
-	setupTestDB(t)
+	store, h := setupTestStore(t)
 
 	// Start with a base word
-	utils.DB.Create(&models.Word{Word: "dom", Language: "pl"})
+	createWord(t, store, models.Word{Word: "dom", Language: "pl"})
 
 	const goroutines = 10
 	var wg sync.WaitGroup
@@ -162,28 +639,28 @@ func TestUpdateWordRaceCondition(t *testing.T) {
 			defer wg.Done()
 			params := graphql.ResolveParams{
 				Args: map[string]interface{}{
-					"oldWord":  "dom",
-					"language": "pl",
-					"newWord":  newWord,
+					"oldWord":         "dom",
+					"language":        "pl",
+					"newWord":         newWord,
+					"expectedVersion": 1,
 				},
 			}
-			_, _ = handlers.UpdateWord(params)
+			_, _ = h.UpdateWord(params)
 		}(newWords[i])
 	}
 	wg.Wait()
 
 	// Should be only one record, but its value is last committed one (non-deterministic)
-	var words []models.Word
-	utils.DB.Find(&words)
+	words, err := store.Words().List(repository.WordFilter{}, repository.SortID, nil, 100)
+	assert.NoError(t, err)
 	assert.Len(t, words, 1)
 	assert.Contains(t, newWords, words[0].Word)
 }
 
 func TestDeleteWordRaceCondition(t *testing.T) {
-	setupTestDB(t)
+	store, h := setupTestStore(t)
 
-	word := models.Word{Word: "usun", Language: "pl"}
-	utils.DB.Create(&word)
+	createWord(t, store, models.Word{Word: "usun", Language: "pl"})
 
 	const goroutines = 10
 	var wg sync.WaitGroup
@@ -198,12 +675,83 @@ func TestDeleteWordRaceCondition(t *testing.T) {
 					"language": "pl",
 				},
 			}
-			_, _ = handlers.DeleteWord(params)
+			_, _ = h.DeleteWord(params)
 		}()
 	}
 	wg.Wait()
 
-	var count int64
-	utils.DB.Model(&models.Word{}).Where("word = ?", "usun").Count(&count)
-	assert.Equal(t, int64(0), count)
+	assert.Empty(t, findWords(t, store, "usun"))
+}
+
+func TestConcurrentAddWordWithEachStore(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store repository.Store) {
+		c := newClient(t, store)
+
+		// Concurrent requests add the word once
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				result := c.do(`mutation { addWord(word: "kot", language: "pl") { id } }`)
+				assert.Empty(t, result.Errors)
+			}()
+		}
+		wg.Wait()
+
+		data := c.data(t, `{ words(language: "pl") { totalCount } }`)
+		assert.Equal(t, map[string]interface{}{"totalCount": 1}, data["words"])
+	})
+}
+
+func TestWordsWithTranslationsWithEachStore(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store repository.Store) {
+		c := newClient(t, store)
+
+		c.data(t, `mutation {
+			kot: addWord(word: "kot", language: "pl") { id }
+			cat: addWord(word: "cat", language: "en") { id }
+			translation: addTranslation(sourceWord: "kot", sourceLanguage: "pl", targetWord: "cat", targetLanguage: "en") { id }
+			example: addExample(word: "kot", language: "pl", example: "Ala ma kota.") { id }
+		}`)
+
+		data := c.data(t, `{ words(language: "pl") { totalCount edges { node { word translations { word } } } } }`)
+		assert.Equal(t, map[string]interface{}{
+			"totalCount": 1,
+			"edges": []interface{}{
+				map[string]interface{}{"node": map[string]interface{}{
+					"word":         "kot",
+					"translations": []interface{}{map[string]interface{}{"word": "cat"}},
+				}},
+			},
+		}, data["words"])
+		data = c.data(t, `{ examplesForWord(word: "kot", language: "pl") { example } }`)
+		assert.Equal(t, []interface{}{map[string]interface{}{"example": "Ala ma kota."}}, data["examplesForWord"])
+	})
+}
+
+func TestClearWordAttributesWithEachStore(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store repository.Store) {
+		c := newClient(t, store)
+		createWord(t, store, models.Word{Word: "kot", Language: "pl"})
+		createWord(t, store, models.Word{Word: "pies", Language: "pl"})
+
+		// NONE clears grammatical attribute, other ones stay
+		data := c.data(t, `mutation {
+			set: updateWord(oldWord: "pies", language: "pl", gender: MASCULINE_ANIMATE, countability: COUNTABLE, expectedVersion: 1) { gender countability }
+			clear: updateWord(oldWord: "pies", language: "pl", gender: NONE, expectedVersion: 2) { gender countability }
+		}`)
+		assert.Equal(t, map[string]interface{}{"gender": "MASCULINE_ANIMATE", "countability": "COUNTABLE"}, data["set"])
+		assert.Equal(t, map[string]interface{}{"gender": nil, "countability": "COUNTABLE"}, data["clear"])
+
+		// NONE filters words without the attribute
+		data = c.data(t, `{
+			withoutGender: words(language: "pl", gender: NONE) { totalCount }
+			withoutCountability: words(language: "pl", countability: NONE) { edges { node { word } } }
+		}`)
+		assert.Equal(t, map[string]interface{}{"totalCount": 2}, data["withoutGender"])
+		assert.Equal(t, map[string]interface{}{
+			"edges": []interface{}{map[string]interface{}{"node": map[string]interface{}{"word": "kot"}}},
+		}, data["withoutCountability"])
+	})
 }
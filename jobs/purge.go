@@ -0,0 +1,60 @@
+// Package jobs contains background jobs of the application
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/tdawidzi/dictionary_app/repository"
+)
+
+// PurgeResult - numbers of entries deleted permanently by Purge
+type PurgeResult struct {
+	Words        int64
+	Translations int64
+	Examples     int64
+}
+
+// Purge permanently deletes words, translations and examples of given dictionaries (any, if none is given)
+// moved to trash before given time
+func Purge(store repository.Store, before time.Time, dictionaries []uint) (PurgeResult, error) {
+	var result PurgeResult
+	err := store.Transaction(func(tx repository.Store) error {
+		var err error
+		if result.Examples, err = tx.Examples().Purge(before, dictionaries); err != nil {
+			return fmt.Errorf("failed to purge examples: %w", err)
+		}
+		if result.Translations, err = tx.Translations().Purge(before, dictionaries); err != nil {
+			return fmt.Errorf("failed to purge translations: %w", err)
+		}
+		if result.Words, err = tx.Words().Purge(before, dictionaries); err != nil {
+			return fmt.Errorf("failed to purge words: %w", err)
+		}
+		return nil
+	})
+	return result, err
+}
+
+// StartPurge purges entries deleted more than retention ago - at once and then every interval, until ctx is done
+func StartPurge(ctx context.Context, store repository.Store, retention, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			result, err := Purge(store, time.Now().Add(-retention), nil)
+			if err != nil {
+				log.Printf("Error while purging trash: %v", err)
+			} else if result != (PurgeResult{}) {
+				log.Printf("Purged from trash: %d words, %d translations, %d examples", result.Words, result.Translations, result.Examples)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
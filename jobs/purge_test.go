@@ -0,0 +1,41 @@
+package jobs_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tdawidzi/dictionary_app/jobs"
+	"github.com/tdawidzi/dictionary_app/models"
+	"github.com/tdawidzi/dictionary_app/repository"
+	"github.com/tdawidzi/dictionary_app/utils"
+)
+
+func TestPurge(t *testing.T) {
+	store := repository.NewMemoryStore(utils.DefaultLanguages...)
+	kot := models.Word{Word: "kot", Language: "pl"}
+	cat := models.Word{Word: "cat", Language: "en"}
+	for _, word := range []*models.Word{&kot, &cat} {
+		_, err := store.Words().Create(word)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, store.Translations().Create(&models.Translation{SourceWordID: kot.ID, TargetWordID: cat.ID}))
+	example := models.Example{WordID: cat.ID, Example: "The cat sleeps."}
+	_, err := store.Examples().Create(&example)
+	assert.NoError(t, err)
+	assert.NoError(t, store.Examples().Delete(example.ID))
+	assert.NoError(t, store.Words().Delete(kot.ID))
+
+	// Entries deleted later than given time stay in trash
+	result, err := jobs.Purge(store, time.Now().Add(-time.Hour), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, jobs.PurgeResult{}, result)
+
+	result, err = jobs.Purge(store, time.Now().Add(time.Second), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, jobs.PurgeResult{Words: 1, Translations: 1, Examples: 1}, result)
+	words, _ := store.Words().Deleted(nil, 10)
+	assert.Empty(t, words)
+	words, _ = store.Words().Find(repository.WordLookup{Word: "cat"})
+	assert.Len(t, words, 1)
+}
@@ -2,17 +2,19 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
+	"os"
+	"time"
 
+	"github.com/tdawidzi/dictionary_app/auth"
 	"github.com/tdawidzi/dictionary_app/config"
+	"github.com/tdawidzi/dictionary_app/handlers"
+	"github.com/tdawidzi/dictionary_app/jobs"
 	"github.com/tdawidzi/dictionary_app/schema"
+	"github.com/tdawidzi/dictionary_app/server"
 	"github.com/tdawidzi/dictionary_app/utils"
-
-	"github.com/graphql-go/graphql"
 )
 
 func main() {
@@ -22,64 +24,80 @@ func main() {
 		log.Fatalf("Error while loading configuration: %v", err)
 	}
 
-	// Connect do database
-	err = utils.ConnectDB(cfg)
-	if err != nil {
-		log.Fatalf("Error while connecting do database: %v", err)
+	// Schema migrations command, e.g. "dictionary_app migrate status"
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := migrate(cfg, os.Args[2:]); err != nil {
+			log.Fatalf("Error while migrating database: %v", err)
+		}
+		return
 	}
 
-	// Close DB at the end
-	sqlDB, err := utils.DB.DB()
-	if err != nil {
-		log.Fatalf("Error while loading db instance: %v", err)
+	// API keys command, e.g. "dictionary_app apikey create importer"
+	if len(os.Args) > 1 && os.Args[1] == "apikey" {
+		if err := apiKey(cfg, os.Args[2:]); err != nil {
+			log.Fatalf("Error while managing API keys: %v", err)
+		}
+		return
 	}
-	defer sqlDB.Close()
 
-	// GraphQL handler for queries
-	http.HandleFunc("/graphql", func(w http.ResponseWriter, r *http.Request) {
-		// GraphQL parsing
-		var requestBody struct {
-			Query string `json:"query"`
+	for _, admin := range cfg.Admins {
+		if !auth.ValidID(admin) {
+			log.Fatalf("Invalid ADMINS entry %q: expected apikey:<name> or jwt:<issuer>/<subject>", admin)
 		}
+	}
 
-		body, err := io.ReadAll(r.Body)
-		if err != nil {
-			http.Error(w, "Error reading query", http.StatusBadRequest)
-			return
-		}
-		err = json.Unmarshal(body, &requestBody)
-		if err != nil {
-			http.Error(w, "Error JSON parsing", http.StatusBadRequest)
-			return
-		}
+	// Tokens signed with configured keys are accepted besides API keys
+	verifier, err := jwtVerifier(cfg)
+	if err != nil {
+		log.Fatalf("Error while loading JWT keys: %v", err)
+	}
 
-		// GraphQL query execution
-		params := graphql.Params{
-			Schema:        *schema.Schema,
-			Context:       context.Background(),
-			RequestString: requestBody.Query,
-		}
-		result := graphql.Do(params)
+	// Connect do database (or in-memory storage)
+	store, closeStore, err := utils.OpenStore(cfg)
+	if err != nil {
+		log.Fatalf("Error while connecting do database: %v", err)
+	}
 
-		// GraphqL Error handling
-		if result.HasErrors() {
-			http.Error(w, fmt.Sprintf("GraphQL Error: %v", result.Errors), http.StatusInternalServerError)
-			return
-		}
+	// Close DB at the end
+	defer closeStore()
 
-		// Convert to JSON and return
-		response, err := json.Marshal(result)
-		if err != nil {
-			http.Error(w, "Output serialization Error", http.StatusInternalServerError)
-			return
-		}
+	// Memory storage gets API keys from configuration
+	if err := seedAPIKeys(cfg, store); err != nil {
+		log.Fatalf("Error while adding API keys: %v", err)
+	}
+
+	// Entries deleted long ago are purged from trash every hour
+	if cfg.Purge_After_Days > 0 {
+		jobs.StartPurge(context.Background(), store, time.Duration(cfg.Purge_After_Days)*24*time.Hour, time.Hour)
+	}
+
+	// Resolvers use storage through repositories
+	s, err := schema.New(handlers.New(store))
+	if err != nil {
+		log.Fatalf("Error while building schema: %v", err)
+	}
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		w.Write(response)
-	})
+	// GraphQL handler for queries
+	http.Handle("/graphql", server.Handler(s, auth.New(store, verifier, cfg.Admins...)))
 
 	// Server startup
 	fmt.Println("Server listening on: http://localhost:8080/graphql")
 	log.Fatal(http.ListenAndServe(":8080", nil))
 }
+
+// jwtVerifier creates verifier of tokens signed with keys from configuration, nil if no key is configured
+func jwtVerifier(cfg *config.Config) (*auth.JWTVerifier, error) {
+	keys := auth.JWTKeys{
+		HS256Secret: []byte(cfg.JWT_HS256_Secret),
+		Issuer:      cfg.JWT_Issuer,
+		Audience:    cfg.JWT_Audience,
+	}
+	if cfg.JWT_RS256_Public_Key != "" {
+		key, err := os.ReadFile(cfg.JWT_RS256_Public_Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read RS256 public key: %w", err)
+		}
+		keys.RS256PublicKey = key
+	}
+	return auth.NewJWTVerifier(keys)
+}
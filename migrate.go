@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/tdawidzi/dictionary_app/config"
+	"github.com/tdawidzi/dictionary_app/utils"
+)
+
+const migrateUsage = "usage: dictionary_app migrate up|down [steps]|status"
+
+// migrate runs "migrate" command - applies (up), reverts (down, last migration by default) or lists migrations
+func migrate(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	db, err := utils.OpenDB(cfg)
+	if err != nil {
+		return err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to load db instance: %w", err)
+	}
+	defer sqlDB.Close()
+
+	m, err := utils.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := m.Up()
+		for _, migration := range applied {
+			fmt.Printf("Applied %s\n", migration)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("Database is up to date")
+		}
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps: %s", args[1])
+			}
+		}
+		reverted, err := m.Down(steps)
+		for _, migration := range reverted {
+			fmt.Printf("Reverted %s\n", migration)
+		}
+		return err
+	case "status":
+		statuses, err := m.Status()
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.AppliedAt != nil {
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%s\t%s\n", status.Migration, state)
+		}
+		return nil
+	}
+	return errors.New(migrateUsage)
+}
@@ -0,0 +1,90 @@
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+)
+
+// Versioned schema migrations, embedded in binary. Every migration is a pair of files
+// <version>_<name>.up.sql and <version>_<name>.down.sql in directory of database dialect (postgres, sqlite).
+// Applied migrations must not be edited - changes of schema go to new migrations.
+
+//go:embed postgres/*.sql sqlite/*.sql
+var files embed.FS
+
+// Migration - one version of schema
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string // SQL changing schema to this version
+	Down    string // SQL reverting the change, empty when migration cannot be reverted
+}
+
+// String gives file name of migration, without direction
+func (m Migration) String() string {
+	return fmt.Sprintf("%04d_%s", m.Version, m.Name)
+}
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Load returns migrations of database dialect ordered by version
+func Load(dialect string) ([]Migration, error) {
+	entries, err := fs.ReadDir(files, dialect)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for database %s: %w", dialect, err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name: %s", entry.Name())
+		}
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		sql, err := fs.ReadFile(files, dialect+"/"+entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migrations %s and %s have the same version", migration, entry.Name())
+		}
+		if match[3] == "up" {
+			migration.Up = string(sql)
+		} else {
+			migration.Down = string(sql)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, version := range slices.Sorted(maps.Keys(byVersion)) {
+		if byVersion[version].Up == "" {
+			return nil, fmt.Errorf("migration %s has no up file", byVersion[version])
+		}
+		migrations = append(migrations, *byVersion[version])
+	}
+	return migrations, nil
+}
+
+// Find returns migration of database dialect with given version
+func Find(dialect string, version int64) (Migration, error) {
+	migrations, err := Load(dialect)
+	if err != nil {
+		return Migration{}, err
+	}
+	for _, migration := range migrations {
+		if migration.Version == version {
+			return migration, nil
+		}
+	}
+	return Migration{}, fmt.Errorf("no migration %d for database %s", version, dialect)
+}
@@ -0,0 +1,128 @@
+package migrations_test
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tdawidzi/dictionary_app/config"
+	"github.com/tdawidzi/dictionary_app/migrations"
+	"github.com/tdawidzi/dictionary_app/models"
+	"github.com/tdawidzi/dictionary_app/utils"
+	"gorm.io/gorm"
+)
+
+func openSQLite(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := utils.OpenDB(&config.Config{
+		DB_Driver: config.DriverSQLite,
+		DB_Path:   filepath.Join(t.TempDir(), "dictionary.db"),
+	})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	sqlDB, _ := db.DB()
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+func TestLoad(t *testing.T) {
+	for _, dialect := range []string{"postgres", "sqlite"} {
+		loaded, err := migrations.Load(dialect)
+		assert.NoError(t, err)
+		for i, migration := range loaded {
+			assert.Equal(t, int64(i+1), migration.Version, "versions are consecutive")
+			assert.NotEmpty(t, migration.Down, "%s can be reverted", migration)
+		}
+	}
+
+	_, err := migrations.Load("mysql")
+	assert.Error(t, err)
+}
+
+func TestUpDownStatus(t *testing.T) {
+	db := openSQLite(t)
+	m, err := utils.NewMigrator(db)
+	assert.NoError(t, err)
+
+	statuses, err := m.Status()
+	assert.NoError(t, err)
+	for _, status := range statuses {
+		assert.Nil(t, status.AppliedAt)
+	}
+
+	applied, err := m.Up()
+	assert.NoError(t, err)
+	assert.Len(t, applied, len(statuses))
+	applied, err = m.Up()
+	assert.NoError(t, err)
+	assert.Empty(t, applied)
+
+	// First migration registers default languages
+	var languages []models.Language
+	assert.NoError(t, db.Order("code").Find(&languages).Error)
+	assert.Len(t, languages, 2)
+
+	reverted, err := m.Down(len(statuses))
+	assert.NoError(t, err)
+	assert.Len(t, reverted, len(statuses))
+	assert.False(t, db.Migrator().HasTable("words"))
+
+	statuses, err = m.Status()
+	assert.NoError(t, err)
+	for _, status := range statuses {
+		assert.Nil(t, status.AppliedAt)
+	}
+}
+
+func TestConcurrentUp(t *testing.T) {
+	db := openSQLite(t)
+
+	// Instances of application started together apply every migration once
+	var wg sync.WaitGroup
+	counts := make([]int, 5)
+	for i := range counts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m, err := utils.NewMigrator(db)
+			assert.NoError(t, err)
+			applied, err := m.Up()
+			assert.NoError(t, err)
+			counts[i] = len(applied)
+		}()
+	}
+	wg.Wait()
+
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+	loaded, _ := migrations.Load("sqlite")
+	assert.Equal(t, len(loaded), total)
+}
+
+func TestBaseline(t *testing.T) {
+	// Database created by AutoMigrate, before versioned migrations - without schema_migrations table
+	db := openSQLite(t)
+	first, err := migrations.Find("sqlite", 1)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Exec(first.Up).Error)
+	assert.NoError(t, db.Exec("INSERT INTO words (word, language, search_key) VALUES ('kot', 'pl', 'kot')").Error)
+
+	m, err := utils.NewMigrator(db)
+	assert.NoError(t, err)
+	_, err = m.Up()
+	assert.NoError(t, err)
+
+	statuses, err := m.Status()
+	assert.NoError(t, err)
+	for _, status := range statuses {
+		assert.NotNil(t, status.AppliedAt, "%s is applied", status.Migration)
+	}
+	var count int64
+	db.Model(&models.Word{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
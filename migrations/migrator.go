@@ -0,0 +1,250 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Key of PostgreSQL advisory lock held while migrating
+const lockKey = 4_180_911_019
+
+// appliedMigration - row of schema_migrations table
+type appliedMigration struct {
+	Version   int64
+	Name      string
+	AppliedAt time.Time
+}
+
+func (appliedMigration) TableName() string { return "schema_migrations" }
+
+const createTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version BIGINT PRIMARY KEY,
+	name TEXT NOT NULL,
+	applied_at TIMESTAMP NOT NULL
+)`
+
+// ErrIrreversible - migration has no down file
+var ErrIrreversible = errors.New("migration cannot be reverted")
+
+// Migrator applies and reverts migrations of database
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+
+	// Baseline brings database created before versioned migrations (it has tables, but no schema_migrations)
+	// to schema of first migration. First migration is then recorded as applied, without running it.
+	Baseline func(tx *gorm.DB) error
+}
+
+// Status of migration - migrations applied in database, but unknown to application, have only version and name
+type Status struct {
+	Migration
+	AppliedAt *time.Time // nil when migration is pending
+}
+
+// New creates migrator with migrations of database dialect
+func New(db *gorm.DB) (*Migrator, error) {
+	migrations, err := Load(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Up applies all pending migrations, every one in own transaction. Returns applied migrations.
+func (m *Migrator) Up() ([]Migration, error) {
+	var applied []Migration
+	err := m.locked(func(db *gorm.DB) error {
+		if err := m.prepare(db); err != nil {
+			return err
+		}
+		versions, err := m.applied(db)
+		if err != nil {
+			return err
+		}
+		for _, row := range versions {
+			if _, ok := m.find(row.Version); !ok {
+				return fmt.Errorf("database has migration %04d_%s unknown to this version of application", row.Version, row.Name)
+			}
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := versions[migration.Version]; ok {
+				continue
+			}
+			ran, err := m.run(db, migration, true)
+			if err != nil {
+				return err
+			}
+			if ran {
+				applied = append(applied, migration)
+			}
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts given number of last applied migrations. Returns reverted migrations.
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	var reverted []Migration
+	err := m.locked(func(db *gorm.DB) error {
+		if !db.Migrator().HasTable(&appliedMigration{}) {
+			return nil
+		}
+		var rows []appliedMigration
+		if err := db.Order("version DESC").Limit(steps).Find(&rows).Error; err != nil {
+			return fmt.Errorf("failed to read applied migrations: %w", err)
+		}
+
+		for _, row := range rows {
+			migration, ok := m.find(row.Version)
+			if !ok {
+				return fmt.Errorf("migration %04d_%s is unknown to this version of application", row.Version, row.Name)
+			}
+			if migration.Down == "" {
+				return fmt.Errorf("%s: %w", migration, ErrIrreversible)
+			}
+			ran, err := m.run(db, migration, false)
+			if err != nil {
+				return err
+			}
+			if ran {
+				reverted = append(reverted, migration)
+			}
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status lists all migrations ordered by version
+func (m *Migrator) Status() ([]Status, error) {
+	versions := make(map[int64]appliedMigration)
+	if m.db.Migrator().HasTable(&appliedMigration{}) {
+		var err error
+		if versions, err = m.applied(m.db); err != nil {
+			return nil, err
+		}
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Migration: migration}
+		if row, ok := versions[migration.Version]; ok {
+			status.AppliedAt = &row.AppliedAt
+			delete(versions, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	// Applied by newer version of application
+	for _, row := range versions {
+		statuses = append(statuses, Status{
+			Migration: Migration{Version: row.Version, Name: row.Name},
+			AppliedAt: &row.AppliedAt,
+		})
+	}
+	return statuses, nil
+}
+
+// locked runs fn on single connection holding advisory lock, so instances of application
+// started together do not migrate at the same time (PostgreSQL).
+// SQLite has no advisory locks - its transactions take write lock at start (see utils.ConnectDB),
+// and every migration checks in its transaction if it was not applied in the meantime.
+func (m *Migrator) locked(fn func(db *gorm.DB) error) error {
+	if m.db.Dialector.Name() != "postgres" {
+		return fn(m.db)
+	}
+	return m.db.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", lockKey).Error; err != nil {
+			return fmt.Errorf("failed to lock migrations: %w", err)
+		}
+		defer conn.Exec("SELECT pg_advisory_unlock(?)", lockKey)
+		return fn(conn)
+	})
+}
+
+// prepare creates schema_migrations table, database created before versioned migrations gets baseline
+func (m *Migrator) prepare(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if tx.Migrator().HasTable(&appliedMigration{}) {
+			return nil
+		}
+		legacy := tx.Migrator().HasTable("words")
+		if err := tx.Exec(createTable).Error; err != nil {
+			return fmt.Errorf("failed to create schema_migrations table: %w", err)
+		}
+		if !legacy || m.Baseline == nil || len(m.migrations) == 0 {
+			return nil
+		}
+
+		if err := m.Baseline(tx); err != nil {
+			return fmt.Errorf("failed to migrate database to baseline: %w", err)
+		}
+		return tx.Create(&appliedMigration{
+			Version:   m.migrations[0].Version,
+			Name:      m.migrations[0].Name,
+			AppliedAt: time.Now(),
+		}).Error
+	})
+}
+
+// applied reads applied migrations by version
+func (m *Migrator) applied(db *gorm.DB) (map[int64]appliedMigration, error) {
+	var rows []appliedMigration
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	versions := make(map[int64]appliedMigration, len(rows))
+	for _, row := range rows {
+		versions[row.Version] = row
+	}
+	return versions, nil
+}
+
+// run applies (up) or reverts migration in transaction.
+// Returns false when other instance of application did it first.
+func (m *Migrator) run(db *gorm.DB, migration Migration, up bool) (bool, error) {
+	ran := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&appliedMigration{}).Where("version = ?", migration.Version).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to read applied migrations: %w", err)
+		}
+		if (count > 0) == up {
+			return nil
+		}
+
+		if up {
+			if err := tx.Exec(migration.Up).Error; err != nil {
+				return fmt.Errorf("migration %s failed: %w", migration, err)
+			}
+			err := tx.Create(&appliedMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+			if err != nil {
+				return fmt.Errorf("failed to record migration %s: %w", migration, err)
+			}
+		} else {
+			if err := tx.Exec(migration.Down).Error; err != nil {
+				return fmt.Errorf("reverting migration %s failed: %w", migration, err)
+			}
+			if err := tx.Where("version = ?", migration.Version).Delete(&appliedMigration{}).Error; err != nil {
+				return fmt.Errorf("failed to record migration %s: %w", migration, err)
+			}
+		}
+		ran = true
+		return nil
+	})
+	return ran, err
+}
+
+func (m *Migrator) find(version int64) (Migration, bool) {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
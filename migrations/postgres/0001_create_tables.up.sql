@@ -0,0 +1,115 @@
+-- Schema of dictionary as created by AutoMigrate of models before versioned migrations were introduced
+CREATE TABLE languages (
+	code varchar(3),
+	name text NOT NULL,
+	script text NOT NULL DEFAULT 'Latn',
+	direction text NOT NULL DEFAULT 'ltr',
+	search_config text NOT NULL DEFAULT 'simple',
+	PRIMARY KEY (code),
+	CONSTRAINT chk_languages_direction CHECK (direction IN ('ltr', 'rtl'))
+);
+
+-- Postgres has no polish stemmer built in
+INSERT INTO languages (code, name, script, direction, search_config) VALUES
+	('pl', 'Polish', 'Latn', 'ltr', 'simple'),
+	('en', 'English', 'Latn', 'ltr', 'english');
+
+CREATE TABLE words (
+	id bigserial,
+	word text NOT NULL,
+	language varchar(3) NOT NULL,
+	homograph bigint NOT NULL DEFAULT 1,
+	search_key text NOT NULL DEFAULT '',
+	sort_key bytea,
+	part_of_speech text NOT NULL DEFAULT '',
+	gender text NOT NULL DEFAULT '',
+	aspect text NOT NULL DEFAULT '',
+	countability text NOT NULL DEFAULT '',
+	transitivity text NOT NULL DEFAULT '',
+	PRIMARY KEY (id),
+	CONSTRAINT fk_words_lang FOREIGN KEY (language) REFERENCES languages(code) ON DELETE RESTRICT ON UPDATE CASCADE,
+	CONSTRAINT chk_words_homograph CHECK (homograph > 0),
+	CONSTRAINT chk_words_part_of_speech CHECK (part_of_speech IN ('', 'noun', 'verb', 'adjective', 'adverb', 'pronoun', 'numeral', 'preposition', 'conjunction', 'particle', 'interjection', 'determiner')),
+	CONSTRAINT chk_words_gender CHECK (gender IN ('', 'masculine', 'masculine_personal', 'masculine_animate', 'masculine_inanimate', 'feminine', 'neuter')),
+	CONSTRAINT chk_words_aspect CHECK (aspect IN ('', 'perfective', 'imperfective', 'biaspectual')),
+	CONSTRAINT chk_words_countability CHECK (countability IN ('', 'countable', 'uncountable', 'plurale_tantum')),
+	CONSTRAINT chk_words_transitivity CHECK (transitivity IN ('', 'transitive', 'intransitive', 'ambitransitive'))
+);
+CREATE INDEX idx_words_language ON words (language);
+CREATE UNIQUE INDEX word_language_homograph ON words (word, language, homograph);
+CREATE INDEX idx_words_sort_key_id ON words (sort_key, id);
+CREATE INDEX idx_words_part_of_speech ON words (part_of_speech);
+CREATE INDEX idx_words_search_key ON words (search_key);
+-- Prefix index for autocomplete (plain btree index does not serve LIKE 'prefix%' in non-C collations)
+CREATE INDEX idx_words_search_key_prefix ON words (search_key text_pattern_ops);
+
+CREATE TABLE word_forms (
+	id bigserial,
+	word_id bigint NOT NULL,
+	form text NOT NULL,
+	search_key text NOT NULL DEFAULT '',
+	grammatical_case text NOT NULL DEFAULT '',
+	number text NOT NULL DEFAULT '',
+	person text NOT NULL DEFAULT '',
+	tense text NOT NULL DEFAULT '',
+	mood text NOT NULL DEFAULT '',
+	aspect text NOT NULL DEFAULT '',
+	gender text NOT NULL DEFAULT '',
+	degree text NOT NULL DEFAULT '',
+	PRIMARY KEY (id),
+	CONSTRAINT fk_word_forms_word FOREIGN KEY (word_id) REFERENCES words(id) ON DELETE CASCADE,
+	CONSTRAINT chk_word_forms_grammatical_case CHECK (grammatical_case IN ('', 'nominative', 'genitive', 'dative', 'accusative', 'instrumental', 'locative', 'vocative')),
+	CONSTRAINT chk_word_forms_number CHECK (number IN ('', 'singular', 'plural')),
+	CONSTRAINT chk_word_forms_person CHECK (person IN ('', 'first', 'second', 'third')),
+	CONSTRAINT chk_word_forms_tense CHECK (tense IN ('', 'present', 'past', 'future')),
+	CONSTRAINT chk_word_forms_mood CHECK (mood IN ('', 'indicative', 'imperative', 'conditional')),
+	CONSTRAINT chk_word_forms_aspect CHECK (aspect IN ('', 'perfective', 'imperfective', 'biaspectual')),
+	CONSTRAINT chk_word_forms_gender CHECK (gender IN ('', 'masculine', 'masculine_personal', 'masculine_animate', 'masculine_inanimate', 'feminine', 'neuter', 'non_masculine_personal')),
+	CONSTRAINT chk_word_forms_degree CHECK (degree IN ('', 'positive', 'comparative', 'superlative'))
+);
+CREATE UNIQUE INDEX word_form_attributes ON word_forms (word_id, form, grammatical_case, number, person, tense, mood, aspect, gender, degree);
+CREATE INDEX idx_word_forms_search_key ON word_forms (search_key);
+CREATE INDEX idx_word_forms_form ON word_forms (form);
+
+CREATE TABLE senses (
+	id bigserial,
+	word_id bigint NOT NULL,
+	ordinal bigint NOT NULL,
+	definition text NOT NULL,
+	domain text NOT NULL DEFAULT '',
+	PRIMARY KEY (id),
+	CONSTRAINT fk_senses_word FOREIGN KEY (word_id) REFERENCES words(id) ON DELETE CASCADE,
+	CONSTRAINT chk_senses_ordinal CHECK (ordinal > 0)
+);
+CREATE UNIQUE INDEX wordid_ordinal ON senses (word_id, ordinal);
+
+CREATE TABLE translations (
+	id bigserial,
+	source_word_id bigint NOT NULL,
+	target_word_id bigint NOT NULL,
+	source_sense_id bigint,
+	target_sense_id bigint,
+	PRIMARY KEY (id),
+	CONSTRAINT fk_translations_source_word FOREIGN KEY (source_word_id) REFERENCES words(id) ON DELETE CASCADE,
+	CONSTRAINT fk_translations_target_word FOREIGN KEY (target_word_id) REFERENCES words(id) ON DELETE CASCADE,
+	CONSTRAINT fk_translations_source_sense FOREIGN KEY (source_sense_id) REFERENCES senses(id) ON DELETE SET NULL,
+	CONSTRAINT fk_translations_target_sense FOREIGN KEY (target_sense_id) REFERENCES senses(id) ON DELETE SET NULL
+);
+CREATE UNIQUE INDEX translation_pair ON translations (source_word_id, target_word_id);
+CREATE INDEX idx_translations_source_word_id ON translations (source_word_id);
+CREATE INDEX idx_translations_target_word_id ON translations (target_word_id);
+CREATE INDEX idx_translations_source_sense_id ON translations (source_sense_id);
+CREATE INDEX idx_translations_target_sense_id ON translations (target_sense_id);
+
+CREATE TABLE examples (
+	id bigserial,
+	word_id bigint NOT NULL,
+	example text NOT NULL,
+	sense_id bigint,
+	PRIMARY KEY (id),
+	CONSTRAINT fk_examples_word FOREIGN KEY (word_id) REFERENCES words(id) ON DELETE CASCADE,
+	CONSTRAINT fk_examples_sense FOREIGN KEY (sense_id) REFERENCES senses(id) ON DELETE SET NULL,
+	CONSTRAINT uni_examples_example UNIQUE (example)
+);
+CREATE UNIQUE INDEX wordid_example ON examples (word_id, example);
+CREATE INDEX idx_examples_sense_id ON examples (sense_id);
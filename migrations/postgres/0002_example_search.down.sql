@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS examples_search_vector ON examples;
+DROP FUNCTION IF EXISTS examples_search_vector();
+DROP INDEX IF EXISTS idx_examples_search_vector;
+ALTER TABLE examples DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,19 @@
+-- Full-text search vector of examples, with GIN index.
+-- Vector is built by trigger with text search configuration of example's word language.
+ALTER TABLE examples ADD COLUMN IF NOT EXISTS search_vector tsvector;
+CREATE INDEX IF NOT EXISTS idx_examples_search_vector ON examples USING gin (search_vector);
+
+CREATE OR REPLACE FUNCTION examples_search_vector() RETURNS trigger AS $$
+BEGIN
+	NEW.search_vector := to_tsvector(
+		COALESCE((SELECT l.search_config FROM words w JOIN languages l ON l.code = w.language WHERE w.id = NEW.word_id), 'simple')::regconfig,
+		NEW.example);
+	RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS examples_search_vector ON examples;
+CREATE TRIGGER examples_search_vector BEFORE INSERT OR UPDATE OF example, word_id ON examples FOR EACH ROW EXECUTE FUNCTION examples_search_vector();
+
+-- Examples created before search was enabled
+UPDATE examples SET example = example WHERE search_vector IS NULL;
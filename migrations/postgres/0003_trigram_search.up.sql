@@ -0,0 +1,10 @@
+-- Trigram index on normalized words, used for "did you mean" suggestions.
+-- Creating pg_trgm extension requires sufficient privileges - suggestions work without trigram index too, only slower.
+DO $$
+BEGIN
+	CREATE EXTENSION IF NOT EXISTS pg_trgm;
+	CREATE INDEX IF NOT EXISTS idx_words_search_key_trgm ON words USING gist (search_key gist_trgm_ops);
+EXCEPTION WHEN insufficient_privilege OR undefined_file THEN
+	RAISE WARNING 'trigram search not available: %', SQLERRM;
+END
+$$;
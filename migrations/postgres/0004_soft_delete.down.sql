@@ -0,0 +1,21 @@
+-- Entries in trash are deleted permanently, they could break restored unique constraints
+DELETE FROM examples WHERE deleted_at IS NOT NULL;
+DELETE FROM translations WHERE deleted_at IS NOT NULL;
+DELETE FROM words WHERE deleted_at IS NOT NULL;
+
+DROP INDEX idx_examples_example;
+ALTER TABLE examples ADD CONSTRAINT uni_examples_example UNIQUE (example);
+DROP INDEX wordid_example;
+CREATE UNIQUE INDEX wordid_example ON examples (word_id, example);
+DROP INDEX idx_examples_deleted_at;
+ALTER TABLE examples DROP COLUMN created_at, DROP COLUMN updated_at, DROP COLUMN deleted_at;
+
+DROP INDEX translation_pair;
+CREATE UNIQUE INDEX translation_pair ON translations (source_word_id, target_word_id);
+DROP INDEX idx_translations_deleted_at;
+ALTER TABLE translations DROP COLUMN created_at, DROP COLUMN updated_at, DROP COLUMN deleted_at;
+
+DROP INDEX word_language_homograph;
+CREATE UNIQUE INDEX word_language_homograph ON words (word, language, homograph);
+DROP INDEX idx_words_deleted_at;
+ALTER TABLE words DROP COLUMN created_at, DROP COLUMN updated_at, DROP COLUMN deleted_at;
@@ -0,0 +1,29 @@
+-- Timestamps of words, translations and examples, deleted rows stay in trash (deleted_at is set)
+-- until they are restored or purged. Unique indexes apply only to rows not deleted.
+ALTER TABLE words
+	ADD COLUMN created_at timestamptz NOT NULL DEFAULT now(),
+	ADD COLUMN updated_at timestamptz NOT NULL DEFAULT now(),
+	ADD COLUMN deleted_at timestamptz;
+CREATE INDEX idx_words_deleted_at ON words (deleted_at);
+DROP INDEX word_language_homograph;
+CREATE UNIQUE INDEX word_language_homograph ON words (word, language, homograph) WHERE deleted_at IS NULL;
+
+ALTER TABLE translations
+	ADD COLUMN created_at timestamptz NOT NULL DEFAULT now(),
+	ADD COLUMN updated_at timestamptz NOT NULL DEFAULT now(),
+	ADD COLUMN deleted_at timestamptz;
+CREATE INDEX idx_translations_deleted_at ON translations (deleted_at);
+DROP INDEX translation_pair;
+CREATE UNIQUE INDEX translation_pair ON translations (source_word_id, target_word_id) WHERE deleted_at IS NULL;
+
+ALTER TABLE examples
+	ADD COLUMN created_at timestamptz NOT NULL DEFAULT now(),
+	ADD COLUMN updated_at timestamptz NOT NULL DEFAULT now(),
+	ADD COLUMN deleted_at timestamptz;
+CREATE INDEX idx_examples_deleted_at ON examples (deleted_at);
+DROP INDEX wordid_example;
+CREATE UNIQUE INDEX wordid_example ON examples (word_id, example) WHERE deleted_at IS NULL;
+-- Databases created by older versions of GORM have unique index idx_examples_example instead of the constraint
+ALTER TABLE examples DROP CONSTRAINT IF EXISTS uni_examples_example;
+DROP INDEX IF EXISTS idx_examples_example;
+CREATE UNIQUE INDEX idx_examples_example ON examples (example) WHERE deleted_at IS NULL;
@@ -0,0 +1,19 @@
+-- Append-only log of changes of dictionary. It has no foreign keys - history stays when entities are purged.
+CREATE TABLE audit_log (
+	id bigserial,
+	entity text NOT NULL,
+	entity_id bigint NOT NULL,
+	word_id bigint,
+	target_word_id bigint,
+	operation text NOT NULL,
+	before text NOT NULL DEFAULT '',
+	after text NOT NULL DEFAULT '',
+	actor text NOT NULL,
+	revert_of bigint,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	PRIMARY KEY (id)
+);
+CREATE INDEX idx_audit_log_word_id ON audit_log (word_id);
+CREATE INDEX idx_audit_log_target_word_id ON audit_log (target_word_id);
+CREATE INDEX idx_audit_log_actor ON audit_log (actor);
+CREATE INDEX idx_audit_log_created_at ON audit_log (created_at);
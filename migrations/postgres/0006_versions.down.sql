@@ -0,0 +1,4 @@
+ALTER TABLE examples DROP COLUMN version;
+ALTER TABLE translations DROP COLUMN version;
+ALTER TABLE senses DROP COLUMN version;
+ALTER TABLE words DROP COLUMN version;
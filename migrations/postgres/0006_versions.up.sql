@@ -0,0 +1,5 @@
+-- Version of rows, incremented by every update - updates based on outdated version are rejected
+ALTER TABLE words ADD COLUMN version bigint NOT NULL DEFAULT 1;
+ALTER TABLE senses ADD COLUMN version bigint NOT NULL DEFAULT 1;
+ALTER TABLE translations ADD COLUMN version bigint NOT NULL DEFAULT 1;
+ALTER TABLE examples ADD COLUMN version bigint NOT NULL DEFAULT 1;
@@ -0,0 +1,10 @@
+-- Keys of clients of the API, stored hashed
+CREATE TABLE api_keys (
+	id bigserial,
+	name text NOT NULL,
+	hash text NOT NULL,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX idx_api_keys_name ON api_keys (name);
+CREATE UNIQUE INDEX idx_api_keys_hash ON api_keys (hash);
@@ -0,0 +1,11 @@
+-- Roles of principals (API keys, subjects of JWTs)
+CREATE TABLE users (
+	id bigserial,
+	name text NOT NULL,
+	role text NOT NULL,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	updated_at timestamptz NOT NULL DEFAULT now(),
+	PRIMARY KEY (id),
+	CONSTRAINT chk_users_role CHECK (role IN ('viewer', 'editor', 'admin'))
+);
+CREATE UNIQUE INDEX idx_users_name ON users (name);
@@ -0,0 +1,15 @@
+-- Entries of other dictionaries are deleted, they could break restored unique constraints
+DELETE FROM words WHERE dictionary_id <> 1;
+
+ALTER TABLE translations DROP COLUMN dictionary_id;
+
+DROP INDEX idx_examples_example;
+CREATE UNIQUE INDEX idx_examples_example ON examples (example) WHERE deleted_at IS NULL;
+ALTER TABLE examples DROP COLUMN dictionary_id;
+DROP INDEX idx_words_id_dictionary;
+
+DROP INDEX word_language_homograph;
+CREATE UNIQUE INDEX word_language_homograph ON words (word, language, homograph) WHERE deleted_at IS NULL;
+ALTER TABLE words DROP COLUMN dictionary_id;
+
+DROP TABLE dictionaries;
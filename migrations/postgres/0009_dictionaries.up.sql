@@ -0,0 +1,34 @@
+-- Independent dictionaries - words are unique within dictionary, and so is text of examples (copied dictionary of word).
+-- Existing entries belong to the default dictionary.
+CREATE TABLE dictionaries (
+	id bigserial,
+	name text NOT NULL,
+	description text NOT NULL DEFAULT '',
+	created_at timestamptz NOT NULL DEFAULT now(),
+	PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX idx_dictionaries_name ON dictionaries (name);
+INSERT INTO dictionaries (id, name) VALUES (1, 'default');
+SELECT setval('dictionaries_id_seq', 1);
+
+ALTER TABLE words ADD COLUMN dictionary_id bigint NOT NULL DEFAULT 1
+	CONSTRAINT fk_words_dictionary REFERENCES dictionaries(id) ON DELETE RESTRICT;
+DROP INDEX word_language_homograph;
+CREATE UNIQUE INDEX word_language_homograph ON words (dictionary_id, word, language, homograph) WHERE deleted_at IS NULL;
+
+-- Examples copy dictionary of their word (for the unique index) - the composite key keeps the copy consistent
+CREATE UNIQUE INDEX idx_words_id_dictionary ON words (id, dictionary_id);
+ALTER TABLE examples ADD COLUMN dictionary_id bigint NOT NULL DEFAULT 1
+	CONSTRAINT fk_examples_dictionary REFERENCES dictionaries(id) ON DELETE CASCADE;
+ALTER TABLE examples ADD CONSTRAINT fk_examples_word_dictionary FOREIGN KEY (word_id, dictionary_id)
+	REFERENCES words (id, dictionary_id) ON UPDATE CASCADE ON DELETE CASCADE;
+DROP INDEX idx_examples_example;
+CREATE UNIQUE INDEX idx_examples_example ON examples (dictionary_id, example) WHERE deleted_at IS NULL;
+
+-- Translations copy dictionary of their words - composite keys to both words keep them in the same dictionary
+ALTER TABLE translations ADD COLUMN dictionary_id bigint NOT NULL DEFAULT 1
+	CONSTRAINT fk_translations_dictionary REFERENCES dictionaries(id) ON DELETE CASCADE;
+ALTER TABLE translations ADD CONSTRAINT fk_translations_source_word_dictionary FOREIGN KEY (source_word_id, dictionary_id)
+	REFERENCES words (id, dictionary_id) ON UPDATE CASCADE ON DELETE CASCADE;
+ALTER TABLE translations ADD CONSTRAINT fk_translations_target_word_dictionary FOREIGN KEY (target_word_id, dictionary_id)
+	REFERENCES words (id, dictionary_id) ON UPDATE CASCADE ON DELETE CASCADE;
@@ -0,0 +1,6 @@
+DROP TABLE examples;
+DROP TABLE translations;
+DROP TABLE senses;
+DROP TABLE word_forms;
+DROP TABLE words;
+DROP TABLE languages;
@@ -0,0 +1,2 @@
+-- PostgreSQL only (full-text search of examples) - SQLite has no counterpart, migration keeps versions of both databases the same
+SELECT 1;
@@ -0,0 +1,2 @@
+-- PostgreSQL only (trigram index of words) - SQLite has no counterpart, migration keeps versions of both databases the same
+SELECT 1;
@@ -0,0 +1,33 @@
+-- Entries in trash are deleted permanently, they could break restored unique constraints
+DELETE FROM examples WHERE deleted_at IS NOT NULL;
+DELETE FROM translations WHERE deleted_at IS NOT NULL;
+DELETE FROM words WHERE deleted_at IS NOT NULL;
+
+CREATE TABLE examples_old (
+	id integer PRIMARY KEY AUTOINCREMENT,
+	word_id integer NOT NULL,
+	example text NOT NULL,
+	sense_id integer,
+	CONSTRAINT fk_examples_word FOREIGN KEY (word_id) REFERENCES words(id) ON DELETE CASCADE,
+	CONSTRAINT fk_examples_sense FOREIGN KEY (sense_id) REFERENCES senses(id) ON DELETE SET NULL,
+	CONSTRAINT uni_examples_example UNIQUE (example)
+);
+INSERT INTO examples_old (id, word_id, example, sense_id) SELECT id, word_id, example, sense_id FROM examples;
+DROP TABLE examples;
+ALTER TABLE examples_old RENAME TO examples;
+CREATE UNIQUE INDEX wordid_example ON examples (word_id, example);
+CREATE INDEX idx_examples_sense_id ON examples (sense_id);
+
+DROP INDEX translation_pair;
+CREATE UNIQUE INDEX translation_pair ON translations (source_word_id, target_word_id);
+DROP INDEX idx_translations_deleted_at;
+ALTER TABLE translations DROP COLUMN created_at;
+ALTER TABLE translations DROP COLUMN updated_at;
+ALTER TABLE translations DROP COLUMN deleted_at;
+
+DROP INDEX word_language_homograph;
+CREATE UNIQUE INDEX word_language_homograph ON words (word, language, homograph);
+DROP INDEX idx_words_deleted_at;
+ALTER TABLE words DROP COLUMN created_at;
+ALTER TABLE words DROP COLUMN updated_at;
+ALTER TABLE words DROP COLUMN deleted_at;
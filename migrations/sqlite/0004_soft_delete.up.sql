@@ -0,0 +1,39 @@
+-- Timestamps of words, translations and examples, deleted rows stay in trash (deleted_at is set)
+-- until they are restored or purged. Unique indexes apply only to rows not deleted.
+-- SQLite cannot add column with non-constant default - existing rows get current time by update.
+ALTER TABLE words ADD COLUMN created_at datetime;
+ALTER TABLE words ADD COLUMN updated_at datetime;
+ALTER TABLE words ADD COLUMN deleted_at datetime;
+UPDATE words SET created_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP;
+CREATE INDEX idx_words_deleted_at ON words (deleted_at);
+DROP INDEX word_language_homograph;
+CREATE UNIQUE INDEX word_language_homograph ON words (word, language, homograph) WHERE deleted_at IS NULL;
+
+ALTER TABLE translations ADD COLUMN created_at datetime;
+ALTER TABLE translations ADD COLUMN updated_at datetime;
+ALTER TABLE translations ADD COLUMN deleted_at datetime;
+UPDATE translations SET created_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP;
+CREATE INDEX idx_translations_deleted_at ON translations (deleted_at);
+DROP INDEX translation_pair;
+CREATE UNIQUE INDEX translation_pair ON translations (source_word_id, target_word_id) WHERE deleted_at IS NULL;
+
+-- Unique constraint of example text cannot be dropped - table is rebuilt
+CREATE TABLE examples_new (
+	id integer PRIMARY KEY AUTOINCREMENT,
+	word_id integer NOT NULL,
+	example text NOT NULL,
+	sense_id integer,
+	created_at datetime,
+	updated_at datetime,
+	deleted_at datetime,
+	CONSTRAINT fk_examples_word FOREIGN KEY (word_id) REFERENCES words(id) ON DELETE CASCADE,
+	CONSTRAINT fk_examples_sense FOREIGN KEY (sense_id) REFERENCES senses(id) ON DELETE SET NULL
+);
+INSERT INTO examples_new (id, word_id, example, sense_id, created_at, updated_at)
+	SELECT id, word_id, example, sense_id, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP FROM examples;
+DROP TABLE examples;
+ALTER TABLE examples_new RENAME TO examples;
+CREATE UNIQUE INDEX wordid_example ON examples (word_id, example) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX idx_examples_example ON examples (example) WHERE deleted_at IS NULL;
+CREATE INDEX idx_examples_sense_id ON examples (sense_id);
+CREATE INDEX idx_examples_deleted_at ON examples (deleted_at);
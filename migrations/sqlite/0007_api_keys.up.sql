@@ -0,0 +1,9 @@
+-- Keys of clients of the API, stored hashed
+CREATE TABLE api_keys (
+	id integer PRIMARY KEY AUTOINCREMENT,
+	name text NOT NULL,
+	hash text NOT NULL,
+	created_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX idx_api_keys_name ON api_keys (name);
+CREATE UNIQUE INDEX idx_api_keys_hash ON api_keys (hash);
@@ -0,0 +1,10 @@
+-- Roles of principals (API keys, subjects of JWTs)
+CREATE TABLE users (
+	id integer PRIMARY KEY AUTOINCREMENT,
+	name text NOT NULL,
+	role text NOT NULL,
+	created_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	CONSTRAINT chk_users_role CHECK (role IN ('viewer', 'editor', 'admin'))
+);
+CREATE UNIQUE INDEX idx_users_name ON users (name);
@@ -0,0 +1,22 @@
+-- Entries of other dictionaries are deleted, they could break restored unique constraints
+DELETE FROM words WHERE dictionary_id <> 1;
+
+DROP TRIGGER fk_translations_word_dictionary_update;
+DROP TRIGGER fk_translations_dictionary_update;
+DROP TRIGGER fk_translations_dictionary_insert;
+ALTER TABLE translations DROP COLUMN dictionary_id;
+
+DROP INDEX idx_examples_example;
+CREATE UNIQUE INDEX idx_examples_example ON examples (example) WHERE deleted_at IS NULL;
+DROP TRIGGER fk_examples_word_dictionary_update;
+DROP TRIGGER fk_examples_dictionary_update;
+DROP TRIGGER fk_examples_dictionary_insert;
+ALTER TABLE examples DROP COLUMN dictionary_id;
+
+DROP INDEX word_language_homograph;
+CREATE UNIQUE INDEX word_language_homograph ON words (word, language, homograph) WHERE deleted_at IS NULL;
+DROP TRIGGER fk_words_dictionary_update;
+DROP TRIGGER fk_words_dictionary_insert;
+ALTER TABLE words DROP COLUMN dictionary_id;
+
+DROP TABLE dictionaries;
@@ -0,0 +1,49 @@
+-- Independent dictionaries - words are unique within dictionary, and so is text of examples (copied dictionary of word).
+-- Existing entries belong to the default dictionary.
+CREATE TABLE dictionaries (
+	id integer PRIMARY KEY AUTOINCREMENT,
+	name text NOT NULL,
+	description text NOT NULL DEFAULT '',
+	created_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX idx_dictionaries_name ON dictionaries (name);
+INSERT INTO dictionaries (id, name) VALUES (1, 'default');
+
+-- SQLite cannot add column with foreign key and non-null default - the key is checked by triggers
+-- (dictionaries are never deleted)
+ALTER TABLE words ADD COLUMN dictionary_id integer NOT NULL DEFAULT 1;
+CREATE TRIGGER fk_words_dictionary_insert BEFORE INSERT ON words
+	WHEN NOT EXISTS (SELECT 1 FROM dictionaries WHERE id = NEW.dictionary_id)
+	BEGIN SELECT RAISE(ABORT, 'FOREIGN KEY constraint failed'); END;
+CREATE TRIGGER fk_words_dictionary_update BEFORE UPDATE OF dictionary_id ON words
+	WHEN NOT EXISTS (SELECT 1 FROM dictionaries WHERE id = NEW.dictionary_id)
+	BEGIN SELECT RAISE(ABORT, 'FOREIGN KEY constraint failed'); END;
+DROP INDEX word_language_homograph;
+CREATE UNIQUE INDEX word_language_homograph ON words (dictionary_id, word, language, homograph) WHERE deleted_at IS NULL;
+
+-- Examples copy dictionary of their word (for the unique index) - triggers keep the copy consistent
+ALTER TABLE examples ADD COLUMN dictionary_id integer NOT NULL DEFAULT 1;
+CREATE TRIGGER fk_examples_dictionary_insert BEFORE INSERT ON examples
+	WHEN NEW.dictionary_id IS NOT (SELECT dictionary_id FROM words WHERE id = NEW.word_id)
+	BEGIN SELECT RAISE(ABORT, 'FOREIGN KEY constraint failed'); END;
+CREATE TRIGGER fk_examples_dictionary_update BEFORE UPDATE OF word_id, dictionary_id ON examples
+	WHEN NEW.dictionary_id IS NOT (SELECT dictionary_id FROM words WHERE id = NEW.word_id)
+	BEGIN SELECT RAISE(ABORT, 'FOREIGN KEY constraint failed'); END;
+CREATE TRIGGER fk_examples_word_dictionary_update AFTER UPDATE OF dictionary_id ON words
+	BEGIN UPDATE examples SET dictionary_id = NEW.dictionary_id WHERE word_id = NEW.id; END;
+DROP INDEX idx_examples_example;
+CREATE UNIQUE INDEX idx_examples_example ON examples (dictionary_id, example) WHERE deleted_at IS NULL;
+
+-- Translations copy dictionary of their words - triggers keep both words in the same dictionary
+-- (moving a word with translations to another dictionary fails)
+ALTER TABLE translations ADD COLUMN dictionary_id integer NOT NULL DEFAULT 1;
+CREATE TRIGGER fk_translations_dictionary_insert BEFORE INSERT ON translations
+	WHEN NEW.dictionary_id IS NOT (SELECT dictionary_id FROM words WHERE id = NEW.source_word_id)
+		OR NEW.dictionary_id IS NOT (SELECT dictionary_id FROM words WHERE id = NEW.target_word_id)
+	BEGIN SELECT RAISE(ABORT, 'FOREIGN KEY constraint failed'); END;
+CREATE TRIGGER fk_translations_dictionary_update BEFORE UPDATE OF source_word_id, target_word_id, dictionary_id ON translations
+	WHEN NEW.dictionary_id IS NOT (SELECT dictionary_id FROM words WHERE id = NEW.source_word_id)
+		OR NEW.dictionary_id IS NOT (SELECT dictionary_id FROM words WHERE id = NEW.target_word_id)
+	BEGIN SELECT RAISE(ABORT, 'FOREIGN KEY constraint failed'); END;
+CREATE TRIGGER fk_translations_word_dictionary_update AFTER UPDATE OF dictionary_id ON words
+	BEGIN UPDATE translations SET dictionary_id = NEW.dictionary_id WHERE source_word_id = NEW.id OR target_word_id = NEW.id; END;
@@ -0,0 +1,136 @@
+package models
+
+// Grammatical attributes of words. Empty value means "not specified".
+// Allowed values are also enforced by check constraints on Word model.
+
+// Parts of speech
+const (
+	PartOfSpeechNoun         = "noun"
+	PartOfSpeechVerb         = "verb"
+	PartOfSpeechAdjective    = "adjective"
+	PartOfSpeechAdverb       = "adverb"
+	PartOfSpeechPronoun      = "pronoun"
+	PartOfSpeechNumeral      = "numeral"
+	PartOfSpeechPreposition  = "preposition"
+	PartOfSpeechConjunction  = "conjunction"
+	PartOfSpeechParticle     = "particle"
+	PartOfSpeechInterjection = "interjection"
+	PartOfSpeechDeterminer   = "determiner"
+)
+
+// Grammatical genders - polish masculine gender is split by animacy
+const (
+	GenderMasculine          = "masculine"
+	GenderMasculinePersonal  = "masculine_personal"
+	GenderMasculineAnimate   = "masculine_animate"
+	GenderMasculineInanimate = "masculine_inanimate"
+	GenderFeminine           = "feminine"
+	GenderNeuter             = "neuter"
+)
+
+// Verb aspects
+const (
+	AspectPerfective   = "perfective"
+	AspectImperfective = "imperfective"
+	AspectBiaspectual  = "biaspectual"
+)
+
+// Noun countability
+const (
+	CountabilityCountable     = "countable"
+	CountabilityUncountable   = "uncountable"
+	CountabilityPluraleTantum = "plurale_tantum" // e.g. "drzwi", "scissors"
+)
+
+// Verb transitivity
+const (
+	TransitivityTransitive     = "transitive"
+	TransitivityIntransitive   = "intransitive"
+	TransitivityAmbitransitive = "ambitransitive"
+)
+
+// AttributeNone - value of attribute argument clearing the attribute of word (stored empty), or filtering words without it
+const AttributeNone = "none"
+
+var PartsOfSpeech = []string{
+	PartOfSpeechNoun, PartOfSpeechVerb, PartOfSpeechAdjective, PartOfSpeechAdverb,
+	PartOfSpeechPronoun, PartOfSpeechNumeral, PartOfSpeechPreposition, PartOfSpeechConjunction,
+	PartOfSpeechParticle, PartOfSpeechInterjection, PartOfSpeechDeterminer,
+}
+
+var Genders = []string{
+	GenderMasculine, GenderMasculinePersonal, GenderMasculineAnimate, GenderMasculineInanimate,
+	GenderFeminine, GenderNeuter,
+}
+
+var Aspects = []string{AspectPerfective, AspectImperfective, AspectBiaspectual}
+
+var Countabilities = []string{CountabilityCountable, CountabilityUncountable, CountabilityPluraleTantum}
+
+var Transitivities = []string{TransitivityTransitive, TransitivityIntransitive, TransitivityAmbitransitive}
+
+// Inflection attributes of word forms
+
+// Grammatical cases (seven polish cases)
+const (
+	CaseNominative   = "nominative"
+	CaseGenitive     = "genitive"
+	CaseDative       = "dative"
+	CaseAccusative   = "accusative"
+	CaseInstrumental = "instrumental"
+	CaseLocative     = "locative"
+	CaseVocative     = "vocative"
+)
+
+// Grammatical numbers
+const (
+	NumberSingular = "singular"
+	NumberPlural   = "plural"
+)
+
+// Grammatical persons
+const (
+	PersonFirst  = "first"
+	PersonSecond = "second"
+	PersonThird  = "third"
+)
+
+// Tenses
+const (
+	TensePresent = "present"
+	TensePast    = "past"
+	TenseFuture  = "future"
+)
+
+// Moods
+const (
+	MoodIndicative  = "indicative"
+	MoodImperative  = "imperative"
+	MoodConditional = "conditional"
+)
+
+// Degrees of adjectives and adverbs
+const (
+	DegreePositive    = "positive"
+	DegreeComparative = "comparative"
+	DegreeSuperlative = "superlative"
+)
+
+// Plural forms of polish adjectives and past tense verbs use "non masculine personal" gender
+const GenderNonMasculinePersonal = "non_masculine_personal"
+
+var Cases = []string{
+	CaseNominative, CaseGenitive, CaseDative, CaseAccusative, CaseInstrumental, CaseLocative, CaseVocative,
+}
+
+var Numbers = []string{NumberSingular, NumberPlural}
+
+var Persons = []string{PersonFirst, PersonSecond, PersonThird}
+
+var Tenses = []string{TensePresent, TensePast, TenseFuture}
+
+var Moods = []string{MoodIndicative, MoodImperative, MoodConditional}
+
+var Degrees = []string{DegreePositive, DegreeComparative, DegreeSuperlative}
+
+var FormGenders = append(append([]string{}, Genders...), GenderNonMasculinePersonal)
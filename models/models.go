@@ -1,25 +1,206 @@
 package models
 
-// Word model
+import (
+	"time"
+
+	"github.com/tdawidzi/dictionary_app/textutil"
+
+	"gorm.io/gorm"
+)
+
+// Language model - registry of languages available in dictionary
+type Language struct {
+	Code         string `gorm:"primaryKey;size:3"`                                        // ISO 639 code
+	Name         string `gorm:"not null"`                                                 // Display name
+	Script       string `gorm:"not null;default:'Latn'"`                                  // ISO 15924 script code
+	Direction    string `gorm:"not null;default:'ltr';check:direction IN ('ltr', 'rtl')"` // Text direction
+	SearchConfig string `gorm:"not null;default:'simple'"`                                // Postgres text search configuration (full-text search of examples)
+}
+
+// Dictionary model - independent set of words with their translations and examples.
+// Words and examples are unique only within their dictionary.
+type Dictionary struct {
+	ID          uint   `gorm:"primaryKey"`
+	Name        string `gorm:"not null;uniqueIndex"`
+	Description string `gorm:"not null;default:''"`
+	CreatedAt   time.Time
+}
+
+// Dictionary created by migrations - entries added before dictionaries were introduced belong to it,
+// requests not naming a dictionary use it
+const (
+	DefaultDictionaryID   uint = 1
+	DefaultDictionaryName      = "default"
+)
+
+// Word model - the same spelling can exist in several languages (and several times in one language - homographs)
+// Deleted words stay in trash (soft delete) until they are restored or purged, unique constraints apply only to words not deleted.
 type Word struct {
-	ID       uint   `gorm:"primaryKey"`
-	Word     string `gorm:"uniqueIndex;not null"`
-	Language string `gorm:"not null;check:language IN ('pl', 'en');index"`
+	ID           uint   `gorm:"primaryKey;index:idx_words_sort_key_id,priority:2"`
+	DictionaryID uint   `gorm:"not null;default:1;uniqueIndex:word_language_homograph,where:deleted_at IS NULL"`                     // unique dictionary - word - language - homograph
+	Word         string `gorm:"not null;uniqueIndex:word_language_homograph,where:deleted_at IS NULL"`                               // unique dictionary - word - language - homograph
+	Language     string `gorm:"not null;index;uniqueIndex:word_language_homograph,where:deleted_at IS NULL"`                         // unique dictionary - word - language - homograph
+	Homograph    int    `gorm:"not null;default:1;check:homograph > 0;uniqueIndex:word_language_homograph,where:deleted_at IS NULL"` // unique dictionary - word - language - homograph
+	SearchKey    string `gorm:"not null;default:'';index"`                                                                           // normalized word (see BeforeSave)
+	SortKey      []byte `gorm:"index:idx_words_sort_key_id,priority:1"`                                                              // polish collation key (see BeforeSave)
+
+	// Grammatical metadata (see grammar.go), empty if not specified
+	PartOfSpeech string `gorm:"not null;default:'';index;check:part_of_speech IN ('', 'noun', 'verb', 'adjective', 'adverb', 'pronoun', 'numeral', 'preposition', 'conjunction', 'particle', 'interjection', 'determiner')"`
+	Gender       string `gorm:"not null;default:'';check:gender IN ('', 'masculine', 'masculine_personal', 'masculine_animate', 'masculine_inanimate', 'feminine', 'neuter')"`
+	Aspect       string `gorm:"not null;default:'';check:aspect IN ('', 'perfective', 'imperfective', 'biaspectual')"`
+	Countability string `gorm:"not null;default:'';check:countability IN ('', 'countable', 'uncountable', 'plurale_tantum')"`
+	Transitivity string `gorm:"not null;default:'';check:transitivity IN ('', 'transitive', 'intransitive', 'ambitransitive')"`
+
+	Version   int `gorm:"not null;default:1"` // incremented by every update (optimistic concurrency control)
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	Lang       Language   `gorm:"foreignKey:Language;references:Code;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Dictionary Dictionary `gorm:"foreignKey:DictionaryID;references:ID;constraint:OnDelete:RESTRICT"`
+}
+
+// BeforeSave keeps search key (lower case, without diacritics) and sort key in sync with word
+func (w *Word) BeforeSave(tx *gorm.DB) error {
+	w.SearchKey = textutil.SearchKey(w.Word)
+	w.SortKey = textutil.SortKey(w.Word)
+	return nil
+}
+
+// Sense model - single meaning of a word (e.g. "zamek" - castle, lock)
+type Sense struct {
+	ID         uint   `gorm:"primaryKey"`
+	WordID     uint   `gorm:"not null; uniqueIndex:wordid_ordinal"`                    // unique sense number within word
+	Ordinal    int    `gorm:"not null; check:ordinal > 0; uniqueIndex:wordid_ordinal"` // unique sense number within word
+	Definition string `gorm:"not null"`
+	Domain     string `gorm:"not null; default:''"` // Domain label, e.g. "architecture", "engineering"
+	Version    int    `gorm:"not null; default:1"`  // incremented by every update
+	Word       Word   `gorm:"foreignKey:WordID;references:ID;constraint:OnDelete:CASCADE"`
+}
+
+// WordForm model - inflected form of a word (e.g. "kota" - genitive singular of "kot")
+// Only attributes relevant for given form are set, other are empty (see grammar.go)
+type WordForm struct {
+	ID        uint   `gorm:"primaryKey"`
+	WordID    uint   `gorm:"not null; uniqueIndex:word_form_attributes"` // unique form within paradigm
+	Form      string `gorm:"not null; index; uniqueIndex:word_form_attributes"`
+	SearchKey string `gorm:"not null; default:''; index"` // normalized form (see BeforeSave)
+	Case      string `gorm:"column:grammatical_case; not null; default:''; uniqueIndex:word_form_attributes; check:grammatical_case IN ('', 'nominative', 'genitive', 'dative', 'accusative', 'instrumental', 'locative', 'vocative')"`
+	Number    string `gorm:"not null; default:''; uniqueIndex:word_form_attributes; check:number IN ('', 'singular', 'plural')"`
+	Person    string `gorm:"not null; default:''; uniqueIndex:word_form_attributes; check:person IN ('', 'first', 'second', 'third')"`
+	Tense     string `gorm:"not null; default:''; uniqueIndex:word_form_attributes; check:tense IN ('', 'present', 'past', 'future')"`
+	Mood      string `gorm:"not null; default:''; uniqueIndex:word_form_attributes; check:mood IN ('', 'indicative', 'imperative', 'conditional')"`
+	Aspect    string `gorm:"not null; default:''; uniqueIndex:word_form_attributes; check:aspect IN ('', 'perfective', 'imperfective', 'biaspectual')"`
+	Gender    string `gorm:"not null; default:''; uniqueIndex:word_form_attributes; check:gender IN ('', 'masculine', 'masculine_personal', 'masculine_animate', 'masculine_inanimate', 'feminine', 'neuter', 'non_masculine_personal')"`
+	Degree    string `gorm:"not null; default:''; uniqueIndex:word_form_attributes; check:degree IN ('', 'positive', 'comparative', 'superlative')"`
+	Word      Word   `gorm:"foreignKey:WordID;references:ID;constraint:OnDelete:CASCADE"`
+}
+
+// BeforeSave keeps search key (lower case, without diacritics) in sync with form
+func (f *WordForm) BeforeSave(tx *gorm.DB) error {
+	f.SearchKey = textutil.SearchKey(f.Form)
+	return nil
 }
 
-// Translation model
+// Translation model - links two words, regardless of their languages
+// Optionally translation can be attached to specific senses of both words
 type Translation struct {
-	ID       uint `gorm:"primaryKey"`
-	WordIDPl uint `gorm:"not null; index; uniqueIndex:pl_en_pair"` // Unique pair
-	WordIDEn uint `gorm:"not null; index; uniqueIndex:pl_en_pair"` // Unique pair
-	WordPl   Word `gorm:"foreignKey:WordIDPl;references:ID;constraint:OnDelete:CASCADE"`
-	WordEn   Word `gorm:"foreignKey:WordIDEn;references:ID;constraint:OnDelete:CASCADE"`
+	ID            uint  `gorm:"primaryKey"`
+	SourceWordID  uint  `gorm:"not null; index; uniqueIndex:translation_pair,where:deleted_at IS NULL"` // Unique pair
+	TargetWordID  uint  `gorm:"not null; index; uniqueIndex:translation_pair,where:deleted_at IS NULL"` // Unique pair
+	DictionaryID  uint  `gorm:"not null; default:1"`                                                    // copy of dictionary of the words (foreign keys keep both words in it)
+	SourceSenseID *uint `gorm:"index"`
+	TargetSenseID *uint `gorm:"index"`
+	Version       int   `gorm:"not null; default:1"` // incremented by every update
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	DeletedAt     gorm.DeletedAt `gorm:"index"` // deleted together with its word, or alone
+	SourceWord    Word           `gorm:"foreignKey:SourceWordID;references:ID;constraint:OnDelete:CASCADE"`
+	TargetWord    Word           `gorm:"foreignKey:TargetWordID;references:ID;constraint:OnDelete:CASCADE"`
+	SourceSense   *Sense         `gorm:"foreignKey:SourceSenseID;references:ID;constraint:OnDelete:SET NULL"`
+	TargetSense   *Sense         `gorm:"foreignKey:TargetSenseID;references:ID;constraint:OnDelete:SET NULL"`
 }
 
-// Example model
+// Example model - optionally attached to specific sense of a word
 type Example struct {
-	ID      uint   `gorm:"primaryKey"`
-	WordID  uint   `gorm:"not null; uniqueIndex:wordid_example"`        // unique example - word pair
-	Example string `gorm:"unique;not null; uniqueIndex:wordid_example"` // unique example - word pair
-	Word    Word   `gorm:"foreignKey:WordID;references:ID;constraint:OnDelete:CASCADE"`
+	ID           uint   `gorm:"primaryKey"`
+	WordID       uint   `gorm:"not null; uniqueIndex:wordid_example,where:deleted_at IS NULL"`                                                            // unique example - word pair
+	DictionaryID uint   `gorm:"not null; default:1; uniqueIndex:idx_examples_example,where:deleted_at IS NULL"`                                           // copy of dictionary of the word (foreign key keeps it consistent), example text is unique within it
+	Example      string `gorm:"not null; uniqueIndex:wordid_example,where:deleted_at IS NULL; uniqueIndex:idx_examples_example,where:deleted_at IS NULL"` // unique example - word pair, unique example text in dictionary
+	SenseID      *uint  `gorm:"index"`
+	Version      int    `gorm:"not null; default:1"` // incremented by every update
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	DeletedAt    gorm.DeletedAt `gorm:"index"` // deleted together with its word, or alone
+	Word         Word           `gorm:"foreignKey:WordID;references:ID;constraint:OnDelete:CASCADE"`
+	Sense        *Sense         `gorm:"foreignKey:SenseID;references:ID;constraint:OnDelete:SET NULL"`
+}
+
+// Entities recorded in audit log
+const (
+	EntityLanguage    = "language"
+	EntityDictionary  = "dictionary"
+	EntityWord        = "word"
+	EntityParadigm    = "paradigm" // all inflected forms of a word, identified by the word
+	EntitySense       = "sense"
+	EntityTranslation = "translation"
+	EntityExample     = "example"
+	EntityUser        = "user" // role of principal (see User)
+)
+
+var AuditEntities = []string{EntityLanguage, EntityDictionary, EntityWord, EntityParadigm, EntitySense, EntityTranslation, EntityExample, EntityUser}
+
+// Operations recorded in audit log
+const (
+	OperationCreate  = "create"
+	OperationUpdate  = "update"
+	OperationDelete  = "delete"
+	OperationRestore = "restore"
+)
+
+var AuditOperations = []string{OperationCreate, OperationUpdate, OperationDelete, OperationRestore}
+
+// AuditEntry model - single change of dictionary, entries are only appended.
+// Entries have no foreign keys - history stays when entities are purged.
+type AuditEntry struct {
+	ID           uint      `gorm:"primaryKey"`
+	Entity       string    `gorm:"not null"`
+	EntityID     uint      `gorm:"not null"` // 0 for languages (identified by code in values)
+	WordID       *uint     `gorm:"index"`    // word the entity belongs to (source word of translation)
+	TargetWordID *uint     `gorm:"index"`    // target word of translation
+	Operation    string    `gorm:"not null"`
+	Before       string    `gorm:"not null; default:''"` // values of entity as JSON, empty when entity did not exist
+	After        string    `gorm:"not null; default:''"` // values of entity as JSON, empty when entity does not exist anymore
+	Actor        string    `gorm:"not null; index"`
+	RevertOf     *uint     // entry reverted by this change
+	CreatedAt    time.Time `gorm:"index"`
+}
+
+func (AuditEntry) TableName() string { return "audit_log" }
+
+// APIKey model - key of client of the API. Only hash of the key is stored, key itself is shown once, when it is created.
+type APIKey struct {
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"not null;uniqueIndex"` // client the key was given to, actor of its changes
+	Hash      string `gorm:"not null;uniqueIndex"` // SHA-256 of the key, hex encoded
+	CreatedAt time.Time
+}
+
+// Roles of users - every role has permissions of the lower ones
+const (
+	RoleViewer = "viewer" // queries only
+	RoleEditor = "editor" // adds and updates words, senses, paradigms, translations and examples
+	RoleAdmin  = "admin"  // deletes, restores and purges entries, manages languages and users
+)
+
+// Roles from the lowest one
+var Roles = []string{RoleViewer, RoleEditor, RoleAdmin}
+
+// User model - role of principal (name of API key or subject of JWT). Principals without user are viewers.
+type User struct {
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"not null;uniqueIndex"`
+	Role      string `gorm:"not null;check:role IN ('viewer', 'editor', 'admin')"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
 }
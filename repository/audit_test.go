@@ -0,0 +1,82 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tdawidzi/dictionary_app/models"
+	"github.com/tdawidzi/dictionary_app/repository"
+)
+
+func TestMemoryAudit(t *testing.T) {
+	testAudit(t, newMemoryStore(t))
+}
+
+func TestSQLiteAudit(t *testing.T) {
+	testAudit(t, newSQLiteStore(t))
+}
+
+// testAudit checks audit log and restore of translations - the same in every store
+func testAudit(t *testing.T, store repository.Store) {
+	kot := createWord(t, store, "kot", "pl")
+	cat := createWord(t, store, "cat", "en")
+	start := time.Now().Add(-time.Second)
+
+	entries := []models.AuditEntry{
+		{Entity: models.EntityWord, EntityID: kot.ID, WordID: &kot.ID, Operation: models.OperationCreate, After: `{"word":"kot"}`, Actor: "ala"},
+		{Entity: models.EntityWord, EntityID: cat.ID, WordID: &cat.ID, Operation: models.OperationCreate, After: `{"word":"cat"}`, Actor: "ola"},
+		{Entity: models.EntityTranslation, EntityID: 1, WordID: &kot.ID, TargetWordID: &cat.ID, Operation: models.OperationCreate, Actor: "ala"},
+	}
+	for i := range entries {
+		assert.NoError(t, store.Audit().Append(&entries[i]))
+		assert.NotZero(t, entries[i].ID)
+		assert.False(t, entries[i].CreatedAt.IsZero())
+	}
+
+	entry, err := store.Audit().Get(entries[1].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"word":"cat"}`, entry.After)
+	assert.Equal(t, "", entry.Before)
+	_, err = store.Audit().Get(100)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	// Translation is in history of both words
+	history, err := store.Audit().ForWord(cat.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, history, 2) {
+		assert.Equal(t, entries[1].ID, history[0].ID)
+		assert.Equal(t, models.EntityTranslation, history[1].Entity)
+	}
+
+	log, err := store.Audit().List(repository.AuditFilter{Actor: "ala", Since: start}, 10)
+	assert.NoError(t, err)
+	assert.Len(t, log, 2)
+	log, err = store.Audit().List(repository.AuditFilter{}, 1)
+	assert.NoError(t, err)
+	assert.Len(t, log, 1)
+	log, err = store.Audit().List(repository.AuditFilter{Since: time.Now().Add(time.Hour)}, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, log)
+
+	// Translation deleted alone is restored only when both words are not deleted
+	translation := models.Translation{SourceWordID: kot.ID, TargetWordID: cat.ID}
+	assert.NoError(t, store.Translations().Create(&translation))
+	got, err := store.Translations().Get(translation.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "cat", got.TargetWord.Word)
+	assert.NoError(t, store.Translations().DeleteBetween(kot.ID, cat.ID))
+	_, err = store.Translations().Get(translation.ID)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	assert.NoError(t, store.Words().Delete(cat.ID))
+	_, err = store.Translations().Restore(translation.ID)
+	assert.ErrorIs(t, err, repository.ErrWordDeleted)
+	_, err = store.Words().Restore(cat.ID)
+	assert.NoError(t, err)
+	restored, err := store.Translations().Restore(translation.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "kot", restored.SourceWord.Word)
+	_, err = store.Translations().Restore(translation.ID)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+}
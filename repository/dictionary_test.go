@@ -0,0 +1,107 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tdawidzi/dictionary_app/models"
+	"github.com/tdawidzi/dictionary_app/repository"
+)
+
+func TestMemoryDictionaries(t *testing.T) {
+	testDictionaries(t, newMemoryStore(t))
+}
+
+func TestSQLiteDictionaries(t *testing.T) {
+	testDictionaries(t, newSQLiteStore(t))
+}
+
+// testDictionaries checks that words and examples are unique only within dictionary, translations stay in one, and lookups by dictionaries
+func testDictionaries(t *testing.T, store repository.Store) {
+	dictionaries, err := store.Dictionaries().List()
+	assert.NoError(t, err)
+	if assert.Len(t, dictionaries, 1) {
+		assert.Equal(t, models.DefaultDictionaryID, dictionaries[0].ID)
+		assert.Equal(t, models.DefaultDictionaryName, dictionaries[0].Name)
+	}
+	medical := models.Dictionary{Name: "medical", Description: "Medical terms"}
+	assert.NoError(t, store.Dictionaries().Create(&medical))
+	assert.ErrorIs(t, store.Dictionaries().Create(&models.Dictionary{Name: "medical"}), repository.ErrDuplicate)
+	found, err := store.Dictionaries().Find("medical")
+	assert.NoError(t, err)
+	assert.Equal(t, medical.ID, found.ID)
+	_, err = store.Dictionaries().Find("legal")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	// The same word in both dictionaries
+	kot := createWord(t, store, "kot", "pl")
+	assert.Equal(t, models.DefaultDictionaryID, kot.DictionaryID)
+	medicalKot := models.Word{DictionaryID: medical.ID, Word: "kot", Language: "pl"}
+	created, err := store.Words().Create(&medicalKot)
+	assert.NoError(t, err)
+	assert.True(t, created)
+	again := models.Word{DictionaryID: medical.ID, Word: "kot", Language: "pl"}
+	created, err = store.Words().Create(&again)
+	assert.NoError(t, err)
+	assert.False(t, created)
+
+	// Words only in existing dictionaries
+	_, err = store.Words().Create(&models.Word{DictionaryID: medical.ID + 1, Word: "kot", Language: "pl"})
+	assert.Error(t, err)
+
+	words, err := store.Words().Find(repository.WordLookup{Word: "kot"})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{kot.ID, medicalKot.ID}, wordIDs(words))
+	words, err = store.Words().Find(repository.WordLookup{Word: "kot", Dictionaries: []uint{medical.ID}})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{medicalKot.ID}, wordIDs(words))
+
+	count, err := store.Words().Count(repository.WordFilter{Dictionaries: []uint{models.DefaultDictionaryID}})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	completions, err := store.Words().Complete("ko", "", []uint{medical.ID}, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{medicalKot.ID}, wordIDs(completions))
+	similar, err := store.Words().Similar("kott", "pl", []uint{models.DefaultDictionaryID}, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{kot.ID}, wordIDs(similar))
+
+	// The same example text in both dictionaries, examples get dictionary of their word
+	example := models.Example{WordID: kot.ID, Example: "Ala ma kota."}
+	_, err = store.Examples().Create(&example)
+	assert.NoError(t, err)
+	medicalExample := models.Example{WordID: medicalKot.ID, Example: "Ala ma kota."}
+	created, err = store.Examples().Create(&medicalExample)
+	assert.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, medical.ID, medicalExample.DictionaryID)
+
+	// Translations only between words of the same dictionary, they get its ID
+	cat := createWord(t, store, "cat", "en")
+	assert.Error(t, store.Translations().Create(&models.Translation{SourceWordID: medicalKot.ID, TargetWordID: cat.ID}))
+	medicalCat := models.Word{DictionaryID: medical.ID, Word: "cat", Language: "en"}
+	_, err = store.Words().Create(&medicalCat)
+	assert.NoError(t, err)
+	translation := models.Translation{SourceWordID: medicalKot.ID, TargetWordID: medicalCat.ID}
+	assert.NoError(t, store.Translations().Create(&translation))
+	assert.Equal(t, medical.ID, translation.DictionaryID)
+	translation.TargetWordID = cat.ID
+	assert.Error(t, store.Translations().Save(&translation))
+
+	hits, err := store.Examples().Search("kota", "", []uint{medical.ID}, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, hits, 1) {
+		assert.Equal(t, medicalExample.ID, hits[0].Example.ID)
+	}
+	hits, err = store.Examples().Search("kota", "", nil, 10)
+	assert.NoError(t, err)
+	assert.Len(t, hits, 2)
+}
+
+func wordIDs(words []models.Word) []uint {
+	ids := make([]uint, 0, len(words))
+	for _, w := range words {
+		ids = append(ids, w.ID)
+	}
+	return ids
+}
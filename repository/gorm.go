@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/tdawidzi/dictionary_app/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repositories over GORM (PostgreSQL or SQLite database, see utils.ConnectDB).
+// Features specific to PostgreSQL (full-text and trigram search) are replaced by simpler ones in SQLite.
+
+type gormStore struct {
+	db *gorm.DB
+}
+
+// NewGormStore creates store of given database. Database has to be opened with TranslateError option.
+func NewGormStore(db *gorm.DB) Store {
+	return &gormStore{db: db}
+}
+
+func (s *gormStore) Languages() LanguageRepository       { return gormLanguages{s.db} }
+func (s *gormStore) Dictionaries() DictionaryRepository  { return gormDictionaries{s.db} }
+func (s *gormStore) Words() WordRepository               { return gormWords{s.db} }
+func (s *gormStore) Forms() FormRepository               { return gormForms{s.db} }
+func (s *gormStore) Senses() SenseRepository             { return gormSenses{s.db} }
+func (s *gormStore) Translations() TranslationRepository { return gormTranslations{s.db} }
+func (s *gormStore) Examples() ExampleRepository         { return gormExamples{s.db} }
+func (s *gormStore) Audit() AuditRepository              { return gormAudit{s.db} }
+func (s *gormStore) APIKeys() APIKeyRepository           { return gormAPIKeys{s.db} }
+func (s *gormStore) Users() UserRepository               { return gormUsers{s.db} }
+
+func (s *gormStore) Transaction(fn func(tx Store) error) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&gormStore{db: tx})
+	})
+}
+
+// forUpdate locks selected rows until end of transaction
+var forUpdate = clause.Locking{Strength: "UPDATE"}
+
+// saveVersion writes all columns of row read in given version and increments the version.
+// Row modified after it was read (its version changed) is not written.
+func saveVersion(db *gorm.DB, row interface{}, version *int) error {
+	read := *version
+	*version = read + 1
+	saved := db.Model(row).Where("version = ?", read).Select("*").Omit("id", "created_at", clause.Associations).Updates(row)
+	if saved.Error == nil && saved.RowsAffected == 0 {
+		saved.Error = ErrStaleVersion
+	}
+	if saved.Error != nil {
+		*version = read
+		return translate(saved.Error)
+	}
+	return nil
+}
+
+// inDictionaries limits query to rows of given dictionaries (any, if none is given)
+func inDictionaries(db *gorm.DB, dictionaries []uint) *gorm.DB {
+	if len(dictionaries) == 0 {
+		return db
+	}
+	return db.Where("dictionary_id IN ?", dictionaries)
+}
+
+// isPostgres checks if database is PostgreSQL
+func isPostgres(db *gorm.DB) bool {
+	return db.Dialector.Name() == "postgres"
+}
+
+// translate replaces GORM errors with errors of repository package
+func translate(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return ErrDuplicate
+	}
+	return err
+}
+
+type gormLanguages struct {
+	db *gorm.DB
+}
+
+func (r gormLanguages) List() ([]models.Language, error) {
+	var languages []models.Language
+	err := r.db.Order("code").Find(&languages).Error
+	return languages, err
+}
+
+func (r gormLanguages) Find(code string) (models.Language, error) {
+	var language models.Language
+	err := r.db.Where("code = ?", code).First(&language).Error
+	return language, translate(err)
+}
+
+func (r gormLanguages) Create(language *models.Language) error {
+	return translate(r.db.Create(language).Error)
+}
+
+func (r gormLanguages) SearchConfigs() ([]string, error) {
+	var configs []string
+	err := r.db.Model(&models.Language{}).Distinct().Order("search_config").Pluck("search_config", &configs).Error
+	return configs, err
+}
+
+func (r gormLanguages) SearchConfigExists(config string) (bool, error) {
+	if !isPostgres(r.db) {
+		return slices.Contains(builtinSearchConfigs, config), nil
+	}
+	var count int64
+	err := r.db.Raw("SELECT count(*) FROM pg_ts_config WHERE cfgname = ?", config).Scan(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"github.com/tdawidzi/dictionary_app/models"
+
+	"gorm.io/gorm"
+)
+
+type gormAPIKeys struct {
+	db *gorm.DB
+}
+
+func (r gormAPIKeys) List() ([]models.APIKey, error) {
+	var keys []models.APIKey
+	err := r.db.Order("name").Find(&keys).Error
+	return keys, err
+}
+
+func (r gormAPIKeys) FindByHash(hash string) (models.APIKey, error) {
+	var key models.APIKey
+	err := r.db.Where("hash = ?", hash).First(&key).Error
+	return key, translate(err)
+}
+
+func (r gormAPIKeys) Create(key *models.APIKey) error {
+	return translate(r.db.Create(key).Error)
+}
+
+func (r gormAPIKeys) Delete(name string) error {
+	deleted := r.db.Where("name = ?", name).Delete(&models.APIKey{})
+	if deleted.Error == nil && deleted.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return deleted.Error
+}
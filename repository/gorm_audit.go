@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"github.com/tdawidzi/dictionary_app/models"
+
+	"gorm.io/gorm"
+)
+
+type gormAudit struct {
+	db *gorm.DB
+}
+
+func (r gormAudit) Append(entry *models.AuditEntry) error {
+	return translate(r.db.Create(entry).Error)
+}
+
+func (r gormAudit) Get(id uint) (models.AuditEntry, error) {
+	var entry models.AuditEntry
+	err := r.db.First(&entry, id).Error
+	return entry, translate(err)
+}
+
+func (r gormAudit) ForWord(wordID uint) ([]models.AuditEntry, error) {
+	var entries []models.AuditEntry
+	err := r.db.Where("word_id = ? OR target_word_id = ?", wordID, wordID).Order("id").Find(&entries).Error
+	return entries, err
+}
+
+func (r gormAudit) List(filter AuditFilter, limit int) ([]models.AuditEntry, error) {
+	query := r.db.Order("id").Limit(limit)
+	if !filter.Since.IsZero() {
+		query = query.Where("created_at >= ?", filter.Since.UTC())
+	}
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	var entries []models.AuditEntry
+	err := query.Find(&entries).Error
+	return entries, err
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"github.com/tdawidzi/dictionary_app/models"
+
+	"gorm.io/gorm"
+)
+
+type gormDictionaries struct {
+	db *gorm.DB
+}
+
+func (r gormDictionaries) List() ([]models.Dictionary, error) {
+	var dictionaries []models.Dictionary
+	err := r.db.Order("id").Find(&dictionaries).Error
+	return dictionaries, err
+}
+
+func (r gormDictionaries) Find(name string) (models.Dictionary, error) {
+	var dictionary models.Dictionary
+	err := r.db.Where("name = ?", name).First(&dictionary).Error
+	return dictionary, translate(err)
+}
+
+func (r gormDictionaries) Create(dictionary *models.Dictionary) error {
+	return translate(r.db.Create(dictionary).Error)
+}
@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/tdawidzi/dictionary_app/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ts_headline options - whole sentence is returned, matched terms are wrapped in <b></b>
+const exampleHeadlineOptions = "StartSel=<b>, StopSel=</b>, HighlightAll=true"
+
+type gormExamples struct {
+	db *gorm.DB
+}
+
+func (r gormExamples) ForWord(wordID uint) ([]models.Example, error) {
+	var examples []models.Example
+	err := r.db.Where("word_id = ?", wordID).Find(&examples).Error
+	return examples, err
+}
+
+func (r gormExamples) ForSenses(senseIDs []uint) ([]models.Example, error) {
+	var examples []models.Example
+	err := r.db.Where("sense_id IN ?", senseIDs).Order("id").Find(&examples).Error
+	return examples, err
+}
+
+func (r gormExamples) Get(id uint) (models.Example, error) {
+	var example models.Example
+	err := r.db.Preload("Word").First(&example, id).Error
+	return example, translate(err)
+}
+
+func (r gormExamples) GetForUpdate(id uint) (models.Example, error) {
+	var example models.Example
+	err := r.db.Clauses(forUpdate).First(&example, id).Error
+	return example, translate(err)
+}
+
+func (r gormExamples) Find(wordID uint, text string) (models.Example, error) {
+	var example models.Example
+	err := r.db.Where("example = ? AND word_id = ?", text, wordID).First(&example).Error
+	return example, translate(err)
+}
+
+// copyDictionary sets dictionary of example to the one of its word (required by foreign key)
+func (r gormExamples) copyDictionary(example *models.Example) error {
+	return r.db.Model(&models.Word{}).Select("dictionary_id").Where("id = ?", example.WordID).Scan(&example.DictionaryID).Error
+}
+
+func (r gormExamples) Create(example *models.Example) (bool, error) {
+	if err := r.copyDictionary(example); err != nil {
+		return false, err
+	}
+	created := r.db.Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "word_id"}, {Name: "example"}},
+		TargetWhere: notDeleted,
+		DoNothing:   true,
+	}).Create(example)
+	return created.RowsAffected > 0, translate(created.Error)
+}
+
+func (r gormExamples) Save(example *models.Example) error {
+	if err := r.copyDictionary(example); err != nil {
+		return err
+	}
+	return saveVersion(r.db, example, &example.Version)
+}
+
+func (r gormExamples) Delete(id uint) error {
+	return r.db.Delete(&models.Example{}, id).Error
+}
+
+func (r gormExamples) Restore(id uint) (models.Example, error) {
+	var example models.Example
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Clauses(forUpdate).Where("deleted_at IS NOT NULL").First(&example, id).Error
+		if err != nil {
+			return err
+		}
+		// Word is locked, so it cannot be deleted meanwhile
+		if err := (gormWords{tx}).Lock(example.WordID); err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return ErrWordDeleted
+			}
+			return err
+		}
+		if err := tx.Unscoped().Model(&example).UpdateColumn("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		example.DeletedAt = gorm.DeletedAt{}
+		return tx.Preload("Word").First(&example, id).Error
+	})
+	return example, translate(err)
+}
+
+func (r gormExamples) Deleted(dictionaries []uint, limit int) ([]models.Example, error) {
+	var examples []models.Example
+	err := inDictionaries(r.db.Unscoped(), dictionaries).Preload("Word", withDeletedWords).
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC, id DESC").
+		Limit(limit).
+		Find(&examples).Error
+	return examples, err
+}
+
+func (r gormExamples) Purge(before time.Time, dictionaries []uint) (int64, error) {
+	purged := inDictionaries(r.db.Unscoped(), dictionaries).Where("deleted_at < ?", before.UTC()).Delete(&models.Example{})
+	return purged.RowsAffected, purged.Error
+}
+
+func (r gormExamples) Search(query, language string, dictionaries []uint, limit int) ([]ExampleHit, error) {
+	if !isPostgres(r.db) {
+		return r.match(query, language, dictionaries, limit)
+	}
+
+	// Configurations to search with
+	var configs []string
+	if language != "" {
+		lang, err := gormLanguages(r).Find(language)
+		if err != nil {
+			return nil, err
+		}
+		configs = []string{lang.SearchConfig}
+	} else {
+		var err error
+		if configs, err = gormLanguages(r).SearchConfigs(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Query with constant configuration can use search vector index, so every configuration is searched separately
+	type hit struct {
+		ID          uint
+		Highlighted string
+		Rank        float64
+	}
+	var hits []hit
+	for _, config := range configs {
+		var configHits []hit
+		q := r.db.Table("examples e").
+			Select("e.id, ts_headline(?::regconfig, e.example, websearch_to_tsquery(?::regconfig, ?), ?) AS highlighted, "+
+				"ts_rank(e.search_vector, websearch_to_tsquery(?::regconfig, ?)) AS rank",
+				config, config, query, exampleHeadlineOptions, config, query).
+			Joins("JOIN words w ON w.id = e.word_id").
+			Joins("JOIN languages l ON l.code = w.language").
+			Where("e.search_vector @@ websearch_to_tsquery(?::regconfig, ?)", config, query).
+			Where("e.deleted_at IS NULL").
+			Where("l.search_config = ?", config)
+		if language != "" {
+			q = q.Where("w.language = ?", language)
+		}
+		if len(dictionaries) > 0 {
+			q = q.Where("e.dictionary_id IN ?", dictionaries)
+		}
+		if err := q.Order("rank DESC, e.id").Limit(limit).Scan(&configHits).Error; err != nil {
+			return nil, err
+		}
+		hits = append(hits, configHits...)
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Rank != hits[j].Rank {
+			return hits[i].Rank > hits[j].Rank
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+
+	// Attach examples with their words
+	ids := make([]uint, 0, len(hits))
+	for _, h := range hits {
+		ids = append(ids, h.ID)
+	}
+	var examples []models.Example
+	if err := r.db.Preload("Word").Where("id IN ?", ids).Find(&examples).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.Example, len(examples))
+	for _, e := range examples {
+		byID[e.ID] = e
+	}
+
+	results := make([]ExampleHit, 0, len(hits))
+	for _, h := range hits {
+		if example, ok := byID[h.ID]; ok {
+			results = append(results, ExampleHit{Example: example, Highlighted: h.Highlighted, Rank: h.Rank})
+		}
+	}
+	return results, nil
+}
+
+// match searches examples without full-text search of database (see matchExamples)
+func (r gormExamples) match(query, language string, dictionaries []uint, limit int) ([]ExampleHit, error) {
+	q := r.db.Preload("Word").Order("id")
+	if language != "" {
+		q = q.Where("word_id IN (?)", r.db.Model(&models.Word{}).Select("id").Where("language = ?", language))
+	}
+	if len(dictionaries) > 0 {
+		q = q.Where("dictionary_id IN ?", dictionaries)
+	}
+	var examples []models.Example
+	if err := q.Find(&examples).Error; err != nil {
+		return nil, err
+	}
+	return matchExamples(examples, query, limit), nil
+}
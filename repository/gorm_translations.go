@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/tdawidzi/dictionary_app/models"
+
+	"gorm.io/gorm"
+)
+
+type gormTranslations struct {
+	db *gorm.DB
+}
+
+func (r gormTranslations) ForWords(wordIDs []uint) ([]models.Translation, error) {
+	var translations []models.Translation
+	err := r.db.Preload("SourceWord").Preload("TargetWord").
+		Where("source_word_id IN ? OR target_word_id IN ?", wordIDs, wordIDs).
+		Order("id").
+		Find(&translations).Error
+	return translations, err
+}
+
+func (r gormTranslations) ForSenses(senseIDs []uint) ([]models.Translation, error) {
+	var translations []models.Translation
+	err := r.db.Preload("SourceWord").Preload("TargetWord").
+		Where("source_sense_id IN ? OR target_sense_id IN ?", senseIDs, senseIDs).
+		Order("id").
+		Find(&translations).Error
+	return translations, err
+}
+
+func (r gormTranslations) Get(id uint) (models.Translation, error) {
+	var translation models.Translation
+	err := r.db.Preload("SourceWord").Preload("TargetWord").First(&translation, id).Error
+	return translation, translate(err)
+}
+
+// betweenWords selects translations between two words, regardless of their direction
+func betweenWords(db *gorm.DB, firstID, secondID uint) *gorm.DB {
+	return db.Where("(source_word_id = ? AND target_word_id = ?) OR (source_word_id = ? AND target_word_id = ?)",
+		firstID, secondID, secondID, firstID)
+}
+
+func (r gormTranslations) Find(firstID, secondID uint) (models.Translation, error) {
+	var translation models.Translation
+	err := betweenWords(r.db, firstID, secondID).First(&translation).Error
+	return translation, translate(err)
+}
+
+// copyDictionary sets dictionary of translation to the one of its source word (foreign keys reject target word of another one)
+func (r gormTranslations) copyDictionary(translation *models.Translation) error {
+	return r.db.Model(&models.Word{}).Select("dictionary_id").Where("id = ?", translation.SourceWordID).Scan(&translation.DictionaryID).Error
+}
+
+func (r gormTranslations) Create(translation *models.Translation) error {
+	if err := r.copyDictionary(translation); err != nil {
+		return err
+	}
+	return translate(r.db.Create(translation).Error)
+}
+
+func (r gormTranslations) Save(t *models.Translation) error {
+	if err := r.copyDictionary(t); err != nil {
+		return err
+	}
+	saved := r.db.Model(&models.Translation{}).
+		Where("id = ? AND version = ?", t.ID, t.Version).
+		Updates(map[string]interface{}{
+			"source_word_id":  t.SourceWordID,
+			"target_word_id":  t.TargetWordID,
+			"dictionary_id":   t.DictionaryID,
+			"source_sense_id": t.SourceSenseID,
+			"target_sense_id": t.TargetSenseID,
+			"version":         gorm.Expr("version + 1"),
+		})
+	if saved.Error != nil {
+		return translate(saved.Error)
+	}
+	if saved.RowsAffected == 0 {
+		return ErrStaleVersion
+	}
+	t.Version++
+	return nil
+}
+
+func (r gormTranslations) DeleteBetween(firstID, secondID uint) error {
+	return betweenWords(r.db, firstID, secondID).Delete(&models.Translation{}).Error
+}
+
+func (r gormTranslations) Restore(id uint) (models.Translation, error) {
+	var translation models.Translation
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Clauses(forUpdate).Where("deleted_at IS NOT NULL").First(&translation, id).Error
+		if err != nil {
+			return err
+		}
+		// Words are locked, so they cannot be deleted meanwhile
+		if err := (gormWords{tx}).Lock(translation.SourceWordID, translation.TargetWordID); err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return ErrWordDeleted
+			}
+			return err
+		}
+		if err := tx.Unscoped().Model(&translation).UpdateColumn("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		translation.DeletedAt = gorm.DeletedAt{}
+		return tx.Preload("SourceWord").Preload("TargetWord").First(&translation, id).Error
+	})
+	return translation, translate(err)
+}
+
+// withDeletedWords preloads words of entries in trash - they can be in trash too
+func withDeletedWords(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
+
+func (r gormTranslations) Deleted(dictionaries []uint, limit int) ([]models.Translation, error) {
+	var translations []models.Translation
+	err := inDictionaries(r.db.Unscoped(), dictionaries).Preload("SourceWord", withDeletedWords).Preload("TargetWord", withDeletedWords).
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC, id DESC").
+		Limit(limit).
+		Find(&translations).Error
+	return translations, err
+}
+
+func (r gormTranslations) Purge(before time.Time, dictionaries []uint) (int64, error) {
+	purged := inDictionaries(r.db.Unscoped(), dictionaries).Where("deleted_at < ?", before.UTC()).Delete(&models.Translation{})
+	return purged.RowsAffected, purged.Error
+}
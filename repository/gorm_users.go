@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"github.com/tdawidzi/dictionary_app/models"
+
+	"gorm.io/gorm"
+)
+
+type gormUsers struct {
+	db *gorm.DB
+}
+
+func (r gormUsers) List() ([]models.User, error) {
+	var users []models.User
+	err := r.db.Order("name").Find(&users).Error
+	return users, err
+}
+
+func (r gormUsers) Find(name string) (models.User, error) {
+	var user models.User
+	err := r.db.Where("name = ?", name).First(&user).Error
+	return user, translate(err)
+}
+
+func (r gormUsers) Create(user *models.User) error {
+	return translate(r.db.Create(user).Error)
+}
+
+func (r gormUsers) Save(user *models.User) error {
+	return translate(r.db.Save(user).Error)
+}
+
+func (r gormUsers) Delete(id uint) error {
+	return r.db.Delete(&models.User{}, id).Error
+}
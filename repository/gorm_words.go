@@ -0,0 +1,373 @@
+package repository
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/tdawidzi/dictionary_app/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Escapes LIKE wildcards, so they are matched literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+type gormWords struct {
+	db *gorm.DB
+}
+
+func (r gormWords) Find(lookup WordLookup) ([]models.Word, error) {
+	query := r.db.Model(&models.Word{})
+	switch {
+	case lookup.Word != "":
+		query = query.Where("word = ?", lookup.Word)
+	case lookup.SearchKey != "":
+		query = query.Where("search_key = ?", lookup.SearchKey)
+	case lookup.Form != "":
+		forms := r.db.Model(&models.WordForm{}).Select("word_id").Where("form = ?", lookup.Form)
+		query = query.Where("id IN (?)", forms)
+	case lookup.FormSearchKey != "":
+		forms := r.db.Model(&models.WordForm{}).Select("word_id").Where("search_key = ?", lookup.FormSearchKey)
+		query = query.Where("id IN (?)", forms)
+	default:
+		return nil, nil
+	}
+	if len(lookup.Dictionaries) > 0 {
+		query = query.Where("dictionary_id IN ?", lookup.Dictionaries)
+	}
+	if lookup.Language != "" {
+		query = query.Where("language = ?", lookup.Language)
+	}
+	if lookup.Homograph != 0 {
+		query = query.Where("homograph = ?", lookup.Homograph)
+	}
+
+	var words []models.Word
+	err := query.Order("dictionary_id, language, homograph").Find(&words).Error
+	return words, err
+}
+
+func (r gormWords) GetForUpdate(id uint) (models.Word, error) {
+	var word models.Word
+	err := r.db.Clauses(forUpdate).First(&word, id).Error
+	return word, translate(err)
+}
+
+func (r gormWords) Lock(ids ...uint) error {
+	// Rows are locked in order of ids, so concurrent transactions cannot deadlock.
+	// Key share locks of foreign keys (e.g. inserted translations) are not blocked.
+	var locked []models.Word
+	err := r.db.Clauses(clause.Locking{Strength: "NO KEY UPDATE"}).
+		Select("id").Where("id IN ?", ids).Order("id").Find(&locked).Error
+	if err != nil {
+		return err
+	}
+	if len(locked) < len(slices.Compact(slices.Sorted(slices.Values(ids)))) {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// filtered narrows down words query by filter
+func (r gormWords) filtered(filter WordFilter) *gorm.DB {
+	query := r.db.Model(&models.Word{})
+	if len(filter.Dictionaries) > 0 {
+		query = query.Where("dictionary_id IN ?", filter.Dictionaries)
+	}
+	if filter.Language != "" {
+		query = query.Where("language = ?", filter.Language)
+	}
+	if filter.Prefix != "" {
+		query = query.Where(`search_key LIKE ? ESCAPE '\'`, likeEscaper.Replace(filter.Prefix)+"%")
+	}
+	attributes := map[string]string{
+		"part_of_speech": filter.PartOfSpeech,
+		"gender":         filter.Gender,
+		"aspect":         filter.Aspect,
+		"countability":   filter.Countability,
+		"transitivity":   filter.Transitivity,
+	}
+	for column, value := range attributes {
+		if value == "" {
+			continue
+		}
+		if value == models.AttributeNone {
+			value = ""
+		}
+		query = query.Where(column+" = ?", value)
+	}
+	if filter.HasTranslations != nil {
+		translated := "EXISTS (SELECT 1 FROM translations t WHERE (t.source_word_id = words.id OR t.target_word_id = words.id) AND t.deleted_at IS NULL)"
+		if !*filter.HasTranslations {
+			translated = "NOT " + translated
+		}
+		query = query.Where(translated)
+	}
+	return query
+}
+
+func (r gormWords) List(filter WordFilter, sort string, after *WordPosition, limit int) ([]models.Word, error) {
+	// Keyset pagination - stable when words are added
+	query := r.filtered(filter)
+	switch sort {
+	case SortAlphabetical:
+		if after != nil {
+			query = query.Where("(sort_key, id) > (?, ?)", after.SortKey, after.ID)
+		}
+		query = query.Order("sort_key, id")
+	case SortNewest:
+		if after != nil {
+			query = query.Where("id < ?", after.ID)
+		}
+		query = query.Order("id DESC")
+	case SortID:
+		if after != nil {
+			query = query.Where("id > ?", after.ID)
+		}
+		query = query.Order("id")
+	default:
+		return nil, fmt.Errorf("unsupported sort order: %s", sort)
+	}
+
+	var words []models.Word
+	err := query.Limit(limit).Find(&words).Error
+	return words, err
+}
+
+func (r gormWords) Count(filter WordFilter) (int64, error) {
+	var count int64
+	err := r.filtered(filter).Count(&count).Error
+	return count, err
+}
+
+func (r gormWords) Complete(prefix, language string, dictionaries []uint, limit int) ([]models.Word, error) {
+	query := r.db.Model(&models.Word{})
+	if language != "" {
+		query = query.Where("language = ?", language)
+	}
+	if len(dictionaries) > 0 {
+		query = query.Where("dictionary_id IN ?", dictionaries)
+	}
+
+	// LIKE 'prefix%' is served by prefix index on search_key (text_pattern_ops in PostgreSQL, ordered by ~<~ operator),
+	// so only limit rows are read. Exact match is the first key of the prefix in byte order.
+	// Escape character is given explicitly, SQLite has none by default.
+	order := "search_key, id"
+	if isPostgres(r.db) {
+		order = "search_key USING ~<~, id"
+	}
+	var words []models.Word
+	err := query.
+		Where(`search_key LIKE ? ESCAPE '\'`, likeEscaper.Replace(prefix)+"%").
+		Order(order).
+		Limit(limit).
+		Find(&words).Error
+	return words, err
+}
+
+func (r gormWords) Similar(key, language string, dictionaries []uint, limit int) ([]models.Word, error) {
+	query := r.db.Model(&models.Word{})
+	if language != "" {
+		query = query.Where("language = ?", language)
+	}
+	if len(dictionaries) > 0 {
+		query = query.Where("dictionary_id IN ?", dictionaries)
+	}
+	query = query.Session(&gorm.Session{})
+
+	// Candidates are preselected by trigram distance (pg_trgm), without pg_trgm they are alphabetical neighbours of key
+	trigrams, err := hasTrigrams(r.db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check trigram search: %w", err)
+	}
+	var candidates []models.Word
+	if trigrams {
+		err := query.
+			Order(clause.OrderBy{Expression: clause.Expr{SQL: "search_key <-> ?, id", Vars: []interface{}{key}}}).
+			Limit(limit).
+			Find(&candidates).Error
+		return candidates, err
+	}
+
+	// Both halves are range scans of search_key index, limited to words starting with the same letter
+	first, _ := utf8.DecodeRuneInString(key)
+	query = query.Where(`search_key LIKE ? ESCAPE '\'`, likeEscaper.Replace(string(first))+"%").Session(&gorm.Session{})
+	var before []models.Word
+	err = query.Where("search_key < ?", key).Order("search_key DESC, id DESC").Limit(limit / 2).Find(&before).Error
+	if err != nil {
+		return nil, err
+	}
+	err = query.Where("search_key >= ?", key).Order("search_key, id").Limit(limit - len(before)).Find(&candidates).Error
+	slices.Reverse(before)
+	return append(before, candidates...), err
+}
+
+// hasTrigrams checks if pg_trgm extension is installed - creating it needs privileges, so migrations may skip it
+func hasTrigrams(db *gorm.DB) (bool, error) {
+	if !isPostgres(db) {
+		return false, nil
+	}
+	var installed bool
+	err := db.Raw("SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'pg_trgm')").Scan(&installed).Error
+	return installed, err
+}
+
+// notDeleted is predicate of partial unique indexes - conflict targets have to repeat it
+var notDeleted = clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "deleted_at IS NULL"}}}
+
+func (r gormWords) Create(word *models.Word) (bool, error) {
+	if word.DictionaryID == 0 {
+		word.DictionaryID = models.DefaultDictionaryID
+	}
+	created := r.db.Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "dictionary_id"}, {Name: "word"}, {Name: "language"}, {Name: "homograph"}},
+		TargetWhere: notDeleted,
+		DoNothing:   true,
+	}).Create(word)
+	return created.RowsAffected > 0, translate(created.Error)
+}
+
+func (r gormWords) Save(word *models.Word) error {
+	return saveVersion(r.db, word, &word.Version)
+}
+
+func (r gormWords) Delete(id uint) error {
+	// Word first - concurrent transactions which locked it (e.g. adding translation) finish before its entries are deleted.
+	// All rows get the same time of deletion, so restore can tell which entries were deleted with the word.
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := tx.NowFunc()
+		deleted := tx.Model(&models.Word{}).Where("id = ?", id).UpdateColumn("deleted_at", now)
+		if deleted.Error != nil {
+			return deleted.Error
+		}
+		if deleted.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		err := tx.Model(&models.Translation{}).Where("source_word_id = ? OR target_word_id = ?", id, id).
+			UpdateColumn("deleted_at", now).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.Example{}).Where("word_id = ?", id).UpdateColumn("deleted_at", now).Error
+	})
+}
+
+func (r gormWords) Restore(id uint) (models.Word, error) {
+	var word models.Word
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Clauses(forUpdate).Where("deleted_at IS NOT NULL").First(&word, id).Error
+		if err != nil {
+			return err
+		}
+
+		// Entries deleted together with the word (compared in database - SQLite keeps times as text)
+		deletedWithWord := "deleted_at = (SELECT w.deleted_at FROM words w WHERE w.id = ?)"
+		active := tx.Model(&models.Word{}).Select("id")
+		err = tx.Unscoped().Model(&models.Translation{}).
+			Where(deletedWithWord, id).
+			Where("(source_word_id = ? AND target_word_id IN (?)) OR (target_word_id = ? AND source_word_id IN (?))", id, active, id, active).
+			UpdateColumn("deleted_at", nil).Error
+		if err != nil {
+			return err
+		}
+		usedTexts := tx.Model(&models.Example{}).Select("example").Where("dictionary_id = ?", word.DictionaryID)
+		err = tx.Unscoped().Model(&models.Example{}).
+			Where(deletedWithWord, id).
+			Where("word_id = ? AND example NOT IN (?)", id, usedTexts).
+			UpdateColumn("deleted_at", nil).Error
+		if err != nil {
+			return err
+		}
+
+		word.DeletedAt = gorm.DeletedAt{}
+		return tx.Unscoped().Model(&word).UpdateColumn("deleted_at", nil).Error
+	})
+	return word, translate(err)
+}
+
+func (r gormWords) Deleted(dictionaries []uint, limit int) ([]models.Word, error) {
+	var words []models.Word
+	err := inDictionaries(r.db.Unscoped(), dictionaries).Where("deleted_at IS NOT NULL").Order("deleted_at DESC, id DESC").Limit(limit).Find(&words).Error
+	return words, err
+}
+
+func (r gormWords) Purge(before time.Time, dictionaries []uint) (int64, error) {
+	// Forms, senses and remaining translations and examples are deleted by foreign key cascades.
+	// Times are compared in UTC, like they are stored - SQLite compares them as text.
+	purged := inDictionaries(r.db.Unscoped(), dictionaries).Where("deleted_at < ?", before.UTC()).Delete(&models.Word{})
+	return purged.RowsAffected, purged.Error
+}
+
+type gormForms struct {
+	db *gorm.DB
+}
+
+func (r gormForms) ForWords(wordIDs []uint) ([]models.WordForm, error) {
+	var forms []models.WordForm
+	err := r.db.Where("word_id IN ?", wordIDs).Order("id").Find(&forms).Error
+	return forms, err
+}
+
+func (r gormForms) Create(forms []models.WordForm) error {
+	return translate(r.db.Create(&forms).Error)
+}
+
+func (r gormForms) DeleteForWord(wordID uint) error {
+	return r.db.Where("word_id = ?", wordID).Delete(&models.WordForm{}).Error
+}
+
+type gormSenses struct {
+	db *gorm.DB
+}
+
+func (r gormSenses) ForWords(wordIDs []uint) ([]models.Sense, error) {
+	var senses []models.Sense
+	err := r.db.Where("word_id IN ?", wordIDs).Order("ordinal").Find(&senses).Error
+	return senses, err
+}
+
+// active selects senses of words not deleted
+func (r gormSenses) active() *gorm.DB {
+	return r.db.Where("word_id IN (?)", r.db.Model(&models.Word{}).Select("id"))
+}
+
+func (r gormSenses) Get(id uint) (models.Sense, error) {
+	var sense models.Sense
+	err := r.active().First(&sense, id).Error
+	return sense, translate(err)
+}
+
+func (r gormSenses) GetForUpdate(id uint) (models.Sense, error) {
+	var sense models.Sense
+	err := r.active().Clauses(forUpdate).First(&sense, id).Error
+	return sense, translate(err)
+}
+
+func (r gormSenses) Find(wordID uint, ordinal int) (models.Sense, error) {
+	var sense models.Sense
+	err := r.db.Where("word_id = ? AND ordinal = ?", wordID, ordinal).First(&sense).Error
+	return sense, translate(err)
+}
+
+func (r gormSenses) LastOrdinal(wordID uint) (int, error) {
+	var last int
+	err := r.db.Model(&models.Sense{}).Where("word_id = ?", wordID).
+		Select("COALESCE(MAX(ordinal), 0)").Scan(&last).Error
+	return last, err
+}
+
+func (r gormSenses) Create(sense *models.Sense) error {
+	return translate(r.db.Create(sense).Error)
+}
+
+func (r gormSenses) Save(sense *models.Sense) error {
+	return saveVersion(r.db, sense, &sense.Version)
+}
+
+func (r gormSenses) Delete(id uint) error {
+	return r.db.Delete(&models.Sense{}, id).Error
+}
@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/tdawidzi/dictionary_app/models"
+
+	"gorm.io/gorm"
+)
+
+// Repositories kept in memory - for tests and demos, data is lost when process ends.
+// Uniqueness of rows and cascades of deletes are the same as in database (see models).
+// Deleted words, translations and examples are moved to separate tables (trash).
+
+// memoryData - tables of store, rows by id
+type memoryData struct {
+	languages           map[string]models.Language
+	dictionaries        map[uint]models.Dictionary
+	words               map[uint]models.Word
+	forms               map[uint]models.WordForm
+	senses              map[uint]models.Sense
+	translations        map[uint]models.Translation
+	examples            map[uint]models.Example
+	deletedWords        map[uint]models.Word
+	deletedTranslations map[uint]models.Translation
+	deletedExamples     map[uint]models.Example
+	auditLog            map[uint]models.AuditEntry
+	apiKeys             map[uint]models.APIKey
+	users               map[uint]models.User
+	lastID              map[string]uint // last id given in every table
+}
+
+func (d *memoryData) clone() *memoryData {
+	return &memoryData{
+		languages:           maps.Clone(d.languages),
+		dictionaries:        maps.Clone(d.dictionaries),
+		words:               maps.Clone(d.words),
+		forms:               maps.Clone(d.forms),
+		senses:              maps.Clone(d.senses),
+		translations:        maps.Clone(d.translations),
+		examples:            maps.Clone(d.examples),
+		deletedWords:        maps.Clone(d.deletedWords),
+		deletedTranslations: maps.Clone(d.deletedTranslations),
+		deletedExamples:     maps.Clone(d.deletedExamples),
+		auditLog:            maps.Clone(d.auditLog),
+		apiKeys:             maps.Clone(d.apiKeys),
+		users:               maps.Clone(d.users),
+		lastID:              maps.Clone(d.lastID),
+	}
+}
+
+// nextID gives id of new row in table
+func (d *memoryData) nextID(table string) uint {
+	d.lastID[table]++
+	return d.lastID[table]
+}
+
+type memoryStore struct {
+	mu   *sync.RWMutex
+	data *memoryData
+	inTx bool
+}
+
+// NewMemoryStore creates empty store kept in memory, with given languages registered and the default dictionary
+func NewMemoryStore(languages ...models.Language) Store {
+	data := &memoryData{
+		languages:           make(map[string]models.Language),
+		dictionaries:        make(map[uint]models.Dictionary),
+		words:               make(map[uint]models.Word),
+		forms:               make(map[uint]models.WordForm),
+		senses:              make(map[uint]models.Sense),
+		translations:        make(map[uint]models.Translation),
+		examples:            make(map[uint]models.Example),
+		deletedWords:        make(map[uint]models.Word),
+		deletedTranslations: make(map[uint]models.Translation),
+		deletedExamples:     make(map[uint]models.Example),
+		auditLog:            make(map[uint]models.AuditEntry),
+		apiKeys:             make(map[uint]models.APIKey),
+		users:               make(map[uint]models.User),
+		lastID:              make(map[string]uint),
+	}
+	for _, language := range languages {
+		data.languages[language.Code] = withLanguageDefaults(language)
+	}
+	data.dictionaries[models.DefaultDictionaryID] = models.Dictionary{
+		ID: models.DefaultDictionaryID, Name: models.DefaultDictionaryName, CreatedAt: now(),
+	}
+	data.lastID["dictionaries"] = models.DefaultDictionaryID
+	return &memoryStore{mu: &sync.RWMutex{}, data: data}
+}
+
+func (s *memoryStore) Languages() LanguageRepository       { return memoryLanguages{s} }
+func (s *memoryStore) Dictionaries() DictionaryRepository  { return memoryDictionaries{s} }
+func (s *memoryStore) Words() WordRepository               { return memoryWords{s} }
+func (s *memoryStore) Forms() FormRepository               { return memoryForms{s} }
+func (s *memoryStore) Senses() SenseRepository             { return memorySenses{s} }
+func (s *memoryStore) Translations() TranslationRepository { return memoryTranslations{s} }
+func (s *memoryStore) Examples() ExampleRepository         { return memoryExamples{s} }
+func (s *memoryStore) Audit() AuditRepository              { return memoryAudit{s} }
+func (s *memoryStore) APIKeys() APIKeyRepository           { return memoryAPIKeys{s} }
+func (s *memoryStore) Users() UserRepository               { return memoryUsers{s} }
+
+// Transaction holds the store exclusively until fn ends, so transactions are serialized
+// and locks of rows (GetForUpdate, Lock) are not needed. Changes are made on a copy of data,
+// which replaces data of the store when fn succeeds.
+func (s *memoryStore) Transaction(fn func(tx Store) error) error {
+	if !s.inTx {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+
+	tx := &memoryStore{mu: s.mu, data: s.data.clone(), inTx: true}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	*s.data = *tx.data
+	return nil
+}
+
+// read runs fn with data locked for reading
+func (s *memoryStore) read(fn func(d *memoryData)) {
+	if !s.inTx {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+	}
+	fn(s.data)
+}
+
+// write runs fn with data locked for writing. Writes check all constraints before changing data,
+// so a failed write leaves data unchanged.
+func (s *memoryStore) write(fn func(d *memoryData) error) error {
+	if !s.inTx {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+	return fn(s.data)
+}
+
+// sortedRows returns rows of table ordered by id
+func sortedRows[T any](rows map[uint]T, keep func(T) bool) []T {
+	ids := slices.Sorted(maps.Keys(rows))
+	result := make([]T, 0, len(ids))
+	for _, id := range ids {
+		if keep(rows[id]) {
+			result = append(result, rows[id])
+		}
+	}
+	return result
+}
+
+// deletedRows returns at most limit rows of trash which pass filter, recently deleted first
+func deletedRows[T any](rows map[uint]T, filter func(T) bool, deletedAt func(T) time.Time, limit int) []T {
+	result := sortedRows(rows, filter)
+	slices.Reverse(result)
+	slices.SortStableFunc(result, func(a, b T) int { return deletedAt(b).Compare(deletedAt(a)) })
+	return result[:min(limit, len(result))]
+}
+
+// now gives time of changes - in UTC, like in database (see utils.OpenDB)
+func now() time.Time {
+	return time.Now().UTC()
+}
+
+// deletedNow marks row as deleted now
+func deletedNow() gorm.DeletedAt {
+	return gorm.DeletedAt{Time: now(), Valid: true}
+}
+
+// withLanguageDefaults sets column defaults of language
+func withLanguageDefaults(language models.Language) models.Language {
+	if language.Script == "" {
+		language.Script = "Latn"
+	}
+	if language.Direction == "" {
+		language.Direction = "ltr"
+	}
+	if language.SearchConfig == "" {
+		language.SearchConfig = "simple"
+	}
+	return language
+}
+
+type memoryLanguages struct {
+	s *memoryStore
+}
+
+func (r memoryLanguages) List() ([]models.Language, error) {
+	var languages []models.Language
+	r.s.read(func(d *memoryData) {
+		for _, code := range slices.Sorted(maps.Keys(d.languages)) {
+			languages = append(languages, d.languages[code])
+		}
+	})
+	return languages, nil
+}
+
+func (r memoryLanguages) Find(code string) (models.Language, error) {
+	var language models.Language
+	var ok bool
+	r.s.read(func(d *memoryData) {
+		language, ok = d.languages[code]
+	})
+	if !ok {
+		return language, ErrNotFound
+	}
+	return language, nil
+}
+
+func (r memoryLanguages) Create(language *models.Language) error {
+	return r.s.write(func(d *memoryData) error {
+		if _, ok := d.languages[language.Code]; ok {
+			return ErrDuplicate
+		}
+		if language.Direction != "" && language.Direction != "ltr" && language.Direction != "rtl" {
+			return fmt.Errorf("invalid text direction: %s", language.Direction)
+		}
+		*language = withLanguageDefaults(*language)
+		d.languages[language.Code] = *language
+		return nil
+	})
+}
+
+func (r memoryLanguages) SearchConfigs() ([]string, error) {
+	var configs []string
+	r.s.read(func(d *memoryData) {
+		for _, language := range d.languages {
+			configs = append(configs, language.SearchConfig)
+		}
+	})
+	slices.Sort(configs)
+	return slices.Compact(configs), nil
+}
+
+func (r memoryLanguages) SearchConfigExists(config string) (bool, error) {
+	return slices.Contains(builtinSearchConfigs, config), nil
+}
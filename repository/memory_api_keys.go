@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/tdawidzi/dictionary_app/models"
+)
+
+type memoryAPIKeys struct {
+	s *memoryStore
+}
+
+func (r memoryAPIKeys) List() ([]models.APIKey, error) {
+	var keys []models.APIKey
+	r.s.read(func(d *memoryData) {
+		keys = sortedRows(d.apiKeys, func(models.APIKey) bool { return true })
+	})
+	slices.SortFunc(keys, func(a, b models.APIKey) int { return strings.Compare(a.Name, b.Name) })
+	return keys, nil
+}
+
+func (r memoryAPIKeys) FindByHash(hash string) (models.APIKey, error) {
+	var keys []models.APIKey
+	r.s.read(func(d *memoryData) {
+		keys = sortedRows(d.apiKeys, func(k models.APIKey) bool { return k.Hash == hash })
+	})
+	if len(keys) == 0 {
+		return models.APIKey{}, ErrNotFound
+	}
+	return keys[0], nil
+}
+
+func (r memoryAPIKeys) Create(key *models.APIKey) error {
+	return r.s.write(func(d *memoryData) error {
+		for _, existing := range d.apiKeys {
+			if existing.Name == key.Name || existing.Hash == key.Hash {
+				return ErrDuplicate
+			}
+		}
+		key.ID = d.nextID("api_keys")
+		key.CreatedAt = now()
+		d.apiKeys[key.ID] = *key
+		return nil
+	})
+}
+
+func (r memoryAPIKeys) Delete(name string) error {
+	return r.s.write(func(d *memoryData) error {
+		for id, key := range d.apiKeys {
+			if key.Name == name {
+				delete(d.apiKeys, id)
+				return nil
+			}
+		}
+		return ErrNotFound
+	})
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"github.com/tdawidzi/dictionary_app/models"
+)
+
+type memoryAudit struct {
+	s *memoryStore
+}
+
+func (r memoryAudit) Append(entry *models.AuditEntry) error {
+	return r.s.write(func(d *memoryData) error {
+		entry.ID = d.nextID("audit_log")
+		entry.CreatedAt = now()
+		d.auditLog[entry.ID] = *entry
+		return nil
+	})
+}
+
+func (r memoryAudit) Get(id uint) (models.AuditEntry, error) {
+	var entry models.AuditEntry
+	var ok bool
+	r.s.read(func(d *memoryData) {
+		entry, ok = d.auditLog[id]
+	})
+	if !ok {
+		return entry, ErrNotFound
+	}
+	return entry, nil
+}
+
+func (r memoryAudit) ForWord(wordID uint) ([]models.AuditEntry, error) {
+	of := func(id *uint) bool { return id != nil && *id == wordID }
+	var entries []models.AuditEntry
+	r.s.read(func(d *memoryData) {
+		entries = sortedRows(d.auditLog, func(e models.AuditEntry) bool { return of(e.WordID) || of(e.TargetWordID) })
+	})
+	return entries, nil
+}
+
+func (r memoryAudit) List(filter AuditFilter, limit int) ([]models.AuditEntry, error) {
+	var entries []models.AuditEntry
+	r.s.read(func(d *memoryData) {
+		entries = sortedRows(d.auditLog, func(e models.AuditEntry) bool {
+			return !e.CreatedAt.Before(filter.Since) && (filter.Actor == "" || e.Actor == filter.Actor)
+		})
+	})
+	return entries[:min(limit, len(entries))], nil
+}
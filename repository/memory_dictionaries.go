@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"slices"
+
+	"github.com/tdawidzi/dictionary_app/models"
+)
+
+type memoryDictionaries struct {
+	s *memoryStore
+}
+
+func (r memoryDictionaries) List() ([]models.Dictionary, error) {
+	var dictionaries []models.Dictionary
+	r.s.read(func(d *memoryData) {
+		dictionaries = sortedRows(d.dictionaries, func(models.Dictionary) bool { return true })
+	})
+	return dictionaries, nil
+}
+
+func (r memoryDictionaries) Find(name string) (models.Dictionary, error) {
+	dictionaries, _ := r.List()
+	i := slices.IndexFunc(dictionaries, func(d models.Dictionary) bool { return d.Name == name })
+	if i < 0 {
+		return models.Dictionary{}, ErrNotFound
+	}
+	return dictionaries[i], nil
+}
+
+func (r memoryDictionaries) Create(dictionary *models.Dictionary) error {
+	return r.s.write(func(d *memoryData) error {
+		for _, existing := range d.dictionaries {
+			if existing.Name == dictionary.Name {
+				return ErrDuplicate
+			}
+		}
+		dictionary.ID = d.nextID("dictionaries")
+		dictionary.CreatedAt = now()
+		d.dictionaries[dictionary.ID] = *dictionary
+		return nil
+	})
+}
@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/tdawidzi/dictionary_app/models"
+
+	"gorm.io/gorm"
+)
+
+type memoryExamples struct {
+	s *memoryStore
+}
+
+func (r memoryExamples) ForWord(wordID uint) ([]models.Example, error) {
+	var examples []models.Example
+	r.s.read(func(d *memoryData) {
+		examples = sortedRows(d.examples, func(e models.Example) bool { return e.WordID == wordID })
+	})
+	return examples, nil
+}
+
+func (r memoryExamples) ForSenses(senseIDs []uint) ([]models.Example, error) {
+	var examples []models.Example
+	r.s.read(func(d *memoryData) {
+		examples = sortedRows(d.examples, func(e models.Example) bool {
+			return e.SenseID != nil && slices.Contains(senseIDs, *e.SenseID)
+		})
+	})
+	return examples, nil
+}
+
+func (r memoryExamples) Get(id uint) (models.Example, error) {
+	var example models.Example
+	var ok bool
+	r.s.read(func(d *memoryData) {
+		example, ok = d.examples[id]
+		example.Word = d.words[example.WordID]
+	})
+	if !ok {
+		return models.Example{}, ErrNotFound
+	}
+	return example, nil
+}
+
+func (r memoryExamples) GetForUpdate(id uint) (models.Example, error) {
+	example, err := r.Get(id)
+	example.Word = models.Word{}
+	return example, err
+}
+
+func (r memoryExamples) Find(wordID uint, text string) (models.Example, error) {
+	var examples []models.Example
+	r.s.read(func(d *memoryData) {
+		examples = sortedRows(d.examples, func(e models.Example) bool {
+			return e.WordID == wordID && e.Example == text
+		})
+	})
+	if len(examples) == 0 {
+		return models.Example{}, ErrNotFound
+	}
+	return examples[0], nil
+}
+
+// checkExample checks constraints of example - text of example is unique in dictionary, also among examples of other words
+func (d *memoryData) checkExample(example models.Example) error {
+	if _, ok := d.words[example.WordID]; !ok {
+		return fmt.Errorf("word %d does not exist", example.WordID)
+	}
+	if example.SenseID != nil {
+		if _, ok := d.senses[*example.SenseID]; !ok {
+			return fmt.Errorf("sense %d does not exist", *example.SenseID)
+		}
+	}
+	for _, e := range d.examples {
+		if e.ID != example.ID && e.DictionaryID == example.DictionaryID && e.Example == example.Example {
+			return ErrDuplicate
+		}
+	}
+	return nil
+}
+
+func (r memoryExamples) Create(example *models.Example) (bool, error) {
+	created := false
+	err := r.s.write(func(d *memoryData) error {
+		for _, e := range d.examples {
+			if e.WordID == example.WordID && e.Example == example.Example {
+				return nil
+			}
+		}
+		example.DictionaryID = d.words[example.WordID].DictionaryID
+		if err := d.checkExample(*example); err != nil {
+			return err
+		}
+		example.ID = d.nextID("examples")
+		example.Version = 1
+		example.CreatedAt, example.UpdatedAt = now(), now()
+		d.examples[example.ID] = *example
+		created = true
+		return nil
+	})
+	return created, err
+}
+
+func (r memoryExamples) Save(example *models.Example) error {
+	return r.s.write(func(d *memoryData) error {
+		example.DictionaryID = d.words[example.WordID].DictionaryID
+		if err := d.checkExample(*example); err != nil {
+			return err
+		}
+		if example.ID == 0 {
+			example.ID = d.nextID("examples")
+			example.CreatedAt = now()
+		} else if saved, ok := d.examples[example.ID]; !ok || saved.Version != example.Version {
+			return ErrStaleVersion
+		}
+		example.Version++
+		example.UpdatedAt = now()
+		saved := *example
+		saved.Word = models.Word{}
+		d.examples[example.ID] = saved
+		return nil
+	})
+}
+
+func (r memoryExamples) Delete(id uint) error {
+	return r.s.write(func(d *memoryData) error {
+		if example, ok := d.examples[id]; ok {
+			example.DeletedAt = deletedNow()
+			d.deletedExamples[id] = example
+			delete(d.examples, id)
+		}
+		return nil
+	})
+}
+
+func (r memoryExamples) Restore(id uint) (models.Example, error) {
+	var example models.Example
+	err := r.s.write(func(d *memoryData) error {
+		var ok bool
+		if example, ok = d.deletedExamples[id]; !ok {
+			return ErrNotFound
+		}
+		if _, ok := d.words[example.WordID]; !ok {
+			return ErrWordDeleted
+		}
+		if err := d.checkExample(example); err != nil {
+			return err
+		}
+		example.DeletedAt = gorm.DeletedAt{}
+		d.examples[id] = example
+		delete(d.deletedExamples, id)
+		example.Word = d.words[example.WordID]
+		return nil
+	})
+	return example, err
+}
+
+func (r memoryExamples) Deleted(dictionaries []uint, limit int) ([]models.Example, error) {
+	var examples []models.Example
+	r.s.read(func(d *memoryData) {
+		examples = deletedRows(d.deletedExamples,
+			func(e models.Example) bool { return isInDictionaries(e.DictionaryID, dictionaries) },
+			func(e models.Example) time.Time { return e.DeletedAt.Time }, limit)
+		for i, e := range examples {
+			examples[i].Word = d.anyWord(e.WordID)
+		}
+	})
+	return examples, nil
+}
+
+func (r memoryExamples) Purge(before time.Time, dictionaries []uint) (int64, error) {
+	var purged int64
+	err := r.s.write(func(d *memoryData) error {
+		for _, e := range d.deletedExamples {
+			if e.DeletedAt.Time.Before(before) && isInDictionaries(e.DictionaryID, dictionaries) {
+				delete(d.deletedExamples, e.ID)
+				purged++
+			}
+		}
+		return nil
+	})
+	return purged, err
+}
+
+// Search matches words of examples case and diacritic insensitive, without stemming (see matchExamples)
+func (r memoryExamples) Search(query, language string, dictionaries []uint, limit int) ([]ExampleHit, error) {
+	var examples []models.Example
+	r.s.read(func(d *memoryData) {
+		for _, e := range sortedRows(d.examples, func(models.Example) bool { return true }) {
+			e.Word = d.words[e.WordID]
+			if (language == "" || e.Word.Language == language) && isInDictionaries(e.Word.DictionaryID, dictionaries) {
+				examples = append(examples, e)
+			}
+		}
+	})
+	return matchExamples(examples, query, limit), nil
+}
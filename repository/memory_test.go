@@ -0,0 +1,209 @@
+package repository_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tdawidzi/dictionary_app/models"
+	"github.com/tdawidzi/dictionary_app/repository"
+)
+
+func newMemoryStore(t *testing.T) repository.Store {
+	t.Helper()
+
+	return repository.NewMemoryStore(
+		models.Language{Code: "pl", Name: "Polish"},
+		models.Language{Code: "en", Name: "English", SearchConfig: "english"},
+	)
+}
+
+func createWord(t *testing.T, store repository.Store, word, language string) models.Word {
+	t.Helper()
+
+	w := models.Word{Word: word, Language: language}
+	created, err := store.Words().Create(&w)
+	assert.NoError(t, err)
+	assert.True(t, created)
+	return w
+}
+
+func TestMemoryWordsAreUnique(t *testing.T) {
+	store := newMemoryStore(t)
+	kot := createWord(t, store, "Kot", "pl")
+	assert.Equal(t, 1, kot.Homograph)
+	assert.Equal(t, "kot", kot.SearchKey)
+
+	// The same word is not created again
+	again := models.Word{Word: "Kot", Language: "pl"}
+	created, err := store.Words().Create(&again)
+	assert.NoError(t, err)
+	assert.False(t, created)
+
+	// Other homograph and other language are different words
+	createWord(t, store, "Kot", "en")
+	second := models.Word{Word: "Kot", Language: "pl", Homograph: 2}
+	created, err = store.Words().Create(&second)
+	assert.NoError(t, err)
+	assert.True(t, created)
+
+	second.Homograph = 1
+	assert.ErrorIs(t, store.Words().Save(&second), repository.ErrDuplicate)
+
+	// Words only in registered languages
+	_, err = store.Words().Create(&models.Word{Word: "gato", Language: "es"})
+	assert.Error(t, err)
+
+	words, err := store.Words().Find(repository.WordLookup{SearchKey: "kot"})
+	assert.NoError(t, err)
+	if assert.Len(t, words, 3) {
+		assert.Equal(t, "en", words[0].Language)
+		assert.Equal(t, 2, words[2].Homograph)
+	}
+}
+
+func TestMemoryDeleteWordCascades(t *testing.T) {
+	store := newMemoryStore(t)
+	kot := createWord(t, store, "kot", "pl")
+	cat := createWord(t, store, "cat", "en")
+	pies := createWord(t, store, "pies", "pl")
+
+	sense := models.Sense{WordID: kot.ID, Ordinal: 1, Definition: "zwierzę"}
+	assert.NoError(t, store.Senses().Create(&sense))
+	assert.NoError(t, store.Forms().Create([]models.WordForm{{WordID: kot.ID, Form: "kota", Case: models.CaseGenitive}}))
+	assert.NoError(t, store.Translations().Create(&models.Translation{SourceWordID: kot.ID, TargetWordID: cat.ID, SourceSenseID: &sense.ID}))
+	assert.NoError(t, store.Translations().Create(&models.Translation{SourceWordID: pies.ID, TargetWordID: cat.ID}))
+	_, err := store.Examples().Create(&models.Example{WordID: kot.ID, Example: "Ala ma kota.", SenseID: &sense.ID})
+	assert.NoError(t, err)
+
+	// Deleted sense is detached from translations and examples
+	assert.NoError(t, store.Senses().Delete(sense.ID))
+	translations, err := store.Translations().ForWords([]uint{kot.ID})
+	assert.NoError(t, err)
+	if assert.Len(t, translations, 1) {
+		assert.Nil(t, translations[0].SourceSenseID)
+		assert.Equal(t, "cat", translations[0].TargetWord.Word)
+	}
+
+	// Translations and examples go to trash with the word
+	assert.NoError(t, store.Words().Delete(kot.ID))
+	examples, _ := store.Examples().ForWord(kot.ID)
+	assert.Empty(t, examples)
+	translations, _ = store.Translations().ForWords([]uint{cat.ID})
+	if assert.Len(t, translations, 1) {
+		assert.Equal(t, pies.ID, translations[0].SourceWordID)
+	}
+
+	// Forms are deleted when word is purged
+	forms, _ := store.Forms().ForWords([]uint{kot.ID})
+	assert.Len(t, forms, 1)
+	purged, err := store.Words().Purge(time.Now().Add(time.Minute), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+	forms, _ = store.Forms().ForWords([]uint{kot.ID})
+	assert.Empty(t, forms)
+	deleted, _ := store.Examples().Deleted(nil, 10)
+	assert.Empty(t, deleted)
+}
+
+func TestMemoryTransactionRollback(t *testing.T) {
+	store := newMemoryStore(t)
+	kot := createWord(t, store, "kot", "pl")
+
+	failure := errors.New("failure")
+	err := store.Transaction(func(tx repository.Store) error {
+		createWord(t, tx, "pies", "pl")
+		if err := tx.Words().Delete(kot.ID); err != nil {
+			return err
+		}
+		return failure
+	})
+	assert.ErrorIs(t, err, failure)
+
+	count, err := store.Words().Count(repository.WordFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	_, err = store.Words().GetForUpdate(kot.ID)
+	assert.NoError(t, err)
+
+	err = store.Transaction(func(tx repository.Store) error {
+		createWord(t, tx, "pies", "pl")
+		return nil
+	})
+	assert.NoError(t, err)
+	count, _ = store.Words().Count(repository.WordFilter{})
+	assert.Equal(t, int64(2), count)
+}
+
+func TestMemoryListWords(t *testing.T) {
+	store := newMemoryStore(t)
+	for _, w := range []string{"żaba", "zebra", "ćma", "cel", "kot"} {
+		createWord(t, store, w, "pl")
+	}
+	createWord(t, store, "cat", "en")
+
+	var page []string
+	var after *repository.WordPosition
+	filter := repository.WordFilter{Language: "pl"}
+	for {
+		words, err := store.Words().List(filter, repository.SortAlphabetical, after, 2)
+		assert.NoError(t, err)
+		if len(words) == 0 {
+			break
+		}
+		for _, w := range words {
+			page = append(page, w.Word)
+		}
+		last := words[len(words)-1]
+		after = &repository.WordPosition{SortKey: last.SortKey, ID: last.ID}
+	}
+	// Polish collation
+	assert.Equal(t, []string{"cel", "ćma", "kot", "zebra", "żaba"}, page)
+
+	translated := false
+	count, err := store.Words().Count(repository.WordFilter{HasTranslations: &translated, Prefix: "c"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	completions, err := store.Words().Complete("c", "", nil, 10)
+	assert.NoError(t, err)
+	words := make([]string, 0, len(completions))
+	for _, w := range completions {
+		words = append(words, w.Word)
+	}
+	assert.Equal(t, []string{"cat", "cel", "ćma"}, words)
+}
+
+func TestMemorySearchExamples(t *testing.T) {
+	store := newMemoryStore(t)
+	cat := createWord(t, store, "cat", "en")
+	kot := createWord(t, store, "kot", "pl")
+	for _, e := range []models.Example{
+		{WordID: cat.ID, Example: "The black cat sleeps."},
+		{WordID: cat.ID, Example: "A cat and a dog."},
+		{WordID: kot.ID, Example: "Czarny kot śpi."},
+	} {
+		_, err := store.Examples().Create(&e)
+		assert.NoError(t, err)
+	}
+
+	hits, err := store.Examples().Search(`"black cat"`, "", nil, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, hits, 1) {
+		assert.Equal(t, "The <b>black</b> <b>cat</b> sleeps.", hits[0].Highlighted)
+		assert.Equal(t, "cat", hits[0].Example.Word.Word)
+	}
+
+	hits, err = store.Examples().Search("cat -dog", "en", nil, 10)
+	assert.NoError(t, err)
+	assert.Len(t, hits, 1)
+
+	hits, err = store.Examples().Search("dog or spi", "", nil, 10)
+	assert.NoError(t, err)
+	assert.Len(t, hits, 2)
+
+	// The same example text cannot be used by two words
+	_, err = store.Examples().Create(&models.Example{WordID: kot.ID, Example: "A cat and a dog."})
+	assert.ErrorIs(t, err, repository.ErrDuplicate)
+}
@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/tdawidzi/dictionary_app/models"
+
+	"gorm.io/gorm"
+)
+
+type memoryTranslations struct {
+	s *memoryStore
+}
+
+// withWords fills words of translations
+func (d *memoryData) withWords(translations []models.Translation) []models.Translation {
+	for i := range translations {
+		translations[i].SourceWord = d.words[translations[i].SourceWordID]
+		translations[i].TargetWord = d.words[translations[i].TargetWordID]
+	}
+	return translations
+}
+
+func (r memoryTranslations) ForWords(wordIDs []uint) ([]models.Translation, error) {
+	var translations []models.Translation
+	r.s.read(func(d *memoryData) {
+		translations = d.withWords(sortedRows(d.translations, func(t models.Translation) bool {
+			return slices.Contains(wordIDs, t.SourceWordID) || slices.Contains(wordIDs, t.TargetWordID)
+		}))
+	})
+	return translations, nil
+}
+
+func (r memoryTranslations) ForSenses(senseIDs []uint) ([]models.Translation, error) {
+	attached := func(id *uint) bool {
+		return id != nil && slices.Contains(senseIDs, *id)
+	}
+	var translations []models.Translation
+	r.s.read(func(d *memoryData) {
+		translations = d.withWords(sortedRows(d.translations, func(t models.Translation) bool {
+			return attached(t.SourceSenseID) || attached(t.TargetSenseID)
+		}))
+	})
+	return translations, nil
+}
+
+func (r memoryTranslations) Get(id uint) (models.Translation, error) {
+	var translation models.Translation
+	var ok bool
+	r.s.read(func(d *memoryData) {
+		if translation, ok = d.translations[id]; ok {
+			translation = d.withWords([]models.Translation{translation})[0]
+		}
+	})
+	if !ok {
+		return translation, ErrNotFound
+	}
+	return translation, nil
+}
+
+func (r memoryTranslations) Find(firstID, secondID uint) (models.Translation, error) {
+	var translations []models.Translation
+	r.s.read(func(d *memoryData) {
+		translations = sortedRows(d.translations, func(t models.Translation) bool {
+			return (t.SourceWordID == firstID && t.TargetWordID == secondID) ||
+				(t.SourceWordID == secondID && t.TargetWordID == firstID)
+		})
+	})
+	if len(translations) == 0 {
+		return models.Translation{}, ErrNotFound
+	}
+	return translations[0], nil
+}
+
+// checkTranslation checks constraints of translation, and sets its dictionary to the one of its words
+func (d *memoryData) checkTranslation(translation *models.Translation) error {
+	for _, id := range []uint{translation.SourceWordID, translation.TargetWordID} {
+		if _, ok := d.words[id]; !ok {
+			return fmt.Errorf("word %d does not exist", id)
+		}
+	}
+	translation.DictionaryID = d.words[translation.SourceWordID].DictionaryID
+	if d.words[translation.TargetWordID].DictionaryID != translation.DictionaryID {
+		return fmt.Errorf("words %d and %d are in different dictionaries", translation.SourceWordID, translation.TargetWordID)
+	}
+	for _, id := range []*uint{translation.SourceSenseID, translation.TargetSenseID} {
+		if id == nil {
+			continue
+		}
+		if _, ok := d.senses[*id]; !ok {
+			return fmt.Errorf("sense %d does not exist", *id)
+		}
+	}
+	for _, t := range d.translations {
+		if t.ID != translation.ID && t.SourceWordID == translation.SourceWordID && t.TargetWordID == translation.TargetWordID {
+			return ErrDuplicate
+		}
+	}
+	return nil
+}
+
+func (r memoryTranslations) Create(translation *models.Translation) error {
+	return r.s.write(func(d *memoryData) error {
+		if err := d.checkTranslation(translation); err != nil {
+			return err
+		}
+		translation.ID = d.nextID("translations")
+		translation.Version = 1
+		translation.CreatedAt, translation.UpdatedAt = now(), now()
+		d.translations[translation.ID] = *translation
+		return nil
+	})
+}
+
+func (r memoryTranslations) Save(t *models.Translation) error {
+	return r.s.write(func(d *memoryData) error {
+		saved, ok := d.translations[t.ID]
+		if !ok || saved.Version != t.Version {
+			return ErrStaleVersion
+		}
+		saved.SourceWordID, saved.TargetWordID = t.SourceWordID, t.TargetWordID
+		saved.SourceSenseID, saved.TargetSenseID = t.SourceSenseID, t.TargetSenseID
+		if err := d.checkTranslation(&saved); err != nil {
+			return err
+		}
+		t.DictionaryID = saved.DictionaryID
+		saved.Version++
+		saved.UpdatedAt = now()
+		d.translations[t.ID] = saved
+		t.Version = saved.Version
+		return nil
+	})
+}
+
+func (r memoryTranslations) DeleteBetween(firstID, secondID uint) error {
+	return r.s.write(func(d *memoryData) error {
+		for _, t := range d.translations {
+			if (t.SourceWordID == firstID && t.TargetWordID == secondID) ||
+				(t.SourceWordID == secondID && t.TargetWordID == firstID) {
+				t.DeletedAt = deletedNow()
+				d.deletedTranslations[t.ID] = t
+				delete(d.translations, t.ID)
+			}
+		}
+		return nil
+	})
+}
+
+func (r memoryTranslations) Restore(id uint) (models.Translation, error) {
+	var translation models.Translation
+	err := r.s.write(func(d *memoryData) error {
+		var ok bool
+		if translation, ok = d.deletedTranslations[id]; !ok {
+			return ErrNotFound
+		}
+		for _, wordID := range []uint{translation.SourceWordID, translation.TargetWordID} {
+			if _, ok := d.words[wordID]; !ok {
+				return ErrWordDeleted
+			}
+		}
+		if err := d.checkTranslation(&translation); err != nil {
+			return err
+		}
+		translation.DeletedAt = gorm.DeletedAt{}
+		d.translations[id] = translation
+		delete(d.deletedTranslations, id)
+		translation = d.withWords([]models.Translation{translation})[0]
+		return nil
+	})
+	return translation, err
+}
+
+func (r memoryTranslations) Deleted(dictionaries []uint, limit int) ([]models.Translation, error) {
+	var translations []models.Translation
+	r.s.read(func(d *memoryData) {
+		translations = deletedRows(d.deletedTranslations,
+			func(t models.Translation) bool { return isInDictionaries(t.DictionaryID, dictionaries) },
+			func(t models.Translation) time.Time { return t.DeletedAt.Time }, limit)
+		for i, t := range translations {
+			translations[i].SourceWord = d.anyWord(t.SourceWordID)
+			translations[i].TargetWord = d.anyWord(t.TargetWordID)
+		}
+	})
+	return translations, nil
+}
+
+func (r memoryTranslations) Purge(before time.Time, dictionaries []uint) (int64, error) {
+	var purged int64
+	err := r.s.write(func(d *memoryData) error {
+		for _, t := range d.deletedTranslations {
+			if t.DeletedAt.Time.Before(before) && isInDictionaries(t.DictionaryID, dictionaries) {
+				delete(d.deletedTranslations, t.ID)
+				purged++
+			}
+		}
+		return nil
+	})
+	return purged, err
+}
+
+// anyWord returns word, also from trash
+func (d *memoryData) anyWord(id uint) models.Word {
+	if word, ok := d.words[id]; ok {
+		return word
+	}
+	return d.deletedWords[id]
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/tdawidzi/dictionary_app/models"
+)
+
+type memoryUsers struct {
+	s *memoryStore
+}
+
+func (r memoryUsers) List() ([]models.User, error) {
+	var users []models.User
+	r.s.read(func(d *memoryData) {
+		users = sortedRows(d.users, func(models.User) bool { return true })
+	})
+	slices.SortFunc(users, func(a, b models.User) int { return strings.Compare(a.Name, b.Name) })
+	return users, nil
+}
+
+func (r memoryUsers) Find(name string) (models.User, error) {
+	var users []models.User
+	r.s.read(func(d *memoryData) {
+		users = sortedRows(d.users, func(u models.User) bool { return u.Name == name })
+	})
+	if len(users) == 0 {
+		return models.User{}, ErrNotFound
+	}
+	return users[0], nil
+}
+
+func (r memoryUsers) Create(user *models.User) error {
+	return r.s.write(func(d *memoryData) error {
+		if err := checkUser(d, *user); err != nil {
+			return err
+		}
+		user.ID = d.nextID("users")
+		user.CreatedAt = now()
+		user.UpdatedAt = user.CreatedAt
+		d.users[user.ID] = *user
+		return nil
+	})
+}
+
+func (r memoryUsers) Save(user *models.User) error {
+	return r.s.write(func(d *memoryData) error {
+		if err := checkUser(d, *user); err != nil {
+			return err
+		}
+		user.UpdatedAt = now()
+		d.users[user.ID] = *user
+		return nil
+	})
+}
+
+func (r memoryUsers) Delete(id uint) error {
+	return r.s.write(func(d *memoryData) error {
+		delete(d.users, id)
+		return nil
+	})
+}
+
+// checkUser checks constraints of users table
+func checkUser(d *memoryData, user models.User) error {
+	if !slices.Contains(models.Roles, user.Role) {
+		return fmt.Errorf("invalid role: %s", user.Role)
+	}
+	for id, existing := range d.users {
+		if id != user.ID && existing.Name == user.Name {
+			return ErrDuplicate
+		}
+	}
+	return nil
+}
@@ -0,0 +1,594 @@
+package repository
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/tdawidzi/dictionary_app/models"
+	"github.com/tdawidzi/dictionary_app/textutil"
+
+	"gorm.io/gorm"
+)
+
+type memoryWords struct {
+	s *memoryStore
+}
+
+func (r memoryWords) Find(lookup WordLookup) ([]models.Word, error) {
+	var words []models.Word
+	r.s.read(func(d *memoryData) {
+		// Words having matching forms
+		formOf := map[uint]bool{}
+		if lookup.Form != "" || lookup.FormSearchKey != "" {
+			for _, f := range d.forms {
+				if (lookup.Form != "" && f.Form == lookup.Form) || (lookup.Form == "" && f.SearchKey == lookup.FormSearchKey) {
+					formOf[f.WordID] = true
+				}
+			}
+		}
+
+		words = sortedRows(d.words, func(w models.Word) bool {
+			switch {
+			case lookup.Word != "":
+				if w.Word != lookup.Word {
+					return false
+				}
+			case lookup.SearchKey != "":
+				if w.SearchKey != lookup.SearchKey {
+					return false
+				}
+			case lookup.Form != "" || lookup.FormSearchKey != "":
+				if !formOf[w.ID] {
+					return false
+				}
+			default:
+				return false
+			}
+			return isInDictionaries(w.DictionaryID, lookup.Dictionaries) &&
+				(lookup.Language == "" || w.Language == lookup.Language) &&
+				(lookup.Homograph == 0 || w.Homograph == lookup.Homograph)
+		})
+	})
+
+	slices.SortStableFunc(words, func(a, b models.Word) int {
+		return cmp.Or(
+			cmp.Compare(a.DictionaryID, b.DictionaryID),
+			strings.Compare(a.Language, b.Language),
+			cmp.Compare(a.Homograph, b.Homograph),
+		)
+	})
+	return words, nil
+}
+
+func (r memoryWords) GetForUpdate(id uint) (models.Word, error) {
+	var word models.Word
+	var ok bool
+	r.s.read(func(d *memoryData) {
+		word, ok = d.words[id]
+	})
+	if !ok {
+		return word, ErrNotFound
+	}
+	return word, nil
+}
+
+func (r memoryWords) Lock(ids ...uint) error {
+	var err error
+	r.s.read(func(d *memoryData) {
+		for _, id := range ids {
+			if _, ok := d.words[id]; !ok {
+				err = ErrNotFound
+				return
+			}
+		}
+	})
+	return err
+}
+
+// isInDictionaries checks if entry of dictionary belongs to one of given dictionaries (any, if none is given)
+func isInDictionaries(dictionaryID uint, dictionaries []uint) bool {
+	return len(dictionaries) == 0 || slices.Contains(dictionaries, dictionaryID)
+}
+
+// matches checks if word passes filter
+func (filter WordFilter) matches(d *memoryData, w models.Word) bool {
+	if !isInDictionaries(w.DictionaryID, filter.Dictionaries) {
+		return false
+	}
+	if filter.Language != "" && w.Language != filter.Language {
+		return false
+	}
+	if !strings.HasPrefix(w.SearchKey, filter.Prefix) {
+		return false
+	}
+	attributes := [][2]string{
+		{filter.PartOfSpeech, w.PartOfSpeech},
+		{filter.Gender, w.Gender},
+		{filter.Aspect, w.Aspect},
+		{filter.Countability, w.Countability},
+		{filter.Transitivity, w.Transitivity},
+	}
+	for _, a := range attributes {
+		want, value := a[0], a[1]
+		if want == models.AttributeNone {
+			want = ""
+		} else if want == "" {
+			continue
+		}
+		if want != value {
+			return false
+		}
+	}
+	if filter.HasTranslations != nil && *filter.HasTranslations != d.translated(w.ID) {
+		return false
+	}
+	return true
+}
+
+// translated checks if word is source or target of any translation
+func (d *memoryData) translated(wordID uint) bool {
+	for _, t := range d.translations {
+		if t.SourceWordID == wordID || t.TargetWordID == wordID {
+			return true
+		}
+	}
+	return false
+}
+
+func (r memoryWords) List(filter WordFilter, sort string, after *WordPosition, limit int) ([]models.Word, error) {
+	var compare func(a, b models.Word) int
+	var isAfter func(w models.Word) bool
+	switch sort {
+	case SortAlphabetical:
+		compare = func(a, b models.Word) int {
+			return cmp.Or(bytes.Compare(a.SortKey, b.SortKey), cmp.Compare(a.ID, b.ID))
+		}
+		isAfter = func(w models.Word) bool {
+			return compare(w, models.Word{SortKey: after.SortKey, ID: after.ID}) > 0
+		}
+	case SortNewest:
+		compare = func(a, b models.Word) int { return cmp.Compare(b.ID, a.ID) }
+		isAfter = func(w models.Word) bool { return w.ID < after.ID }
+	case SortID:
+		compare = func(a, b models.Word) int { return cmp.Compare(a.ID, b.ID) }
+		isAfter = func(w models.Word) bool { return w.ID > after.ID }
+	default:
+		return nil, fmt.Errorf("unsupported sort order: %s", sort)
+	}
+
+	var words []models.Word
+	r.s.read(func(d *memoryData) {
+		words = sortedRows(d.words, func(w models.Word) bool {
+			return filter.matches(d, w) && (after == nil || isAfter(w))
+		})
+	})
+	slices.SortFunc(words, compare)
+	return words[:min(limit, len(words))], nil
+}
+
+func (r memoryWords) Count(filter WordFilter) (int64, error) {
+	var count int64
+	r.s.read(func(d *memoryData) {
+		for _, w := range d.words {
+			if filter.matches(d, w) {
+				count++
+			}
+		}
+	})
+	return count, nil
+}
+
+func (r memoryWords) Complete(prefix, language string, dictionaries []uint, limit int) ([]models.Word, error) {
+	words, err := r.List(WordFilter{Dictionaries: dictionaries, Language: language, Prefix: prefix}, SortID, nil, math.MaxInt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Byte order of search keys, like SQL stores - exact match is first
+	slices.SortStableFunc(words, func(a, b models.Word) int { return strings.Compare(a.SearchKey, b.SearchKey) })
+	return words[:min(limit, len(words))], nil
+}
+
+// Similar returns alphabetical neighbours of key starting with the same letter, like SQL stores without trigram index
+func (r memoryWords) Similar(key, language string, dictionaries []uint, limit int) ([]models.Word, error) {
+	first, _ := utf8.DecodeRuneInString(key)
+	words, err := r.List(WordFilter{Dictionaries: dictionaries, Language: language, Prefix: string(first)}, SortID, nil, math.MaxInt)
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(words, func(a, b models.Word) int { return strings.Compare(a.SearchKey, b.SearchKey) })
+	at, _ := slices.BinarySearchFunc(words, key, func(w models.Word, key string) int { return strings.Compare(w.SearchKey, key) })
+	start := max(at-limit/2, 0)
+	end := min(start+limit, len(words))
+	return words[start:end], nil
+}
+
+// checkWord checks constraints of word and sets its keys (like models.Word.BeforeSave)
+func (d *memoryData) checkWord(word *models.Word) error {
+	if word.Homograph == 0 {
+		word.Homograph = 1
+	}
+	if word.Homograph < 0 {
+		return fmt.Errorf("homograph must be positive")
+	}
+	if word.DictionaryID == 0 {
+		word.DictionaryID = models.DefaultDictionaryID
+	}
+	if _, ok := d.dictionaries[word.DictionaryID]; !ok {
+		return fmt.Errorf("dictionary %d does not exist", word.DictionaryID)
+	}
+	if _, ok := d.languages[word.Language]; !ok {
+		return fmt.Errorf("language %s is not registered", word.Language)
+	}
+	word.SearchKey = textutil.SearchKey(word.Word)
+	word.SortKey = textutil.SortKey(word.Word)
+	return nil
+}
+
+// sameWord checks if other word of the dictionary has the same spelling, language and homograph
+func (d *memoryData) sameWord(word models.Word) bool {
+	for _, w := range d.words {
+		if w.ID != word.ID && w.DictionaryID == word.DictionaryID && w.Word == word.Word &&
+			w.Language == word.Language && w.Homograph == word.Homograph {
+			return true
+		}
+	}
+	return false
+}
+
+func (r memoryWords) Create(word *models.Word) (bool, error) {
+	created := false
+	err := r.s.write(func(d *memoryData) error {
+		if err := d.checkWord(word); err != nil {
+			return err
+		}
+		if d.sameWord(*word) {
+			return nil
+		}
+		word.ID = d.nextID("words")
+		word.Version = 1
+		word.CreatedAt, word.UpdatedAt = now(), now()
+		d.words[word.ID] = *word
+		created = true
+		return nil
+	})
+	return created, err
+}
+
+func (r memoryWords) Save(word *models.Word) error {
+	return r.s.write(func(d *memoryData) error {
+		if err := d.checkWord(word); err != nil {
+			return err
+		}
+		if d.sameWord(*word) {
+			return ErrDuplicate
+		}
+		if word.ID == 0 {
+			word.ID = d.nextID("words")
+			word.CreatedAt = now()
+		} else if saved, ok := d.words[word.ID]; !ok || saved.Version != word.Version {
+			return ErrStaleVersion
+		}
+		word.Version++
+		word.UpdatedAt = now()
+		d.words[word.ID] = *word
+		return nil
+	})
+}
+
+func (r memoryWords) Delete(id uint) error {
+	return r.s.write(func(d *memoryData) error {
+		word, ok := d.words[id]
+		if !ok {
+			return ErrNotFound
+		}
+		// Translations and examples get the same time of deletion as the word, so restore can tell them apart
+		word.DeletedAt = deletedNow()
+		for _, t := range d.translations {
+			if t.SourceWordID == id || t.TargetWordID == id {
+				t.DeletedAt = word.DeletedAt
+				d.deletedTranslations[t.ID] = t
+				delete(d.translations, t.ID)
+			}
+		}
+		for _, e := range d.examples {
+			if e.WordID == id {
+				e.DeletedAt = word.DeletedAt
+				d.deletedExamples[e.ID] = e
+				delete(d.examples, e.ID)
+			}
+		}
+		d.deletedWords[id] = word
+		delete(d.words, id)
+		return nil
+	})
+}
+
+func (r memoryWords) Restore(id uint) (models.Word, error) {
+	var word models.Word
+	err := r.s.write(func(d *memoryData) error {
+		var ok bool
+		if word, ok = d.deletedWords[id]; !ok {
+			return ErrNotFound
+		}
+		if d.sameWord(word) {
+			return ErrDuplicate
+		}
+		deletedAt := word.DeletedAt.Time
+		word.DeletedAt = gorm.DeletedAt{}
+		d.words[id] = word
+		delete(d.deletedWords, id)
+
+		for _, t := range d.deletedTranslations {
+			_, sourceActive := d.words[t.SourceWordID]
+			_, targetActive := d.words[t.TargetWordID]
+			if (t.SourceWordID == id || t.TargetWordID == id) && t.DeletedAt.Time.Equal(deletedAt) && sourceActive && targetActive {
+				t.DeletedAt = gorm.DeletedAt{}
+				d.translations[t.ID] = t
+				delete(d.deletedTranslations, t.ID)
+			}
+		}
+		for _, e := range d.deletedExamples {
+			if e.WordID == id && e.DeletedAt.Time.Equal(deletedAt) && d.checkExample(e) == nil {
+				e.DeletedAt = gorm.DeletedAt{}
+				d.examples[e.ID] = e
+				delete(d.deletedExamples, e.ID)
+			}
+		}
+		return nil
+	})
+	return word, err
+}
+
+func (r memoryWords) Deleted(dictionaries []uint, limit int) ([]models.Word, error) {
+	var words []models.Word
+	r.s.read(func(d *memoryData) {
+		words = deletedRows(d.deletedWords,
+			func(w models.Word) bool { return isInDictionaries(w.DictionaryID, dictionaries) },
+			func(w models.Word) time.Time { return w.DeletedAt.Time }, limit)
+	})
+	return words, nil
+}
+
+func (r memoryWords) Purge(before time.Time, dictionaries []uint) (int64, error) {
+	var purged int64
+	err := r.s.write(func(d *memoryData) error {
+		for _, w := range d.deletedWords {
+			if w.DeletedAt.Time.Before(before) && isInDictionaries(w.DictionaryID, dictionaries) {
+				d.deleteWord(w.ID)
+				purged++
+			}
+		}
+		return nil
+	})
+	return purged, err
+}
+
+// deleteWord permanently deletes word in trash with its forms, senses, translations and examples
+func (d *memoryData) deleteWord(id uint) {
+	for _, s := range d.senses {
+		if s.WordID == id {
+			d.deleteSense(s.ID)
+		}
+	}
+	for _, f := range d.forms {
+		if f.WordID == id {
+			delete(d.forms, f.ID)
+		}
+	}
+	for _, t := range d.deletedTranslations {
+		if t.SourceWordID == id || t.TargetWordID == id {
+			delete(d.deletedTranslations, t.ID)
+		}
+	}
+	for _, e := range d.deletedExamples {
+		if e.WordID == id {
+			delete(d.deletedExamples, e.ID)
+		}
+	}
+	delete(d.deletedWords, id)
+}
+
+// formKey - unique columns of form
+type formKey struct {
+	wordID                                             uint
+	form, grammaticalCase, number, person, tense, mood string
+	aspect, gender, degree                             string
+}
+
+func keyOfForm(f models.WordForm) formKey {
+	return formKey{f.WordID, f.Form, f.Case, f.Number, f.Person, f.Tense, f.Mood, f.Aspect, f.Gender, f.Degree}
+}
+
+type memoryForms struct {
+	s *memoryStore
+}
+
+func (r memoryForms) ForWords(wordIDs []uint) ([]models.WordForm, error) {
+	var forms []models.WordForm
+	r.s.read(func(d *memoryData) {
+		forms = sortedRows(d.forms, func(f models.WordForm) bool {
+			return slices.Contains(wordIDs, f.WordID)
+		})
+	})
+	return forms, nil
+}
+
+func (r memoryForms) Create(forms []models.WordForm) error {
+	return r.s.write(func(d *memoryData) error {
+		// Forms are unique within paradigm - also among created ones
+		existing := map[formKey]bool{}
+		for _, f := range d.forms {
+			existing[keyOfForm(f)] = true
+		}
+		for i := range forms {
+			if _, ok := d.words[forms[i].WordID]; !ok {
+				return fmt.Errorf("word %d does not exist", forms[i].WordID)
+			}
+			forms[i].SearchKey = textutil.SearchKey(forms[i].Form)
+			key := keyOfForm(forms[i])
+			if existing[key] {
+				return ErrDuplicate
+			}
+			existing[key] = true
+		}
+
+		for i := range forms {
+			forms[i].ID = d.nextID("word_forms")
+			d.forms[forms[i].ID] = forms[i]
+		}
+		return nil
+	})
+}
+
+func (r memoryForms) DeleteForWord(wordID uint) error {
+	return r.s.write(func(d *memoryData) error {
+		for _, f := range d.forms {
+			if f.WordID == wordID {
+				delete(d.forms, f.ID)
+			}
+		}
+		return nil
+	})
+}
+
+type memorySenses struct {
+	s *memoryStore
+}
+
+func (r memorySenses) ForWords(wordIDs []uint) ([]models.Sense, error) {
+	var senses []models.Sense
+	r.s.read(func(d *memoryData) {
+		senses = sortedRows(d.senses, func(s models.Sense) bool {
+			return slices.Contains(wordIDs, s.WordID)
+		})
+	})
+	slices.SortStableFunc(senses, func(a, b models.Sense) int { return cmp.Compare(a.Ordinal, b.Ordinal) })
+	return senses, nil
+}
+
+func (r memorySenses) Get(id uint) (models.Sense, error) {
+	var sense models.Sense
+	var ok bool
+	r.s.read(func(d *memoryData) {
+		// Senses of words in trash are not available
+		if sense, ok = d.senses[id]; ok {
+			_, ok = d.words[sense.WordID]
+		}
+	})
+	if !ok {
+		return sense, ErrNotFound
+	}
+	return sense, nil
+}
+
+func (r memorySenses) GetForUpdate(id uint) (models.Sense, error) {
+	return r.Get(id)
+}
+
+func (r memorySenses) Find(wordID uint, ordinal int) (models.Sense, error) {
+	var senses []models.Sense
+	r.s.read(func(d *memoryData) {
+		senses = sortedRows(d.senses, func(s models.Sense) bool {
+			return s.WordID == wordID && s.Ordinal == ordinal
+		})
+	})
+	if len(senses) == 0 {
+		return models.Sense{}, ErrNotFound
+	}
+	return senses[0], nil
+}
+
+func (r memorySenses) LastOrdinal(wordID uint) (int, error) {
+	last := 0
+	r.s.read(func(d *memoryData) {
+		for _, s := range d.senses {
+			if s.WordID == wordID {
+				last = max(last, s.Ordinal)
+			}
+		}
+	})
+	return last, nil
+}
+
+// checkSense checks constraints of sense
+func (d *memoryData) checkSense(sense models.Sense) error {
+	if sense.Ordinal < 1 {
+		return fmt.Errorf("ordinal must be positive")
+	}
+	if _, ok := d.words[sense.WordID]; !ok {
+		return fmt.Errorf("word %d does not exist", sense.WordID)
+	}
+	for _, s := range d.senses {
+		if s.ID != sense.ID && s.WordID == sense.WordID && s.Ordinal == sense.Ordinal {
+			return ErrDuplicate
+		}
+	}
+	return nil
+}
+
+func (r memorySenses) Create(sense *models.Sense) error {
+	return r.s.write(func(d *memoryData) error {
+		if err := d.checkSense(*sense); err != nil {
+			return err
+		}
+		sense.ID = d.nextID("senses")
+		sense.Version = 1
+		d.senses[sense.ID] = *sense
+		return nil
+	})
+}
+
+func (r memorySenses) Save(sense *models.Sense) error {
+	return r.s.write(func(d *memoryData) error {
+		if err := d.checkSense(*sense); err != nil {
+			return err
+		}
+		if sense.ID == 0 {
+			sense.ID = d.nextID("senses")
+		} else if saved, ok := d.senses[sense.ID]; !ok || saved.Version != sense.Version {
+			return ErrStaleVersion
+		}
+		sense.Version++
+		d.senses[sense.ID] = *sense
+		return nil
+	})
+}
+
+func (r memorySenses) Delete(id uint) error {
+	return r.s.write(func(d *memoryData) error {
+		d.deleteSense(id)
+		return nil
+	})
+}
+
+// deleteSense deletes sense - its translations and examples (also in trash) stay attached to the word
+func (d *memoryData) deleteSense(id uint) {
+	for _, translations := range []map[uint]models.Translation{d.translations, d.deletedTranslations} {
+		for _, t := range translations {
+			if t.SourceSenseID != nil && *t.SourceSenseID == id {
+				t.SourceSenseID = nil
+			}
+			if t.TargetSenseID != nil && *t.TargetSenseID == id {
+				t.TargetSenseID = nil
+			}
+			translations[t.ID] = t
+		}
+	}
+	for _, examples := range []map[uint]models.Example{d.examples, d.deletedExamples} {
+		for _, e := range examples {
+			if e.SenseID != nil && *e.SenseID == id {
+				e.SenseID = nil
+				examples[e.ID] = e
+			}
+		}
+	}
+	delete(d.senses, id)
+}
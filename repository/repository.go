@@ -0,0 +1,247 @@
+// Package repository defines access to dictionary data. Handlers depend only on the interfaces,
+// so the same handlers work with any storage (see NewGormStore).
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/tdawidzi/dictionary_app/models"
+)
+
+var (
+	ErrNotFound    = errors.New("record not found")
+	ErrDuplicate   = errors.New("duplicated key not allowed")   // unique constraint violation
+	ErrWordDeleted = errors.New("word of the entry is deleted") // entry cannot be restored before its word
+	// ErrStaleVersion - row was modified after it was read (its version changed), so it cannot be saved
+	ErrStaleVersion = errors.New("row was modified since it was read")
+)
+
+// Store gives access to repositories of single database
+type Store interface {
+	Languages() LanguageRepository
+	Dictionaries() DictionaryRepository
+	Words() WordRepository
+	Forms() FormRepository
+	Senses() SenseRepository
+	Translations() TranslationRepository
+	Examples() ExampleRepository
+	Audit() AuditRepository
+	APIKeys() APIKeyRepository
+	Users() UserRepository
+
+	// Transaction runs fn with repositories working in one transaction.
+	// Transaction is committed if fn returns nil, otherwise it is rolled back and the error is returned.
+	Transaction(fn func(tx Store) error) error
+}
+
+type LanguageRepository interface {
+	// List returns all languages ordered by code
+	List() ([]models.Language, error)
+	Find(code string) (models.Language, error)
+	Create(language *models.Language) error
+	// SearchConfigs returns distinct text search configurations of languages
+	SearchConfigs() ([]string, error)
+	// SearchConfigExists checks if text search configuration is supported by database
+	SearchConfigExists(config string) (bool, error)
+}
+
+type DictionaryRepository interface {
+	// List returns all dictionaries ordered by id (the default one first)
+	List() ([]models.Dictionary, error)
+	Find(name string) (models.Dictionary, error)
+	// Create inserts dictionary. Returns ErrDuplicate if dictionary with the same name exists.
+	Create(dictionary *models.Dictionary) error
+}
+
+// Sort orders of words list
+const (
+	SortAlphabetical = "alphabetical" // polish collation, homographs by id
+	SortNewest       = "newest"       // recently added first
+	SortID           = "id"
+)
+
+// WordLookup - criteria of word lookup. Exactly one of text criteria is set.
+// Empty language, dictionaries and zero homograph mean "any".
+type WordLookup struct {
+	Word          string // exact spelling
+	SearchKey     string // normalized spelling (see textutil.SearchKey)
+	Form          string // exact spelling of inflected form
+	FormSearchKey string // normalized inflected form
+	Dictionaries  []uint // ids of dictionaries
+	Language      string
+	Homograph     int
+}
+
+// WordFilter narrows down list of words, empty values mean "any"
+type WordFilter struct {
+	Dictionaries    []uint // ids of dictionaries
+	Language        string
+	Prefix          string // prefix of normalized spelling
+	PartOfSpeech    string // grammatical attributes - models.AttributeNone matches words without the attribute
+	Gender          string
+	Aspect          string
+	Countability    string
+	Transitivity    string
+	HasTranslations *bool
+}
+
+// WordPosition is position in sorted list of words - values of the word the list is continued after
+type WordPosition struct {
+	SortKey []byte
+	ID      uint
+}
+
+type WordRepository interface {
+	// Find returns words matching lookup, ordered by dictionary, language and homograph
+	Find(lookup WordLookup) ([]models.Word, error)
+	// GetForUpdate fetches word and locks it until end of transaction
+	GetForUpdate(id uint) (models.Word, error)
+	// Lock locks words until end of transaction, so they cannot be deleted meanwhile and concurrent
+	// transactions locking any of them wait. Returns ErrNotFound if any of words does not exist.
+	Lock(ids ...uint) error
+	// List returns at most limit words matching filter, in given sort order, after given position (nil - from the start)
+	List(filter WordFilter, sort string, after *WordPosition, limit int) ([]models.Word, error)
+	Count(filter WordFilter) (int64, error)
+	// Complete returns words which normalized spelling starts with prefix:
+	// exact match first, then alphabetically. Empty language and dictionaries mean "any".
+	Complete(prefix, language string, dictionaries []uint, limit int) ([]models.Word, error)
+	// Similar returns at most limit candidates for words similar to normalized text, closest first (if storage can tell).
+	// Without trigram index candidates are words starting with the same letter, alphabetically closest to the text.
+	Similar(key, language string, dictionaries []uint, limit int) ([]models.Word, error)
+	// Create inserts word, unless the same word (dictionary, spelling, language, homograph) exists. Returns false if it exists.
+	// Word without dictionary is added to the default one.
+	Create(word *models.Word) (bool, error)
+	// Save writes word and increments its version. Returns ErrStaleVersion if word was modified after it was read.
+	Save(word *models.Word) error
+	// Delete moves word to trash, together with its translations and examples
+	Delete(id uint) error
+	// Restore brings word back from trash, with translations and examples deleted together with it.
+	// Translations to words still in trash and examples which text was used again meanwhile stay in trash.
+	Restore(id uint) (models.Word, error)
+	// Deleted returns at most limit words in trash of given dictionaries (any, if none is given), recently deleted first
+	Deleted(dictionaries []uint, limit int) ([]models.Word, error)
+	// Purge permanently deletes words of given dictionaries (any, if none is given) moved to trash before given time,
+	// with their forms and senses. Returns number of deleted words.
+	Purge(before time.Time, dictionaries []uint) (int64, error)
+}
+
+type FormRepository interface {
+	// ForWords returns forms of given words
+	ForWords(wordIDs []uint) ([]models.WordForm, error)
+	Create(forms []models.WordForm) error
+	DeleteForWord(wordID uint) error
+}
+
+type SenseRepository interface {
+	// ForWords returns senses of given words ordered by sense number
+	ForWords(wordIDs []uint) ([]models.Sense, error)
+	Get(id uint) (models.Sense, error)
+	// GetForUpdate fetches sense and locks it until end of transaction
+	GetForUpdate(id uint) (models.Sense, error)
+	Find(wordID uint, ordinal int) (models.Sense, error)
+	// LastOrdinal returns the highest sense number of word (0 if word has no senses)
+	LastOrdinal(wordID uint) (int, error)
+	Create(sense *models.Sense) error
+	// Save writes sense and increments its version. Returns ErrStaleVersion if sense was modified after it was read.
+	Save(sense *models.Sense) error
+	Delete(id uint) error
+}
+
+type TranslationRepository interface {
+	// ForWords returns translations of given words (as source or target) with both words, ordered by id
+	ForWords(wordIDs []uint) ([]models.Translation, error)
+	// ForSenses returns translations attached to given senses with both words, ordered by id
+	ForSenses(senseIDs []uint) ([]models.Translation, error)
+	// Get fetches translation with both words
+	Get(id uint) (models.Translation, error)
+	// Find looks for translation between two words, regardless of its direction
+	Find(firstID, secondID uint) (models.Translation, error)
+	Create(translation *models.Translation) error
+	// Save writes words and senses of translation and increments its version.
+	// Returns ErrStaleVersion if translation was modified after it was read.
+	Save(translation *models.Translation) error
+	// DeleteBetween moves translation between two words to trash, regardless of its direction
+	DeleteBetween(firstID, secondID uint) error
+	// Restore brings translation back from trash. Returns ErrWordDeleted if any of its words is in trash.
+	Restore(id uint) (models.Translation, error)
+	// Deleted returns at most limit translations in trash of given dictionaries (any, if none is given) with both words,
+	// recently deleted first
+	Deleted(dictionaries []uint, limit int) ([]models.Translation, error)
+	// Purge permanently deletes translations of given dictionaries (any, if none is given) moved to trash before given time,
+	// returns their number
+	Purge(before time.Time, dictionaries []uint) (int64, error)
+}
+
+// ExampleHit is single result of full-text search
+type ExampleHit struct {
+	Example     models.Example // with its word
+	Highlighted string         // example with matched terms wrapped in <b></b>
+	Rank        float64
+}
+
+type ExampleRepository interface {
+	ForWord(wordID uint) ([]models.Example, error)
+	// ForSenses returns examples attached to given senses, ordered by id
+	ForSenses(senseIDs []uint) ([]models.Example, error)
+	// Get fetches example with its word
+	Get(id uint) (models.Example, error)
+	// GetForUpdate fetches example and locks it until end of transaction
+	GetForUpdate(id uint) (models.Example, error)
+	Find(wordID uint, text string) (models.Example, error)
+	// Create inserts example, unless word already has the same one. Returns false if it exists.
+	// Dictionary of example is set to dictionary of its word.
+	Create(example *models.Example) (bool, error)
+	// Save writes example and increments its version. Returns ErrStaleVersion if example was modified after it was read.
+	Save(example *models.Example) error
+	// Delete moves example to trash
+	Delete(id uint) error
+	// Restore brings example back from trash. Returns ErrWordDeleted if its word is in trash.
+	Restore(id uint) (models.Example, error)
+	// Deleted returns at most limit examples in trash of given dictionaries (any, if none is given) with their words,
+	// recently deleted first
+	Deleted(dictionaries []uint, limit int) ([]models.Example, error)
+	// Purge permanently deletes examples of given dictionaries (any, if none is given) moved to trash before given time,
+	// returns their number
+	Purge(before time.Time, dictionaries []uint) (int64, error)
+	// Search finds examples matching full-text query (web search syntax) in text search configuration
+	// of their language, optionally only in given language and dictionaries. Results are ordered by relevance.
+	Search(query, language string, dictionaries []uint, limit int) ([]ExampleHit, error)
+}
+
+// AuditFilter narrows down audit log, empty values mean "any"
+type AuditFilter struct {
+	Since time.Time // changes made at or after given time
+	Actor string
+}
+
+// AuditRepository - audit log can be only appended, its entries are never changed
+type AuditRepository interface {
+	Append(entry *models.AuditEntry) error
+	Get(id uint) (models.AuditEntry, error)
+	// ForWord returns changes of word and entities belonging to it (also as target of translation), oldest first
+	ForWord(wordID uint) ([]models.AuditEntry, error)
+	// List returns at most limit changes matching filter, oldest first
+	List(filter AuditFilter, limit int) ([]models.AuditEntry, error)
+}
+
+type APIKeyRepository interface {
+	// List returns all keys ordered by name
+	List() ([]models.APIKey, error)
+	// FindByHash finds key with given hash (see auth.HashAPIKey)
+	FindByHash(hash string) (models.APIKey, error)
+	// Create inserts key. Returns ErrDuplicate if key with the same name exists.
+	Create(key *models.APIKey) error
+	// Delete removes key with given name, so it cannot be used anymore
+	Delete(name string) error
+}
+
+type UserRepository interface {
+	// List returns all users ordered by name
+	List() ([]models.User, error)
+	Find(name string) (models.User, error)
+	// Create inserts user. Returns ErrDuplicate if user with the same name exists.
+	Create(user *models.User) error
+	Save(user *models.User) error
+	Delete(id uint) error
+}
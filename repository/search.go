@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/tdawidzi/dictionary_app/models"
+	"github.com/tdawidzi/dictionary_app/textutil"
+)
+
+// Full-text search of examples without database support (memory store, SQLite)
+
+// Text search configurations built into PostgreSQL. Without database support all of them search the same way.
+var builtinSearchConfigs = []string{
+	"simple", "arabic", "armenian", "basque", "catalan", "danish", "dutch", "english", "finnish", "french",
+	"german", "greek", "hindi", "hungarian", "indonesian", "irish", "italian", "lithuanian", "nepali", "norwegian",
+	"portuguese", "romanian", "russian", "serbian", "spanish", "swedish", "tamil", "turkish", "yiddish",
+}
+
+// matchExamples finds examples (with their words) matching query and orders them by relevance.
+// Words are matched case and diacritic insensitive, without stemming.
+// Query has web search syntax: words, "quoted phrases", -excluded words and "or" between alternatives.
+func matchExamples(examples []models.Example, query string, limit int) []ExampleHit {
+	alternatives := parseSearchQuery(query)
+
+	var hits []ExampleHit
+	for _, e := range examples {
+		tokens := searchTokens(e.Example)
+		matched := map[int]bool{} // positions of matched tokens
+		for _, terms := range alternatives {
+			if positions, ok := matchTerms(tokens, terms); ok {
+				for _, p := range positions {
+					matched[p] = true
+				}
+			}
+		}
+		if len(matched) == 0 {
+			continue
+		}
+		hits = append(hits, ExampleHit{
+			Example:     e,
+			Highlighted: highlight(e.Example, tokens, matched),
+			Rank:        float64(len(matched)) / float64(len(tokens)),
+		})
+	}
+
+	slices.SortStableFunc(hits, func(a, b ExampleHit) int {
+		return cmp.Or(cmp.Compare(b.Rank, a.Rank), cmp.Compare(a.Example.ID, b.Example.ID))
+	})
+	return hits[:min(limit, len(hits))]
+}
+
+// searchTerm is single word or phrase of query
+type searchTerm struct {
+	words    []string // normalized words
+	excluded bool
+}
+
+// searchToken is normalized word of text with its position in text
+type searchToken struct {
+	key        string
+	start, end int
+}
+
+// parseSearchQuery splits query into alternatives, every alternative is list of terms
+func parseSearchQuery(query string) [][]searchTerm {
+	alternatives := [][]searchTerm{nil}
+	for i, part := range strings.Split(query, `"`) {
+		// Every second part is inside quotes
+		if i%2 == 1 {
+			if words := tokenKeys(searchTokens(part)); len(words) > 0 {
+				last := len(alternatives) - 1
+				alternatives[last] = append(alternatives[last], searchTerm{words: words})
+			}
+			continue
+		}
+		for _, field := range strings.Fields(part) {
+			if strings.EqualFold(field, "or") {
+				alternatives = append(alternatives, nil)
+				continue
+			}
+			excluded := strings.HasPrefix(field, "-")
+			if words := tokenKeys(searchTokens(field)); len(words) > 0 {
+				last := len(alternatives) - 1
+				alternatives[last] = append(alternatives[last], searchTerm{words: words, excluded: excluded})
+			}
+		}
+	}
+	return alternatives
+}
+
+// searchTokens splits text into words
+func searchTokens(text string) []searchToken {
+	var tokens []searchToken
+	start := -1
+	for i, r := range text + " " {
+		inWord := unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.Is(unicode.Mn, r)
+		switch {
+		case inWord && start < 0:
+			start = i
+		case !inWord && start >= 0:
+			tokens = append(tokens, searchToken{key: textutil.SearchKey(text[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+	return tokens
+}
+
+func tokenKeys(tokens []searchToken) []string {
+	keys := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		keys = append(keys, t.key)
+	}
+	return keys
+}
+
+// matchTerms checks if tokens contain all terms but excluded ones, returns positions of matched tokens
+func matchTerms(tokens []searchToken, terms []searchTerm) ([]int, bool) {
+	var positions []int
+	included := 0
+	for _, term := range terms {
+		var found []int
+		for start := 0; start+len(term.words) <= len(tokens); start++ {
+			if slices.Equal(tokenKeys(tokens[start:start+len(term.words)]), term.words) {
+				for i := range term.words {
+					found = append(found, start+i)
+				}
+			}
+		}
+		if term.excluded {
+			if len(found) > 0 {
+				return nil, false
+			}
+			continue
+		}
+		if len(found) == 0 {
+			return nil, false
+		}
+		included++
+		positions = append(positions, found...)
+	}
+	return positions, included > 0
+}
+
+// highlight wraps matched tokens of text in <b></b>
+func highlight(text string, tokens []searchToken, matched map[int]bool) string {
+	var b strings.Builder
+	last := 0
+	for i, t := range tokens {
+		if !matched[i] {
+			continue
+		}
+		b.WriteString(text[last:t.start])
+		b.WriteString("<b>" + text[t.start:t.end] + "</b>")
+		last = t.end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
@@ -0,0 +1,233 @@
+package repository_test
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tdawidzi/dictionary_app/config"
+	"github.com/tdawidzi/dictionary_app/models"
+	"github.com/tdawidzi/dictionary_app/repository"
+	"github.com/tdawidzi/dictionary_app/utils"
+	"gorm.io/gorm"
+)
+
+func newSQLiteStore(t *testing.T) repository.Store {
+	t.Helper()
+
+	return repository.NewGormStore(openSQLite(t))
+}
+
+func openSQLite(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := utils.ConnectDB(&config.Config{
+		DB_Driver: config.DriverSQLite,
+		DB_Path:   filepath.Join(t.TempDir(), "dictionary.db"),
+	})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	sqlDB, _ := db.DB()
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+func TestSQLiteConstraints(t *testing.T) {
+	store := newSQLiteStore(t)
+	kot := createWord(t, store, "kot", "pl")
+	cat := createWord(t, store, "cat", "en")
+
+	// Composite unique index
+	again := models.Word{Word: "kot", Language: "pl"}
+	created, err := store.Words().Create(&again)
+	assert.NoError(t, err)
+	assert.False(t, created)
+	second := models.Word{Word: "kot", Language: "pl", Homograph: 2}
+	created, err = store.Words().Create(&second)
+	assert.NoError(t, err)
+	assert.True(t, created)
+	second.Homograph = 1
+	assert.ErrorIs(t, store.Words().Save(&second), repository.ErrDuplicate)
+
+	// Words only in registered languages
+	_, err = store.Words().Create(&models.Word{Word: "gato", Language: "es"})
+	assert.Error(t, err)
+
+	// Cascade deletes
+	sense := models.Sense{WordID: kot.ID, Ordinal: 1, Definition: "zwierzę"}
+	assert.NoError(t, store.Senses().Create(&sense))
+	assert.NoError(t, store.Translations().Create(&models.Translation{SourceWordID: kot.ID, TargetWordID: cat.ID, SourceSenseID: &sense.ID}))
+	_, err = store.Examples().Create(&models.Example{WordID: kot.ID, Example: "Ala ma kota.", SenseID: &sense.ID})
+	assert.NoError(t, err)
+
+	assert.NoError(t, store.Senses().Delete(sense.ID))
+	translations, err := store.Translations().ForWords([]uint{kot.ID})
+	assert.NoError(t, err)
+	if assert.Len(t, translations, 1) {
+		assert.Nil(t, translations[0].SourceSenseID)
+	}
+
+	assert.NoError(t, store.Words().Delete(kot.ID))
+	translations, _ = store.Translations().ForWords([]uint{cat.ID})
+	assert.Empty(t, translations)
+	examples, _ := store.Examples().ForWord(kot.ID)
+	assert.Empty(t, examples)
+}
+
+func TestSQLiteExampleDictionary(t *testing.T) {
+	db := openSQLite(t)
+	store := repository.NewGormStore(db)
+	other := models.Dictionary{Name: "inny"}
+	assert.NoError(t, store.Dictionaries().Create(&other))
+	kot := models.Word{Word: "kot", Language: "pl", DictionaryID: other.ID}
+	_, err := store.Words().Create(&kot)
+	assert.NoError(t, err)
+
+	// Example gets dictionary of its word, also when saved with another one
+	example := models.Example{WordID: kot.ID, Example: "Ala ma kota."}
+	_, err = store.Examples().Create(&example)
+	assert.NoError(t, err)
+	assert.Equal(t, other.ID, example.DictionaryID)
+	example.DictionaryID = models.DefaultDictionaryID
+	assert.NoError(t, store.Examples().Save(&example))
+	assert.Equal(t, other.ID, example.DictionaryID)
+
+	// Dictionary different from the one of the word is rejected
+	err = db.Exec("INSERT INTO examples (word_id, example, dictionary_id) VALUES (?, ?, ?)", kot.ID, "Kot śpi.", models.DefaultDictionaryID).Error
+	assert.Error(t, err)
+	err = db.Exec("UPDATE examples SET dictionary_id = ? WHERE id = ?", models.DefaultDictionaryID, example.ID).Error
+	assert.Error(t, err)
+
+	// Moved word takes its examples along
+	assert.NoError(t, db.Exec("UPDATE words SET dictionary_id = ? WHERE id = ?", models.DefaultDictionaryID, kot.ID).Error)
+	saved, err := store.Examples().Get(example.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.DefaultDictionaryID, saved.DictionaryID)
+}
+
+func TestSQLiteTranslationDictionary(t *testing.T) {
+	db := openSQLite(t)
+	store := repository.NewGormStore(db)
+	other := models.Dictionary{Name: "inny"}
+	assert.NoError(t, store.Dictionaries().Create(&other))
+	kot := models.Word{Word: "kot", Language: "pl", DictionaryID: other.ID}
+	_, err := store.Words().Create(&kot)
+	assert.NoError(t, err)
+	cat := models.Word{Word: "cat", Language: "en", DictionaryID: other.ID}
+	_, err = store.Words().Create(&cat)
+	assert.NoError(t, err)
+	dog := createWord(t, store, "dog", "en")
+
+	// Words of translation are in its dictionary
+	translation := models.Translation{SourceWordID: kot.ID, TargetWordID: cat.ID}
+	assert.NoError(t, store.Translations().Create(&translation))
+	assert.Equal(t, other.ID, translation.DictionaryID)
+	err = db.Exec("INSERT INTO translations (source_word_id, target_word_id, dictionary_id) VALUES (?, ?, ?)", kot.ID, dog.ID, other.ID).Error
+	assert.Error(t, err)
+	err = db.Exec("UPDATE translations SET target_word_id = ? WHERE id = ?", dog.ID, translation.ID).Error
+	assert.Error(t, err)
+
+	// Word with translations cannot be moved to another dictionary
+	err = db.Exec("UPDATE words SET dictionary_id = ? WHERE id = ?", models.DefaultDictionaryID, kot.ID).Error
+	assert.Error(t, err)
+	assert.NoError(t, db.Exec("DELETE FROM translations WHERE id = ?", translation.ID).Error)
+	assert.NoError(t, db.Exec("UPDATE words SET dictionary_id = ? WHERE id = ?", models.DefaultDictionaryID, kot.ID).Error)
+}
+
+func TestSQLiteConcurrentTransactions(t *testing.T) {
+	store := newSQLiteStore(t)
+
+	// Writers wait for each other instead of failing with "database is locked"
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := store.Transaction(func(tx repository.Store) error {
+				_, err := tx.Words().Create(&models.Word{Word: "kot", Language: "pl"})
+				return err
+			})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	count, err := store.Words().Count(repository.WordFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestSQLiteSearch(t *testing.T) {
+	store := newSQLiteStore(t)
+	cat := createWord(t, store, "cat", "en")
+	kot := createWord(t, store, "kot", "pl")
+	createWord(t, store, "100%", "pl")
+	for _, e := range []models.Example{
+		{WordID: cat.ID, Example: "The black cat sleeps."},
+		{WordID: cat.ID, Example: "A cat and a dog."},
+		{WordID: kot.ID, Example: "Czarny kot śpi."},
+	} {
+		_, err := store.Examples().Create(&e)
+		assert.NoError(t, err)
+	}
+
+	// Full-text search falls back to matching words of examples
+	hits, err := store.Examples().Search(`"black cat"`, "", nil, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, hits, 1) {
+		assert.Equal(t, "The <b>black</b> <b>cat</b> sleeps.", hits[0].Highlighted)
+		assert.Equal(t, "cat", hits[0].Example.Word.Word)
+	}
+	hits, err = store.Examples().Search("spi", "en", nil, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, hits)
+
+	exists, err := store.Languages().SearchConfigExists("english")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	// LIKE wildcards are matched literally
+	completions, err := store.Words().Complete("100%", "", nil, 10)
+	assert.NoError(t, err)
+	assert.Len(t, completions, 1)
+	completions, err = store.Words().Complete("1_0", "", nil, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, completions)
+
+}
+
+func TestSQLiteAPIKeys(t *testing.T) {
+	keys := newSQLiteStore(t).APIKeys()
+
+	assert.NoError(t, keys.Create(&models.APIKey{Name: "importer", Hash: "a1"}))
+	assert.ErrorIs(t, keys.Create(&models.APIKey{Name: "importer", Hash: "b2"}), repository.ErrDuplicate)
+	key, err := keys.FindByHash("a1")
+	assert.NoError(t, err)
+	assert.Equal(t, "importer", key.Name)
+
+	assert.NoError(t, keys.Delete("importer"))
+	assert.ErrorIs(t, keys.Delete("importer"), repository.ErrNotFound)
+	_, err = keys.FindByHash("a1")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+}
+
+func TestSQLiteUsers(t *testing.T) {
+	users := newSQLiteStore(t).Users()
+
+	ola := models.User{Name: "ola", Role: models.RoleEditor}
+	assert.NoError(t, users.Create(&ola))
+	assert.ErrorIs(t, users.Create(&models.User{Name: "ola", Role: models.RoleAdmin}), repository.ErrDuplicate)
+	assert.Error(t, users.Create(&models.User{Name: "ala", Role: "owner"}))
+
+	ola.Role = models.RoleAdmin
+	assert.NoError(t, users.Save(&ola))
+	found, err := users.Find("ola")
+	assert.NoError(t, err)
+	assert.Equal(t, models.RoleAdmin, found.Role)
+
+	assert.NoError(t, users.Delete(ola.ID))
+	_, err = users.Find("ola")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+}
@@ -0,0 +1,63 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tdawidzi/dictionary_app/models"
+	"github.com/tdawidzi/dictionary_app/repository"
+)
+
+func TestMemorySimilar(t *testing.T) {
+	testSimilar(t, newMemoryStore(t))
+}
+
+func TestSQLiteSimilar(t *testing.T) {
+	testSimilar(t, newSQLiteStore(t))
+}
+
+// testSimilar checks that without trigram index candidates are bounded - alphabetical neighbours starting with the same letter
+func testSimilar(t *testing.T, store repository.Store) {
+	words := map[string]models.Word{}
+	for _, w := range []string{"kat", "kit", "kot", "kotek", "kura", "lot"} {
+		words[w] = createWord(t, store, w, "pl")
+	}
+
+	similar, err := store.Words().Similar("kott", "pl", nil, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{words["kot"].ID, words["kotek"].ID, words["kura"].ID}, wordIDs(similar))
+
+	similar, err = store.Words().Similar("kaat", "pl", nil, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{words["kat"].ID, words["kit"].ID}, wordIDs(similar))
+
+	similar, err = store.Words().Similar("kot", "en", nil, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, similar)
+}
+
+func TestMemoryComplete(t *testing.T) {
+	testComplete(t, newMemoryStore(t))
+}
+
+func TestSQLiteComplete(t *testing.T) {
+	testComplete(t, newSQLiteStore(t))
+}
+
+// testComplete checks that exact match goes first, then words in order of search keys, at most limit of them
+func testComplete(t *testing.T, store repository.Store) {
+	words := map[string]models.Word{}
+	for _, w := range []string{"kotek", "koła", "kot", "kat"} {
+		words[w] = createWord(t, store, w, "pl")
+	}
+
+	completions, err := store.Words().Complete("ko", "pl", nil, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{words["koła"].ID, words["kot"].ID, words["kotek"].ID}, wordIDs(completions))
+	completions, err = store.Words().Complete("kot", "", nil, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{words["kot"].ID, words["kotek"].ID}, wordIDs(completions))
+	completions, err = store.Words().Complete("ko", "", nil, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{words["koła"].ID}, wordIDs(completions))
+}
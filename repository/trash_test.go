@@ -0,0 +1,98 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tdawidzi/dictionary_app/models"
+	"github.com/tdawidzi/dictionary_app/repository"
+)
+
+func TestMemoryTrash(t *testing.T) {
+	testTrash(t, newMemoryStore(t))
+}
+
+func TestSQLiteTrash(t *testing.T) {
+	testTrash(t, newSQLiteStore(t))
+}
+
+// testTrash checks soft deletion, restore and purge - the same in every store
+func testTrash(t *testing.T, store repository.Store) {
+	kot := createWord(t, store, "kot", "pl")
+	cat := createWord(t, store, "cat", "en")
+	pies := createWord(t, store, "pies", "pl")
+	assert.False(t, kot.CreatedAt.IsZero())
+	assert.NoError(t, store.Translations().Create(&models.Translation{SourceWordID: kot.ID, TargetWordID: cat.ID}))
+	assert.NoError(t, store.Translations().Create(&models.Translation{SourceWordID: pies.ID, TargetWordID: cat.ID}))
+	kotExample := models.Example{WordID: kot.ID, Example: "Ala ma kota."}
+	_, err := store.Examples().Create(&kotExample)
+	assert.NoError(t, err)
+	catExample := models.Example{WordID: cat.ID, Example: "The cat sleeps."}
+	_, err = store.Examples().Create(&catExample)
+	assert.NoError(t, err)
+
+	// Example deleted alone, then word with its translations and examples
+	assert.NoError(t, store.Examples().Delete(catExample.ID))
+	assert.NoError(t, store.Words().Delete(cat.ID))
+	assert.ErrorIs(t, store.Words().Delete(cat.ID), repository.ErrNotFound)
+	words, _ := store.Words().Find(repository.WordLookup{Word: "cat"})
+	assert.Empty(t, words)
+	translations, _ := store.Translations().ForWords([]uint{kot.ID, pies.ID})
+	assert.Empty(t, translations)
+
+	deletedWords, err := store.Words().Deleted(nil, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, deletedWords, 1) {
+		assert.True(t, deletedWords[0].DeletedAt.Valid)
+	}
+	deletedTranslations, err := store.Translations().Deleted(nil, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, deletedTranslations, 2) {
+		assert.Equal(t, "cat", deletedTranslations[0].TargetWord.Word)
+	}
+	deletedExamples, err := store.Examples().Deleted(nil, 10)
+	assert.NoError(t, err)
+	assert.Len(t, deletedExamples, 1)
+
+	// Example cannot be restored before its word, new word takes place of deleted one
+	_, err = store.Examples().Restore(catExample.ID)
+	assert.ErrorIs(t, err, repository.ErrWordDeleted)
+	again := createWord(t, store, "cat", "en")
+	_, err = store.Words().Restore(cat.ID)
+	assert.ErrorIs(t, err, repository.ErrDuplicate)
+
+	// Restore brings back translations deleted with the word, but not the example deleted earlier
+	assert.NoError(t, store.Words().Delete(again.ID))
+	assert.NoError(t, store.Words().Delete(pies.ID))
+	restored, err := store.Words().Restore(cat.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "cat", restored.Word)
+	assert.False(t, restored.DeletedAt.Valid)
+	translations, _ = store.Translations().ForWords([]uint{cat.ID})
+	if assert.Len(t, translations, 1) {
+		assert.Equal(t, kot.ID, translations[0].SourceWordID)
+	}
+	examples, _ := store.Examples().ForWord(cat.ID)
+	assert.Empty(t, examples)
+	example, err := store.Examples().Restore(catExample.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "cat", example.Word.Word)
+	_, err = store.Examples().Restore(catExample.ID)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	// Purge deletes only entries deleted before given time
+	purged, err := store.Words().Purge(time.Now().Add(-time.Hour), nil)
+	assert.NoError(t, err)
+	assert.Zero(t, purged)
+	purged, err = store.Translations().Purge(time.Now().Add(time.Hour), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+	purged, err = store.Words().Purge(time.Now().Add(time.Hour), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), purged)
+	deletedWords, _ = store.Words().Deleted(nil, 10)
+	assert.Empty(t, deletedWords)
+	_, err = store.Words().Restore(pies.ID)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+}
@@ -0,0 +1,66 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tdawidzi/dictionary_app/models"
+	"github.com/tdawidzi/dictionary_app/repository"
+)
+
+func TestMemoryVersions(t *testing.T) {
+	testVersions(t, newMemoryStore(t))
+}
+
+func TestSQLiteVersions(t *testing.T) {
+	testVersions(t, newSQLiteStore(t))
+}
+
+// testVersions checks that saves increment versions and saves of outdated rows are rejected
+func testVersions(t *testing.T, store repository.Store) {
+	kot := createWord(t, store, "kot", "pl")
+	cat := createWord(t, store, "cat", "en")
+	assert.Equal(t, 1, kot.Version)
+
+	outdated := kot
+	kot.PartOfSpeech = models.PartOfSpeechNoun
+	assert.NoError(t, store.Words().Save(&kot))
+	assert.Equal(t, 2, kot.Version)
+	outdated.Gender = models.GenderMasculineAnimate
+	assert.ErrorIs(t, store.Words().Save(&outdated), repository.ErrStaleVersion)
+	assert.Equal(t, 1, outdated.Version)
+
+	word, err := store.Words().GetForUpdate(kot.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, word.Version)
+	assert.Equal(t, models.PartOfSpeechNoun, word.PartOfSpeech)
+	assert.Empty(t, word.Gender)
+
+	sense := models.Sense{WordID: kot.ID, Ordinal: 1, Definition: "zwierzę"}
+	assert.NoError(t, store.Senses().Create(&sense))
+	assert.Equal(t, 1, sense.Version)
+	outdatedSense := sense
+	sense.Domain = "zoologia"
+	assert.NoError(t, store.Senses().Save(&sense))
+	assert.Equal(t, 2, sense.Version)
+	assert.ErrorIs(t, store.Senses().Save(&outdatedSense), repository.ErrStaleVersion)
+
+	translation := models.Translation{SourceWordID: kot.ID, TargetWordID: cat.ID}
+	assert.NoError(t, store.Translations().Create(&translation))
+	assert.Equal(t, 1, translation.Version)
+	outdatedTranslation := translation
+	translation.SourceSenseID = &sense.ID
+	assert.NoError(t, store.Translations().Save(&translation))
+	assert.Equal(t, 2, translation.Version)
+	assert.ErrorIs(t, store.Translations().Save(&outdatedTranslation), repository.ErrStaleVersion)
+
+	example := models.Example{WordID: kot.ID, Example: "Ala ma kota."}
+	_, err = store.Examples().Create(&example)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, example.Version)
+	outdatedExample := example
+	example.Example = "Ala ma dwa koty."
+	assert.NoError(t, store.Examples().Save(&example))
+	assert.Equal(t, 2, example.Version)
+	assert.ErrorIs(t, store.Examples().Save(&outdatedExample), repository.ErrStaleVersion)
+}
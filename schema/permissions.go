@@ -0,0 +1,66 @@
+package schema
+
+import (
+	"github.com/graphql-go/graphql"
+	"github.com/tdawidzi/dictionary_app/apperrors"
+	"github.com/tdawidzi/dictionary_app/auth"
+	"github.com/tdawidzi/dictionary_app/models"
+)
+
+// Roles required by fields of root types (see models.Roles). Queries not listed here are available to everyone,
+// mutations not listed here need admin role - new mutations are not open to editors by mistake.
+var queryRoles = map[string]string{
+	"trash":    models.RoleAdmin,
+	"history":  models.RoleAdmin,
+	"auditLog": models.RoleAdmin,
+	"users":    models.RoleAdmin,
+}
+
+var mutationRoles = map[string]string{
+	"addWord":           models.RoleEditor,
+	"updateWord":        models.RoleEditor,
+	"setParadigm":       models.RoleEditor,
+	"addSense":          models.RoleEditor,
+	"updateSense":       models.RoleEditor,
+	"addTranslation":    models.RoleEditor,
+	"updateTranslation": models.RoleEditor,
+	"addExample":        models.RoleEditor,
+	"updateExample":     models.RoleEditor,
+
+	"addLanguage":       models.RoleAdmin,
+	"addDictionary":     models.RoleAdmin,
+	"deleteWord":        models.RoleAdmin,
+	"restoreWord":       models.RoleAdmin,
+	"deleteSense":       models.RoleAdmin,
+	"deleteTranslation": models.RoleAdmin,
+	"deleteExample":     models.RoleAdmin,
+	"restoreExample":    models.RoleAdmin,
+	"revertChange":      models.RoleAdmin,
+	"purgeTrash":        models.RoleAdmin,
+	"setUserRole":       models.RoleAdmin,
+	"deleteUser":        models.RoleAdmin,
+}
+
+// withRoles wraps resolvers of given fields, so they check role of request principal
+func withRoles(fields graphql.Fields, roles map[string]string, defaultRole string) graphql.Fields {
+	for name, field := range fields {
+		role, ok := roles[name]
+		if !ok {
+			role = defaultRole
+		}
+		if role != models.RoleViewer {
+			field.Resolve = allow(role, field.Resolve)
+		}
+	}
+	return fields
+}
+
+// allow wraps resolver of field available only to given role (and higher ones)
+func allow(role string, resolve graphql.FieldResolveFn) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		if !auth.Allows(auth.RoleFrom(p.Context), role) {
+			return nil, apperrors.Forbidden("%s role required", role)
+		}
+		return resolve(p)
+	}
+}
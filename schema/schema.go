@@ -1,32 +1,148 @@
 package schema
 
 import (
+	"strings"
+
 	"github.com/graphql-go/graphql"
 	"github.com/tdawidzi/dictionary_app/handlers"
+	"github.com/tdawidzi/dictionary_app/models"
 )
 
-var Schema *graphql.Schema
+// Enums do not depend on handlers, they are shared by all schemas
+var wordSortEnum *graphql.Enum
+var trashEntryTypeEnum *graphql.Enum
+var auditEntityEnum *graphql.Enum
+var auditOperationEnum *graphql.Enum
+var roleEnum *graphql.Enum
+
+// Grammatical attributes of words
+var partOfSpeechEnum *graphql.Enum
+var genderEnum *graphql.Enum
+var aspectEnum *graphql.Enum
+var countabilityEnum *graphql.Enum
+var transitivityEnum *graphql.Enum
 
-// Zadeklaruj zmienne typów
-var wordType *graphql.Object
-var translationType *graphql.Object
-var exampleType *graphql.Object
+// Inflection
+var caseEnum *graphql.Enum
+var numberEnum *graphql.Enum
+var personEnum *graphql.Enum
+var tenseEnum *graphql.Enum
+var moodEnum *graphql.Enum
+var degreeEnum *graphql.Enum
+var formGenderEnum *graphql.Enum
 
 func init() {
-	initTypes()
+	partOfSpeechEnum = newWordAttributeEnum("PartOfSpeech", models.PartsOfSpeech)
+	genderEnum = newWordAttributeEnum("Gender", models.Genders)
+	aspectEnum = newWordAttributeEnum("Aspect", models.Aspects)
+	countabilityEnum = newWordAttributeEnum("Countability", models.Countabilities)
+	transitivityEnum = newWordAttributeEnum("Transitivity", models.Transitivities)
+	caseEnum = newAttributeEnum("Case", models.Cases)
+	numberEnum = newAttributeEnum("Number", models.Numbers)
+	personEnum = newAttributeEnum("Person", models.Persons)
+	tenseEnum = newAttributeEnum("Tense", models.Tenses)
+	moodEnum = newAttributeEnum("Mood", models.Moods)
+	degreeEnum = newAttributeEnum("Degree", models.Degrees)
+	formGenderEnum = newAttributeEnum("FormGender", models.FormGenders)
+	wordSortEnum = newAttributeEnum("WordSort", handlers.WordSorts)
+	trashEntryTypeEnum = newAttributeEnum("TrashEntryType", handlers.TrashEntryTypes)
+	auditEntityEnum = newAttributeEnum("AuditEntity", models.AuditEntities)
+	auditOperationEnum = newAttributeEnum("AuditOperation", models.AuditOperations)
+	roleEnum = newAttributeEnum("Role", models.Roles)
+}
+
+// builder holds object types of schema resolved by given handlers
+type builder struct {
+	h *handlers.Handlers
+
+	languageType     *graphql.Object
+	dictionaryType   *graphql.Object
+	wordType         *graphql.Object
+	translationType  *graphql.Object
+	exampleType      *graphql.Object
+	senseType        *graphql.Object
+	completionType   *graphql.Object
+	exampleMatchType *graphql.Object
+	trashEntryType   *graphql.Object
+	auditEntryType   *graphql.Object
+	purgeResultType  *graphql.Object
+	userType         *graphql.Object
 
-	schema, err := graphql.NewSchema(graphql.SchemaConfig{
-		Query:    buildRootQuery(),
-		Mutation: buildRootMutation(),
+	// Pagination of words
+	pageInfoType       *graphql.Object
+	wordEdgeType       *graphql.Object
+	wordConnectionType *graphql.Object
+
+	// Inflection
+	wordFormType      *graphql.Object
+	wordFormInputType *graphql.InputObject
+}
+
+// New builds GraphQL schema with fields resolved by given handlers
+func New(h *handlers.Handlers) (graphql.Schema, error) {
+	b := &builder{h: h}
+	b.initTypes()
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:    b.buildRootQuery(),
+		Mutation: b.buildRootMutation(),
 	})
-	if err != nil {
-		panic(err)
-	}
-	Schema = &schema
 }
 
-func initTypes() {
-	wordType = graphql.NewObject(graphql.ObjectConfig{
+func (b *builder) initTypes() {
+	b.wordFormType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "WordForm",
+		Fields: graphql.Fields{
+			"id":     &graphql.Field{Type: graphql.Int},
+			"form":   &graphql.Field{Type: graphql.String},
+			"case":   &graphql.Field{Type: caseEnum},
+			"number": &graphql.Field{Type: numberEnum},
+			"person": &graphql.Field{Type: personEnum},
+			"tense":  &graphql.Field{Type: tenseEnum},
+			"mood":   &graphql.Field{Type: moodEnum},
+			"aspect": &graphql.Field{Type: aspectEnum},
+			"gender": &graphql.Field{Type: formGenderEnum},
+			"degree": &graphql.Field{Type: degreeEnum},
+		},
+	})
+
+	b.wordFormInputType = graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "WordFormInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"form":   &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"case":   &graphql.InputObjectFieldConfig{Type: caseEnum},
+			"number": &graphql.InputObjectFieldConfig{Type: numberEnum},
+			"person": &graphql.InputObjectFieldConfig{Type: personEnum},
+			"tense":  &graphql.InputObjectFieldConfig{Type: tenseEnum},
+			"mood":   &graphql.InputObjectFieldConfig{Type: moodEnum},
+			"aspect": &graphql.InputObjectFieldConfig{Type: aspectEnum},
+			"gender": &graphql.InputObjectFieldConfig{Type: formGenderEnum},
+			"degree": &graphql.InputObjectFieldConfig{Type: degreeEnum},
+		},
+	})
+
+	b.languageType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Language",
+		Fields: graphql.Fields{
+			"code":         &graphql.Field{Type: graphql.String},
+			"name":         &graphql.Field{Type: graphql.String},
+			"script":       &graphql.Field{Type: graphql.String},
+			"direction":    &graphql.Field{Type: graphql.String},
+			"searchConfig": &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	b.dictionaryType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Dictionary",
+		Fields: graphql.Fields{
+			"id":          &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"name":        &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"description": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"createdAt":   &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+		},
+	})
+
+	b.wordType = graphql.NewObject(graphql.ObjectConfig{
 		Name: "Word",
 		Fields: graphql.FieldsThunk(func() graphql.Fields {
 			return graphql.Fields{
@@ -39,79 +155,404 @@ func initTypes() {
 				"language": &graphql.Field{
 					Type: graphql.String,
 				},
+				"homograph": &graphql.Field{
+					Type: graphql.Int,
+				},
+				"partOfSpeech": &graphql.Field{
+					Type: partOfSpeechEnum,
+				},
+				"gender": &graphql.Field{
+					Type: genderEnum,
+				},
+				"aspect": &graphql.Field{
+					Type: aspectEnum,
+				},
+				"countability": &graphql.Field{
+					Type: countabilityEnum,
+				},
+				"transitivity": &graphql.Field{
+					Type: transitivityEnum,
+				},
+				"version": &graphql.Field{
+					Type: graphql.Int,
+				},
+				"dictionary": &graphql.Field{
+					Type:    b.dictionaryType,
+					Resolve: b.h.GetDictionaryForWord,
+				},
 				"translations": &graphql.Field{
-					Type:    graphql.NewList(wordType),
-					Resolve: handlers.GetTranslationsForWord,
+					Type: graphql.NewList(b.wordType),
+					Args: graphql.FieldConfigArgument{
+						"language": &graphql.ArgumentConfig{
+							Type: graphql.String,
+						},
+					},
+					Resolve: b.h.GetTranslationsForWord,
+				},
+				"senses": &graphql.Field{
+					Type:    graphql.NewList(b.senseType),
+					Resolve: b.h.GetSensesForWord,
+				},
+				"forms": &graphql.Field{
+					Type:    graphql.NewList(b.wordFormType),
+					Resolve: b.h.GetFormsForWord,
+				},
+				"createdAt": &graphql.Field{
+					Type: graphql.DateTime,
+				},
+				"updatedAt": &graphql.Field{
+					Type: graphql.DateTime,
 				},
 			}
 		}),
 	})
 
-	translationType = graphql.NewObject(graphql.ObjectConfig{
+	b.translationType = graphql.NewObject(graphql.ObjectConfig{
 		Name: "Translation",
 		Fields: graphql.Fields{
-			"id":    &graphql.Field{Type: graphql.Int},
-			"id_pl": &graphql.Field{Type: graphql.Int},
-			"id_en": &graphql.Field{Type: graphql.Int},
+			"id":            &graphql.Field{Type: graphql.Int},
+			"sourceWordId":  &graphql.Field{Type: graphql.Int},
+			"targetWordId":  &graphql.Field{Type: graphql.Int},
+			"sourceSenseId": &graphql.Field{Type: graphql.Int},
+			"targetSenseId": &graphql.Field{Type: graphql.Int},
+			"version":       &graphql.Field{Type: graphql.Int},
+			"createdAt":     &graphql.Field{Type: graphql.DateTime},
+			"updatedAt":     &graphql.Field{Type: graphql.DateTime},
 		},
 	})
 
-	exampleType = graphql.NewObject(graphql.ObjectConfig{
+	b.exampleType = graphql.NewObject(graphql.ObjectConfig{
 		Name: "Example",
 		Fields: graphql.Fields{
-			"id":      &graphql.Field{Type: graphql.Int},
-			"example": &graphql.Field{Type: graphql.String},
+			"id":        &graphql.Field{Type: graphql.Int},
+			"example":   &graphql.Field{Type: graphql.String},
+			"senseId":   &graphql.Field{Type: graphql.Int},
+			"version":   &graphql.Field{Type: graphql.Int},
+			"createdAt": &graphql.Field{Type: graphql.DateTime},
+			"updatedAt": &graphql.Field{Type: graphql.DateTime},
+		},
+	})
+
+	b.senseType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Sense",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":         &graphql.Field{Type: graphql.Int},
+				"ordinal":    &graphql.Field{Type: graphql.Int},
+				"definition": &graphql.Field{Type: graphql.String},
+				"domain":     &graphql.Field{Type: graphql.String},
+				"version":    &graphql.Field{Type: graphql.Int},
+				"translations": &graphql.Field{
+					Type:    graphql.NewList(b.wordType),
+					Resolve: b.h.GetTranslationsForSense,
+				},
+				"examples": &graphql.Field{
+					Type:    graphql.NewList(b.exampleType),
+					Resolve: b.h.GetExamplesForSense,
+				},
+			}
+		}),
+	})
+
+	b.exampleMatchType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "ExampleMatch",
+		Fields: graphql.Fields{
+			"example":     &graphql.Field{Type: b.exampleType},
+			"word":        &graphql.Field{Type: b.wordType},
+			"highlighted": &graphql.Field{Type: graphql.String},
+			"rank":        &graphql.Field{Type: graphql.Float},
+		},
+	})
+
+	b.trashEntryType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "TrashEntry",
+		Fields: graphql.Fields{
+			"type":       &graphql.Field{Type: graphql.NewNonNull(trashEntryTypeEnum)},
+			"id":         &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"deletedAt":  &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+			"word":       &graphql.Field{Type: b.wordType},
+			"targetWord": &graphql.Field{Type: b.wordType},
+			"example":    &graphql.Field{Type: b.exampleType},
+		},
+	})
+
+	b.auditEntryType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "AuditEntry",
+		Fields: graphql.Fields{
+			"id":           &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"entity":       &graphql.Field{Type: graphql.NewNonNull(auditEntityEnum)},
+			"entityId":     &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"wordId":       &graphql.Field{Type: graphql.Int},
+			"targetWordId": &graphql.Field{Type: graphql.Int},
+			"operation":    &graphql.Field{Type: graphql.NewNonNull(auditOperationEnum)},
+			// Values of entity as JSON, null when entity did not exist before or does not exist after change
+			"before":    &graphql.Field{Type: graphql.String, Resolve: auditValues(func(e models.AuditEntry) string { return e.Before })},
+			"after":     &graphql.Field{Type: graphql.String, Resolve: auditValues(func(e models.AuditEntry) string { return e.After })},
+			"actor":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"revertOf":  &graphql.Field{Type: graphql.Int},
+			"createdAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+		},
+	})
+
+	b.purgeResultType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "PurgeResult",
+		Fields: graphql.Fields{
+			"words":        &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"translations": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"examples":     &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+
+	b.userType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.Fields{
+			"name":      &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"role":      &graphql.Field{Type: graphql.NewNonNull(roleEnum)},
+			"createdAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+			"updatedAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+		},
+	})
+
+	b.pageInfoType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "PageInfo",
+		Fields: graphql.Fields{
+			"hasNextPage":     &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"hasPreviousPage": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"startCursor":     &graphql.Field{Type: graphql.String},
+			"endCursor":       &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	b.wordEdgeType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "WordEdge",
+		Fields: graphql.Fields{
+			"cursor": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"node":   &graphql.Field{Type: b.wordType},
+		},
+	})
+
+	b.wordConnectionType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "WordConnection",
+		Fields: graphql.Fields{
+			"edges":      &graphql.Field{Type: graphql.NewList(b.wordEdgeType)},
+			"pageInfo":   &graphql.Field{Type: graphql.NewNonNull(b.pageInfoType)},
+			"totalCount": &graphql.Field{Type: graphql.Int},
+		},
+	})
+
+	b.completionType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Completion",
+		Fields: graphql.Fields{
+			"word": &graphql.Field{Type: graphql.NewNonNull(b.wordType)},
+			"translation": &graphql.Field{
+				Type:    b.wordType,
+				Resolve: b.h.GetCompletionTranslation,
+			},
 		},
 	})
 }
 
-func buildRootQuery() *graphql.Object {
+func (b *builder) buildRootQuery() *graphql.Object {
 	return graphql.NewObject(graphql.ObjectConfig{
 		Name: "Query",
-		Fields: graphql.Fields{
+		Fields: withRoles(graphql.Fields{
+			"languages": &graphql.Field{
+				Type:    graphql.NewList(b.languageType),
+				Resolve: b.h.GetLanguages,
+			},
+			"dictionaries": &graphql.Field{
+				Type:    graphql.NewList(b.dictionaryType),
+				Resolve: b.h.GetDictionaries,
+			},
 			"words": &graphql.Field{
-				Type:    graphql.NewList(wordType),
-				Resolve: handlers.GetWords,
+				Type: b.wordConnectionType,
+				Args: withWordAttributeArgs(withDictionariesArg(graphql.FieldConfigArgument{
+					"first": &graphql.ArgumentConfig{
+						Type: graphql.Int,
+					},
+					"after": &graphql.ArgumentConfig{
+						Type: graphql.String,
+					},
+					"sort": &graphql.ArgumentConfig{
+						Type: wordSortEnum,
+					},
+					"language": &graphql.ArgumentConfig{
+						Type: graphql.String,
+					},
+					"prefix": &graphql.ArgumentConfig{
+						Type: graphql.String,
+					},
+					"hasTranslations": &graphql.ArgumentConfig{
+						Type: graphql.Boolean,
+					},
+				})),
+				Resolve: b.h.GetWords,
 			},
 			"examplesForWord": &graphql.Field{
-				Type: graphql.NewList(exampleType),
-				Args: graphql.FieldConfigArgument{
+				Type: graphql.NewList(b.exampleType),
+				Args: withDictionariesArg(graphql.FieldConfigArgument{
 					"word": &graphql.ArgumentConfig{
 						Type: graphql.String,
 					},
-				},
-				Resolve: handlers.GetExamplesForWord,
+					"language": &graphql.ArgumentConfig{
+						Type: graphql.String,
+					},
+					"homograph": &graphql.ArgumentConfig{
+						Type: graphql.Int,
+					},
+					"exact": &graphql.ArgumentConfig{
+						Type: graphql.Boolean,
+					},
+				}),
+				Resolve: b.h.GetExamplesForWord,
+			},
+			"searchExamples": &graphql.Field{
+				Type: graphql.NewList(b.exampleMatchType),
+				Args: withDictionariesArg(graphql.FieldConfigArgument{
+					"query": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.String),
+					},
+					"language": &graphql.ArgumentConfig{
+						Type: graphql.String,
+					},
+					"limit": &graphql.ArgumentConfig{
+						Type: graphql.Int,
+					},
+				}),
+				Resolve: b.h.SearchExamples,
+			},
+			"autocomplete": &graphql.Field{
+				Type: graphql.NewList(b.completionType),
+				Args: withDictionariesArg(graphql.FieldConfigArgument{
+					"prefix": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.String),
+					},
+					"language": &graphql.ArgumentConfig{
+						Type: graphql.String,
+					},
+					"targetLanguage": &graphql.ArgumentConfig{
+						Type: graphql.String,
+					},
+					"limit": &graphql.ArgumentConfig{
+						Type: graphql.Int,
+					},
+				}),
+				Resolve: b.h.Autocomplete,
+			},
+			"suggest": &graphql.Field{
+				Type: graphql.NewList(b.wordType),
+				Args: withDictionariesArg(graphql.FieldConfigArgument{
+					"word": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.String),
+					},
+					"language": &graphql.ArgumentConfig{
+						Type: graphql.String,
+					},
+					"limit": &graphql.ArgumentConfig{
+						Type: graphql.Int,
+					},
+				}),
+				Resolve: b.h.GetSuggestions,
 			},
 			"word": &graphql.Field{
-				Type: wordType,
-				Args: graphql.FieldConfigArgument{
+				Type: b.wordType,
+				Args: withDictionariesArg(graphql.FieldConfigArgument{
 					"word": &graphql.ArgumentConfig{
 						Type: graphql.NewNonNull(graphql.String),
 					},
+					"language": &graphql.ArgumentConfig{
+						Type: graphql.String,
+					},
+					"homograph": &graphql.ArgumentConfig{
+						Type: graphql.Int,
+					},
+					"exact": &graphql.ArgumentConfig{
+						Type: graphql.Boolean,
+					},
+				}),
+				Resolve: b.h.GetWordByText,
+			},
+			"trash": &graphql.Field{
+				Type: graphql.NewList(b.trashEntryType),
+				Args: withDictionariesArg(graphql.FieldConfigArgument{
+					"limit": &graphql.ArgumentConfig{
+						Type: graphql.Int,
+					},
+				}),
+				Resolve: b.h.GetTrash,
+			},
+			"history": &graphql.Field{
+				Type: graphql.NewList(b.auditEntryType),
+				Args: graphql.FieldConfigArgument{
+					"wordId": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.Int),
+					},
 				},
-				Resolve: handlers.GetWordByText,
+				Resolve: b.h.GetHistory,
 			},
-		},
+			"auditLog": &graphql.Field{
+				Type: graphql.NewList(b.auditEntryType),
+				Args: graphql.FieldConfigArgument{
+					"since": &graphql.ArgumentConfig{
+						Type: graphql.DateTime,
+					},
+					"actor": &graphql.ArgumentConfig{
+						Type: graphql.String,
+					},
+					"limit": &graphql.ArgumentConfig{
+						Type: graphql.Int,
+					},
+				},
+				Resolve: b.h.GetAuditLog,
+			},
+			"users": &graphql.Field{
+				Type:    graphql.NewList(b.userType),
+				Resolve: b.h.GetUsers,
+			},
+		}, queryRoles, models.RoleViewer),
 	})
 }
 
-func buildRootMutation() *graphql.Object {
+func (b *builder) buildRootMutation() *graphql.Object {
 	return graphql.NewObject(graphql.ObjectConfig{
 		Name: "Mutation",
-		Fields: graphql.Fields{
-			// Add a new word
-			"addWord": &graphql.Field{
-				Type: wordType,
+		Fields: withRoles(graphql.Fields{
+			// Register a new language
+			"addLanguage": &graphql.Field{
+				Type: b.languageType,
+				Args: graphql.FieldConfigArgument{
+					"code":         &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"name":         &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"script":       &graphql.ArgumentConfig{Type: graphql.String},
+					"direction":    &graphql.ArgumentConfig{Type: graphql.String},
+					"searchConfig": &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: b.h.AddLanguage,
+			},
+			// Create a new dictionary
+			"addDictionary": &graphql.Field{
+				Type: b.dictionaryType,
 				Args: graphql.FieldConfigArgument{
-					"word":     &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
-					"language": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"name":        &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"description": &graphql.ArgumentConfig{Type: graphql.String},
 				},
-				Resolve: handlers.AddWord,
+				Resolve: b.h.AddDictionary,
+			},
+
+			// Add a new word
+			"addWord": &graphql.Field{
+				Type: b.wordType,
+				Args: withWordAttributeArgs(withDictionaryArg(graphql.FieldConfigArgument{
+					"word":      &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"language":  &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"homograph": &graphql.ArgumentConfig{Type: graphql.Int},
+				})),
+				Resolve: b.h.AddWord,
 			},
 			// Update an existing word
 			"updateWord": &graphql.Field{
-				Type: wordType,
-				Args: graphql.FieldConfigArgument{
+				Type: b.wordType,
+				Args: withWordAttributeArgs(withDictionaryArg(graphql.FieldConfigArgument{
 					"oldWord": &graphql.ArgumentConfig{
 						Type: graphql.NewNonNull(graphql.String),
 					},
@@ -121,92 +562,254 @@ func buildRootMutation() *graphql.Object {
 					"language": &graphql.ArgumentConfig{
 						Type: graphql.String,
 					},
-				},
-				Resolve: handlers.UpdateWord,
+					"homograph": &graphql.ArgumentConfig{
+						Type: graphql.Int,
+					},
+					"expectedVersion": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.Int),
+					},
+				})),
+				Resolve: b.h.UpdateWord,
 			},
 
 			// Delete a word
 			"deleteWord": &graphql.Field{
 				Type: graphql.Boolean,
+				Args: withDictionaryArg(graphql.FieldConfigArgument{
+					"word": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.String),
+					},
+					"language": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.String),
+					},
+					"homograph": &graphql.ArgumentConfig{
+						Type: graphql.Int,
+					},
+				}),
+				Resolve: b.h.DeleteWord,
+			},
+			// Restore a deleted word
+			"restoreWord": &graphql.Field{
+				Type: b.wordType,
 				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.Int),
+					},
+				},
+				Resolve: b.h.RestoreWord,
+			},
+
+			// Replace all inflected forms of a word
+			"setParadigm": &graphql.Field{
+				Type: graphql.NewList(b.wordFormType),
+				Args: withDictionaryArg(graphql.FieldConfigArgument{
 					"word": &graphql.ArgumentConfig{
 						Type: graphql.NewNonNull(graphql.String),
 					},
 					"language": &graphql.ArgumentConfig{
 						Type: graphql.NewNonNull(graphql.String),
 					},
+					"homograph": &graphql.ArgumentConfig{
+						Type: graphql.Int,
+					},
+					"forms": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(b.wordFormInputType))),
+					},
+				}),
+				Resolve: b.h.SetParadigm,
+			},
+
+			// Add a new sense (meaning) of a word
+			"addSense": &graphql.Field{
+				Type: b.senseType,
+				Args: withDictionaryArg(graphql.FieldConfigArgument{
+					"word": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.String),
+					},
+					"language": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.String),
+					},
+					"homograph": &graphql.ArgumentConfig{
+						Type: graphql.Int,
+					},
+					"definition": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.String),
+					},
+					"domain": &graphql.ArgumentConfig{
+						Type: graphql.String,
+					},
+					"ordinal": &graphql.ArgumentConfig{
+						Type: graphql.Int,
+					},
+				}),
+				Resolve: b.h.AddSense,
+			},
+
+			// Update an existing sense
+			"updateSense": &graphql.Field{
+				Type: b.senseType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.Int),
+					},
+					"definition": &graphql.ArgumentConfig{
+						Type: graphql.String,
+					},
+					"domain": &graphql.ArgumentConfig{
+						Type: graphql.String,
+					},
+					"ordinal": &graphql.ArgumentConfig{
+						Type: graphql.Int,
+					},
+					"expectedVersion": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.Int),
+					},
+				},
+				Resolve: b.h.UpdateSense,
+			},
+
+			// Delete a sense
+			"deleteSense": &graphql.Field{
+				Type: graphql.Boolean,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.Int),
+					},
 				},
-				Resolve: handlers.DeleteWord,
+				Resolve: b.h.DeleteSense,
 			},
 
 			// Add a new translation
 			"addTranslation": &graphql.Field{
-				Type: translationType,
-				Args: graphql.FieldConfigArgument{
-					"wordPl": &graphql.ArgumentConfig{
+				Type: b.translationType,
+				Args: withDictionaryArg(graphql.FieldConfigArgument{
+					"sourceWord": &graphql.ArgumentConfig{
 						Type: graphql.NewNonNull(graphql.String),
 					},
-					"wordEn": &graphql.ArgumentConfig{
+					"sourceLanguage": &graphql.ArgumentConfig{
 						Type: graphql.NewNonNull(graphql.String),
 					},
-				},
-				Resolve: handlers.AddTranslation,
+					"targetWord": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.String),
+					},
+					"targetLanguage": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.String),
+					},
+					"sourceHomograph": &graphql.ArgumentConfig{
+						Type: graphql.Int,
+					},
+					"targetHomograph": &graphql.ArgumentConfig{
+						Type: graphql.Int,
+					},
+					"sourceSense": &graphql.ArgumentConfig{
+						Type: graphql.Int,
+					},
+					"targetSense": &graphql.ArgumentConfig{
+						Type: graphql.Int,
+					},
+				}),
+				Resolve: b.h.AddTranslation,
 			},
 
 			// Update an existing translation
 			"updateTranslation": &graphql.Field{
-				Type: translationType,
-				Args: graphql.FieldConfigArgument{
-					"oldWordPl": &graphql.ArgumentConfig{
+				Type: b.translationType,
+				Args: withDictionaryArg(graphql.FieldConfigArgument{
+					"sourceLanguage": &graphql.ArgumentConfig{
 						Type: graphql.NewNonNull(graphql.String),
 					},
-					"oldWordEn": &graphql.ArgumentConfig{
+					"targetLanguage": &graphql.ArgumentConfig{
 						Type: graphql.NewNonNull(graphql.String),
 					},
-					"newWordPl": &graphql.ArgumentConfig{
+					"oldSourceWord": &graphql.ArgumentConfig{
 						Type: graphql.NewNonNull(graphql.String),
 					},
-					"newWordEn": &graphql.ArgumentConfig{
+					"oldTargetWord": &graphql.ArgumentConfig{
 						Type: graphql.NewNonNull(graphql.String),
 					},
-				},
-				Resolve: handlers.UpdateTranslation,
+					"newSourceWord": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.String),
+					},
+					"newTargetWord": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.String),
+					},
+					"oldSourceHomograph": &graphql.ArgumentConfig{
+						Type: graphql.Int,
+					},
+					"oldTargetHomograph": &graphql.ArgumentConfig{
+						Type: graphql.Int,
+					},
+					"newSourceHomograph": &graphql.ArgumentConfig{
+						Type: graphql.Int,
+					},
+					"newTargetHomograph": &graphql.ArgumentConfig{
+						Type: graphql.Int,
+					},
+					"sourceSense": &graphql.ArgumentConfig{
+						Type: graphql.Int,
+					},
+					"targetSense": &graphql.ArgumentConfig{
+						Type: graphql.Int,
+					},
+					"expectedVersion": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.Int),
+					},
+				}),
+				Resolve: b.h.UpdateTranslation,
 			},
 
 			// Delete a translation
 			"deleteTranslation": &graphql.Field{
 				Type: graphql.Boolean,
-				Args: graphql.FieldConfigArgument{
-					"wordPl": &graphql.ArgumentConfig{
+				Args: withDictionaryArg(graphql.FieldConfigArgument{
+					"sourceWord": &graphql.ArgumentConfig{
 						Type: graphql.NewNonNull(graphql.String),
 					},
-					"wordEn": &graphql.ArgumentConfig{
+					"sourceLanguage": &graphql.ArgumentConfig{
 						Type: graphql.NewNonNull(graphql.String),
 					},
-				},
-				Resolve: handlers.DeleteTranslation,
+					"targetWord": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.String),
+					},
+					"targetLanguage": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.String),
+					},
+					"sourceHomograph": &graphql.ArgumentConfig{
+						Type: graphql.Int,
+					},
+					"targetHomograph": &graphql.ArgumentConfig{
+						Type: graphql.Int,
+					},
+				}),
+				Resolve: b.h.DeleteTranslation,
 			},
 
 			// Add a new example
 			"addExample": &graphql.Field{
-				Type: exampleType,
-				Args: graphql.FieldConfigArgument{
+				Type: b.exampleType,
+				Args: withDictionaryArg(graphql.FieldConfigArgument{
 					"word": &graphql.ArgumentConfig{
 						Type: graphql.NewNonNull(graphql.String),
 					},
 					"language": &graphql.ArgumentConfig{
 						Type: graphql.NewNonNull(graphql.String),
 					},
+					"homograph": &graphql.ArgumentConfig{
+						Type: graphql.Int,
+					},
 					"example": &graphql.ArgumentConfig{
 						Type: graphql.NewNonNull(graphql.String),
 					},
-				},
-				Resolve: handlers.AddExample,
+					"sense": &graphql.ArgumentConfig{
+						Type: graphql.Int,
+					},
+				}),
+				Resolve: b.h.AddExample,
 			},
 
 			// Update an existing example
 			"updateExample": &graphql.Field{
-				Type: exampleType,
+				Type: b.exampleType,
 				Args: graphql.FieldConfigArgument{
 					"id": &graphql.ArgumentConfig{
 						Type: graphql.NewNonNull(graphql.Int),
@@ -214,8 +817,14 @@ func buildRootMutation() *graphql.Object {
 					"example": &graphql.ArgumentConfig{
 						Type: graphql.String,
 					},
+					"sense": &graphql.ArgumentConfig{
+						Type: graphql.Int,
+					},
+					"expectedVersion": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.Int),
+					},
 				},
-				Resolve: handlers.UpdateExample,
+				Resolve: b.h.UpdateExample,
 			},
 
 			// Delete an example
@@ -226,8 +835,114 @@ func buildRootMutation() *graphql.Object {
 						Type: graphql.NewNonNull(graphql.Int),
 					},
 				},
-				Resolve: handlers.DeleteExample,
+				Resolve: b.h.DeleteExample,
 			},
-		},
+			// Restore a deleted example
+			"restoreExample": &graphql.Field{
+				Type: b.exampleType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.Int),
+					},
+				},
+				Resolve: b.h.RestoreExample,
+			},
+
+			// Undo a change recorded in audit log
+			"revertChange": &graphql.Field{
+				Type: b.auditEntryType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.Int),
+					},
+				},
+				Resolve: b.h.RevertChange,
+			},
+			// Permanently delete entries moved to trash before given time (all by default)
+			"purgeTrash": &graphql.Field{
+				Type: b.purgeResultType,
+				Args: withDictionaryArg(graphql.FieldConfigArgument{
+					"before": &graphql.ArgumentConfig{
+						Type: graphql.DateTime,
+					},
+				}),
+				Resolve: b.h.PurgeTrash,
+			},
+
+			// Give role to principal (ID: "apikey:<name>" or "jwt:<issuer>/<subject>")
+			"setUserRole": &graphql.Field{
+				Type: b.userType,
+				Args: graphql.FieldConfigArgument{
+					"name": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.String),
+					},
+					"role": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(roleEnum),
+					},
+				},
+				Resolve: b.h.SetUserRole,
+			},
+			// Remove user - principal becomes viewer
+			"deleteUser": &graphql.Field{
+				Type: graphql.Boolean,
+				Args: graphql.FieldConfigArgument{
+					"name": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.String),
+					},
+				},
+				Resolve: b.h.DeleteUser,
+			},
+		}, mutationRoles, models.RoleAdmin),
 	})
 }
+
+// newAttributeEnum builds enum of lower case values (grammatical attributes, sort orders), e.g. "plurale_tantum" is exposed as PLURALE_TANTUM
+func newAttributeEnum(name string, values []string) *graphql.Enum {
+	enumValues := graphql.EnumValueConfigMap{}
+	for _, value := range values {
+		enumValues[strings.ToUpper(value)] = &graphql.EnumValueConfig{Value: value}
+	}
+	return graphql.NewEnum(graphql.EnumConfig{
+		Name:   name,
+		Values: enumValues,
+	})
+}
+
+// newWordAttributeEnum builds enum of grammatical attribute of word with NONE value, which clears the attribute.
+// Attributes not specified are still returned as null.
+func newWordAttributeEnum(name string, values []string) *graphql.Enum {
+	return newAttributeEnum(name, append([]string{models.AttributeNone}, values...))
+}
+
+// withWordAttributeArgs adds optional grammatical attribute arguments to given arguments
+func withWordAttributeArgs(args graphql.FieldConfigArgument) graphql.FieldConfigArgument {
+	args["partOfSpeech"] = &graphql.ArgumentConfig{Type: partOfSpeechEnum}
+	args["gender"] = &graphql.ArgumentConfig{Type: genderEnum}
+	args["aspect"] = &graphql.ArgumentConfig{Type: aspectEnum}
+	args["countability"] = &graphql.ArgumentConfig{Type: countabilityEnum}
+	args["transitivity"] = &graphql.ArgumentConfig{Type: transitivityEnum}
+	return args
+}
+
+// withDictionaryArg adds optional argument naming dictionary changed by mutation (the default one if not given)
+func withDictionaryArg(args graphql.FieldConfigArgument) graphql.FieldConfigArgument {
+	args["dictionary"] = &graphql.ArgumentConfig{Type: graphql.String}
+	return args
+}
+
+// withDictionariesArg adds optional argument naming dictionaries searched by query (the default one if not given)
+func withDictionariesArg(args graphql.FieldConfigArgument) graphql.FieldConfigArgument {
+	args["dictionaries"] = &graphql.ArgumentConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))}
+	return args
+}
+
+// auditValues resolves recorded values of entity - empty values (entity does not exist) are null
+func auditValues(values func(models.AuditEntry) string) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		entry, ok := p.Source.(models.AuditEntry)
+		if !ok || values(entry) == "" {
+			return nil, nil
+		}
+		return values(entry), nil
+	}
+}
@@ -0,0 +1,204 @@
+// Package server exposes GraphQL schema over HTTP (https://graphql.github.io/graphql-over-http/)
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/tdawidzi/dictionary_app/apperrors"
+	"github.com/tdawidzi/dictionary_app/auth"
+	"github.com/tdawidzi/dictionary_app/handlers"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+// Request is GraphQL request - sent as JSON body of POST request or as query string parameters of GET request
+type Request struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+	Extensions    map[string]interface{} `json:"extensions"`
+}
+
+var errMutationOverGet = errors.New("mutations are not allowed in GET requests")
+
+// Handler executes GraphQL requests against given schema. Requests without credentials can only read,
+// requests with invalid credentials are rejected.
+func Handler(schema graphql.Schema, authenticator *auth.Authenticator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request, err := parseRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if request == nil {
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		// GET requests must be safe - they can be cached or prefetched
+		mutation := isMutation(request)
+		if r.Method == http.MethodGet && mutation {
+			w.Header().Set("Allow", "POST")
+			http.Error(w, errMutationOverGet.Error(), http.StatusMethodNotAllowed)
+			return
+		}
+
+		principal, authenticated, err := authenticator.Authenticate(r)
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			unauthorized(w, err.Error())
+			return
+		}
+		if err != nil {
+			http.Error(w, "Authentication failed", http.StatusInternalServerError)
+			return
+		}
+		if mutation && !authenticated {
+			unauthorized(w, "authentication required")
+			return
+		}
+
+		// GraphQL query execution (loaders batch queries of nested fields).
+		// Changes are recorded in audit log with name of the principal.
+		ctx := handlers.WithLoaders(r.Context())
+		if authenticated {
+			ctx = auth.WithPrincipal(ctx, principal)
+		}
+		result := graphql.Do(graphql.Params{
+			Schema:         schema,
+			Context:        ctx,
+			RequestString:  request.Query,
+			VariableValues: request.Variables,
+			OperationName:  request.OperationName,
+		})
+
+		// Errors are returned together with (partial) data, every error has code in extensions
+		for i := range result.Errors {
+			result.Errors[i] = withErrorCode(result.Errors[i])
+		}
+
+		// Convert to JSON and return
+		response, err := json.Marshal(result)
+		if err != nil {
+			http.Error(w, "Output serialization Error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(response)
+	})
+}
+
+// unauthorized rejects request without valid credentials
+func unauthorized(w http.ResponseWriter, message string) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	http.Error(w, message, http.StatusUnauthorized)
+}
+
+// parseRequest reads GraphQL request from HTTP request. Returns nil request for unsupported HTTP method.
+func parseRequest(r *http.Request) (*Request, error) {
+	var request Request
+
+	switch r.Method {
+	case http.MethodGet:
+		values := r.URL.Query()
+		request.Query = values.Get("query")
+		request.OperationName = values.Get("operationName")
+		if variables := values.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &request.Variables); err != nil {
+				return nil, fmt.Errorf("invalid variables: %w", err)
+			}
+		}
+		if extensions := values.Get("extensions"); extensions != "" {
+			if err := json.Unmarshal([]byte(extensions), &request.Extensions); err != nil {
+				return nil, fmt.Errorf("invalid extensions: %w", err)
+			}
+		}
+
+	case http.MethodPost:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, errors.New("error reading query")
+		}
+
+		// Plain query text is accepted too
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if mediaType == "application/graphql" {
+			request.Query = string(body)
+		} else if err := json.Unmarshal(body, &request); err != nil {
+			return nil, errors.New("error JSON parsing")
+		}
+
+	default:
+		return nil, nil
+	}
+
+	if request.Query == "" {
+		return nil, errors.New("missing query")
+	}
+	return &request, nil
+}
+
+// withErrorCode adds extensions of the original error returned by resolver (see apperrors).
+// Errors of GraphQL document itself (syntax, validation) have no original error and get BAD_REQUEST code.
+func withErrorCode(formatted gqlerrors.FormattedError) gqlerrors.FormattedError {
+	original := originalError(formatted)
+	if original == nil {
+		formatted.Extensions = map[string]interface{}{"code": string(apperrors.CodeBadRequest)}
+		return formatted
+	}
+
+	var extended gqlerrors.ExtendedError
+	if errors.As(original, &extended) {
+		formatted.Extensions = extended.Extensions()
+	} else {
+		formatted.Extensions = map[string]interface{}{"code": string(apperrors.CodeInternal)}
+	}
+	return formatted
+}
+
+// originalError unwraps error returned by resolver from GraphQL execution errors
+func originalError(err error) error {
+	for {
+		switch e := err.(type) {
+		case gqlerrors.FormattedError:
+			err = e.OriginalError()
+		case *gqlerrors.Error:
+			err = e.OriginalError
+		default:
+			return err
+		}
+	}
+}
+
+// isMutation checks if operation selected by request is a mutation.
+// Invalid queries are not mutations - they are rejected by GraphQL execution.
+func isMutation(request *Request) bool {
+	document, err := parser.Parse(parser.ParseParams{Source: request.Query})
+	if err != nil {
+		return false
+	}
+
+	for _, definition := range document.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if request.OperationName != "" && (operation.Name == nil || operation.Name.Value != request.OperationName) {
+			continue
+		}
+		if operation.Operation == ast.OperationTypeMutation {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,275 @@
+package server_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/stretchr/testify/assert"
+	"github.com/tdawidzi/dictionary_app/apperrors"
+	"github.com/tdawidzi/dictionary_app/auth"
+	"github.com/tdawidzi/dictionary_app/handlers"
+	"github.com/tdawidzi/dictionary_app/models"
+	"github.com/tdawidzi/dictionary_app/repository"
+	"github.com/tdawidzi/dictionary_app/schema"
+	"github.com/tdawidzi/dictionary_app/server"
+)
+
+// testSchema echoes arguments and fails on demand, so requests can be tested without database
+func testSchema(t *testing.T) graphql.Schema {
+	t.Helper()
+
+	echo := &graphql.Field{
+		Type: graphql.String,
+		Args: graphql.FieldConfigArgument{
+			"text": &graphql.ArgumentConfig{Type: graphql.String},
+		},
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Args["text"], nil
+		},
+	}
+	missing := &graphql.Field{
+		Type: graphql.String,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return nil, apperrors.NotFound("word not found")
+		},
+	}
+	broken := &graphql.Field{
+		Type: graphql.String,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return nil, errors.New("connection refused")
+		},
+	}
+	whoami := &graphql.Field{
+		Type: graphql.String,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			principal, _ := auth.PrincipalFrom(p.Context)
+			return principal.Name, nil
+		},
+	}
+	queries := graphql.Fields{"echo": echo, "missing": missing, "broken": broken}
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query:    graphql.NewObject(graphql.ObjectConfig{Name: "Query", Fields: queries}),
+		Mutation: graphql.NewObject(graphql.ObjectConfig{Name: "Mutation", Fields: graphql.Fields{"echo": echo, "whoami": whoami}}),
+	})
+	if err != nil {
+		t.Fatalf("failed to build schema: %v", err)
+	}
+	return schema
+}
+
+// testAPIKey is key of "ala"
+const testAPIKey = "dk_test"
+
+// testJWTSecret signs tokens accepted by test server
+var testJWTSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func serve(t *testing.T, r *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+
+	store := repository.NewMemoryStore()
+	assert.NoError(t, store.APIKeys().Create(&models.APIKey{Name: "ala", Hash: auth.HashAPIKey(testAPIKey)}))
+	verifier, err := auth.NewJWTVerifier(auth.JWTKeys{HS256Secret: testJWTSecret})
+	assert.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	server.Handler(testSchema(t), auth.New(store, verifier)).ServeHTTP(recorder, r)
+	return recorder
+}
+
+func echoed(t *testing.T, recorder *httptest.ResponseRecorder) interface{} {
+	t.Helper()
+
+	var response struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	err := json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	return response.Data["echo"]
+}
+
+func TestPostWithVariables(t *testing.T) {
+	body := `{
+		"query": "query A { echo(text: \"a\") } query B($text: String) { echo(text: $text) }",
+		"operationName": "B",
+		"variables": {"text": "żółw"},
+		"extensions": {}
+	}`
+	r := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+
+	recorder := serve(t, r)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "żółw", echoed(t, recorder))
+}
+
+func TestPostPlainQuery(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{ echo(text: "kot") }`))
+	r.Header.Set("Content-Type", "application/graphql; charset=utf-8")
+
+	recorder := serve(t, r)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "kot", echoed(t, recorder))
+}
+
+func TestGetWithVariables(t *testing.T) {
+	values := url.Values{
+		"query":     {"query Echo($text: String) { echo(text: $text) }"},
+		"variables": {`{"text": "pies"}`},
+	}
+	r := httptest.NewRequest(http.MethodGet, "/graphql?"+values.Encode(), nil)
+
+	recorder := serve(t, r)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "pies", echoed(t, recorder))
+}
+
+func TestGetRejectsMutation(t *testing.T) {
+	values := url.Values{"query": {`mutation { echo(text: "kot") }`}}
+	r := httptest.NewRequest(http.MethodGet, "/graphql?"+values.Encode(), nil)
+
+	recorder := serve(t, r)
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+	assert.Equal(t, "POST", recorder.Header().Get("Allow"))
+}
+
+func TestInvalidRequests(t *testing.T) {
+	// Invalid variables
+	values := url.Values{"query": {"{ echo }"}, "variables": {"{"}}
+	recorder := serve(t, httptest.NewRequest(http.MethodGet, "/graphql?"+values.Encode(), nil))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	// Missing query
+	recorder = serve(t, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"variables": {}}`)))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	// Unsupported method
+	recorder = serve(t, httptest.NewRequest(http.MethodPut, "/graphql", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+}
+
+type errorResponse struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Path       []interface{}          `json:"path"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+func TestErrorsHaveCodes(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query": "{ echo(text: \"kot\") missing broken }"}`))
+	recorder := serve(t, r)
+
+	// Partial data is returned with errors
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	var response errorResponse
+	err := json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "kot", response.Data["echo"])
+
+	codes := map[string]interface{}{}
+	for _, e := range response.Errors {
+		codes[e.Path[0].(string)] = e.Extensions["code"]
+	}
+	assert.Equal(t, map[string]interface{}{"missing": "NOT_FOUND", "broken": "INTERNAL"}, codes)
+}
+
+func TestInvalidDocumentError(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query": "{ unknown }"}`))
+	recorder := serve(t, r)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var response errorResponse
+	err := json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	if assert.Len(t, response.Errors, 1) {
+		assert.Equal(t, "BAD_REQUEST", response.Errors[0].Extensions["code"])
+	}
+}
+
+func TestMutationsNeedAuthentication(t *testing.T) {
+	mutation := func() *http.Request {
+		return httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query": "mutation { whoami }"}`))
+	}
+
+	// Anonymous requests can only read
+	recorder := serve(t, mutation())
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Equal(t, "Bearer", recorder.Header().Get("WWW-Authenticate"))
+	recorder = serve(t, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query": "{ echo(text: \"kot\") }"}`)))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	// Principal is passed to resolvers
+	r := mutation()
+	r.Header.Set(auth.APIKeyHeader, testAPIKey)
+	recorder = serve(t, r)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"data": {"whoami": "ala"}}`, recorder.Body.String())
+
+	r = mutation()
+	r.Header.Set(auth.AuthorizationHeader, "Bearer "+signHS256(t, `{"sub": "ola", "exp": 4102444800}`))
+	recorder = serve(t, r)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"data": {"whoami": "ola"}}`, recorder.Body.String())
+}
+
+func TestInvalidCredentials(t *testing.T) {
+	// Invalid credentials are rejected even for queries
+	for header, value := range map[string]string{
+		auth.APIKeyHeader:        "dk_unknown",
+		auth.AuthorizationHeader: "Bearer " + signHS256(t, `{"sub": "ola", "exp": 946684800}`), // expired
+	} {
+		r := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query": "{ echo(text: \"kot\") }"}`))
+		r.Header.Set(header, value)
+		recorder := serve(t, r)
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code, header)
+	}
+}
+
+func TestRolesOfOtherCredentialsAreNotGiven(t *testing.T) {
+	store := repository.NewMemoryStore()
+	assert.NoError(t, store.APIKeys().Create(&models.APIKey{Name: "ala", Hash: auth.HashAPIKey(testAPIKey)}))
+	assert.NoError(t, store.Users().Create(&models.User{Name: "apikey:ala", Role: models.RoleAdmin}))
+	verifier, err := auth.NewJWTVerifier(auth.JWTKeys{HS256Secret: testJWTSecret})
+	assert.NoError(t, err)
+	s, err := schema.New(handlers.New(store))
+	assert.NoError(t, err)
+	handler := server.Handler(s, auth.New(store, verifier, "apikey:ala"))
+
+	addLanguage := func(header, value string) string {
+		r := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query": "mutation { addLanguage(code: \"de\", name: \"German\") { code } }"}`))
+		r.Header.Set(header, value)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, r)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		return recorder.Body.String()
+	}
+
+	// JWT with subject named like admin API key is not admin
+	body := addLanguage(auth.AuthorizationHeader, "Bearer "+signHS256(t, `{"sub": "ala", "exp": 4102444800}`))
+	assert.Contains(t, body, `"code":"FORBIDDEN"`)
+	assert.Contains(t, body, `"addLanguage":null`)
+	body = addLanguage(auth.APIKeyHeader, testAPIKey)
+	assert.JSONEq(t, `{"data": {"addLanguage": {"code": "de"}}}`, body)
+}
+
+// signHS256 creates token with given claims signed with test secret
+func signHS256(t *testing.T, claims string) string {
+	t.Helper()
+
+	encode := base64.RawURLEncoding.EncodeToString
+	signed := encode([]byte(`{"alg": "HS256", "typ": "JWT"}`)) + "." + encode([]byte(claims))
+	mac := hmac.New(sha256.New, testJWTSecret)
+	mac.Write([]byte(signed))
+	return signed + "." + encode(mac.Sum(nil))
+}
@@ -1,14 +1,18 @@
+//go:build postgres
+
 package testresources
 
 import (
-	"fmt"
+	"errors"
+	"io/fs"
+	"path/filepath"
 	"strconv"
 	"testing"
 	"time"
 
 	embeddedpostgres "github.com/fergusstrange/embedded-postgres"
 	"github.com/tdawidzi/dictionary_app/config"
-	"gorm.io/driver/postgres"
+	"github.com/tdawidzi/dictionary_app/utils"
 	"gorm.io/gorm"
 )
 
@@ -27,13 +31,11 @@ func NewSingleTestConnection(t *testing.T) *gorm.DB {
 		db.Stop()
 	})
 
-	// Load config
-	cfg, err := config.Load("..\\testresources\\")
-	if err != nil {
-		t.Fatalf("Error while loading configuration: %v", err)
-	}
+	cfg := testConfig(t)
+	cfg.DB_Driver = config.DriverPostgres
 
-	postgresDB, err := ConnectDB(cfg)
+	// Migrations create schema with full-text and trigram search, and register default languages and dictionary
+	postgresDB, err := utils.ConnectDB(cfg)
 	if err != nil {
 		t.Fatalf("failed to connect to embedded_postgres database: %v", err)
 	}
@@ -42,11 +44,7 @@ func NewSingleTestConnection(t *testing.T) *gorm.DB {
 }
 
 func NewInMemTestDB(t *testing.T) (*embeddedpostgres.EmbeddedPostgres, error) {
-	// Load config
-	cfg, err := config.Load("..\\testresources\\")
-	if err != nil {
-		t.Fatalf("Error while loading configuration: %v,", err)
-	}
+	cfg := testConfig(t)
 	port, _ := strconv.ParseUint(cfg.DB_Port, 0, 32)
 
 	embedCfg := embeddedpostgres.DefaultConfig().
@@ -61,20 +59,27 @@ func NewInMemTestDB(t *testing.T) (*embeddedpostgres.EmbeddedPostgres, error) {
 	return postgres, nil
 }
 
-func ConnectDB(config *config.Config) (*gorm.DB, error) {
-
-	var err error
-	var Test_DB *gorm.DB
-
-	// Get connection details from config
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		config.DB_Host, config.DB_Port, config.DB_User, config.DB_Password, config.DB_Name)
+// testConfig loads test database configuration, with defaults of .env.example when there is no .env
+func testConfig(t *testing.T) *config.Config {
+	t.Helper()
 
-	Test_DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	cfg, err := config.Load(envPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return &config.Config{
+			DB_Host:     "localhost",
+			DB_Port:     "5433",
+			DB_User:     "postgres",
+			DB_Password: "password",
+			DB_Name:     "dictionary",
+		}
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
+		t.Fatalf("Error while loading configuration: %v", err)
 	}
+	return cfg
+}
 
-	return Test_DB, nil
-
+// envPath - directory of .env with test database configuration, relative to directory of tested package
+func envPath() string {
+	return filepath.Join("..", "testresources") + string(filepath.Separator)
 }
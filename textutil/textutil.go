@@ -0,0 +1,83 @@
+package textutil
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Letters which are not decomposed by unicode normalization, but should be folded anyway
+var foldedLetters = map[rune]rune{
+	'ł': 'l',
+	'đ': 'd',
+	'ø': 'o',
+	'ı': 'i',
+}
+
+// SearchKey normalizes text for lookups - lower case, without diacritics ("Żółw" -> "zolw")
+func SearchKey(text string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(strings.ToLower(strings.TrimSpace(text))) {
+		// Skip combining marks left after decomposition (accents, ogonek, dot above...)
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		if folded, ok := foldedLetters[r]; ok {
+			r = folded
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// EditDistance counts single letter insertions, deletions, substitutions and transpositions
+// of adjacent letters needed to turn one text into another (optimal string alignment distance).
+func EditDistance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+
+	// Three last rows of distance matrix are enough
+	prev2 := make([]int, len(t)+1)
+	prev := make([]int, len(t)+1)
+	curr := make([]int, len(t)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(s); i++ {
+		curr[0] = i
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+	return prev[len(t)]
+}
+
+// Collator is not safe for concurrent use
+var (
+	polishCollator   = collate.New(language.Polish)
+	polishCollatorMu sync.Mutex
+)
+
+// SortKey returns binary key which orders texts alphabetically with polish collation
+// ("a" < "ą" < "b" ... "z" < "ź" < "ż") when compared byte by byte.
+func SortKey(text string) []byte {
+	polishCollatorMu.Lock()
+	defer polishCollatorMu.Unlock()
+
+	var buf collate.Buffer
+	key := polishCollator.KeyFromString(&buf, text)
+	// Key points to buffer memory, copy it
+	return append([]byte(nil), key...)
+}
@@ -0,0 +1,55 @@
+package textutil_test
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tdawidzi/dictionary_app/textutil"
+)
+
+func TestSearchKey(t *testing.T) {
+	cases := map[string]string{
+		"kot":         "kot",
+		"Kot":         "kot",
+		"żółw":        "zolw",
+		"ŻÓŁW":        "zolw",
+		"źdźbło":      "zdzblo",
+		"Gęś":         "ges",
+		"zażółć jaźń": "zazolc jazn",
+		"  ćma ":      "cma",
+		"Straße":      "straße",
+	}
+	for text, expected := range cases {
+		assert.Equal(t, expected, textutil.SearchKey(text), text)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected int
+	}{
+		{"kot", "kot", 0},
+		{"kot", "kit", 1},
+		{"kot", "kto", 1},
+		{"kot", "kotek", 2},
+		{"", "pies", 4},
+		{"zolw", "żółw", 3},
+		{"zamek", "zamki", 2},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.expected, textutil.EditDistance(c.a, c.b), c.a+" -> "+c.b)
+	}
+}
+
+func TestSortKey(t *testing.T) {
+	// Alphabetical order with polish collation
+	words := []string{"a", "ą", "b", "cześć", "ćma", "kot", "Kot", "łąka", "lama", "zebra", "źdźbło", "żaba"}
+	sorted := append([]string{}, words...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(textutil.SortKey(sorted[i]), textutil.SortKey(sorted[j])) < 0
+	})
+	assert.Equal(t, []string{"a", "ą", "b", "cześć", "ćma", "kot", "Kot", "lama", "łąka", "zebra", "źdźbło", "żaba"}, sorted)
+}
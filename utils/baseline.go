@@ -0,0 +1,229 @@
+package utils
+
+import (
+	"fmt"
+
+	"github.com/tdawidzi/dictionary_app/textutil"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Databases created before versioned migrations were migrated by AutoMigrate of models on every start.
+// They are brought once to schema of first migration, by AutoMigrate of models as they were then (below).
+// These copies must not change with models - changes of schema go to migrations.
+
+type baselineLanguage struct {
+	Code         string `gorm:"primaryKey;size:3"`
+	Name         string `gorm:"not null"`
+	Script       string `gorm:"not null;default:'Latn'"`
+	Direction    string `gorm:"not null;default:'ltr';check:direction IN ('ltr', 'rtl')"`
+	SearchConfig string `gorm:"not null;default:'simple'"`
+}
+
+func (baselineLanguage) TableName() string { return "languages" }
+
+type baselineWord struct {
+	ID           uint   `gorm:"primaryKey;index:idx_words_sort_key_id,priority:2"`
+	Word         string `gorm:"not null;uniqueIndex:word_language_homograph"`
+	Language     string `gorm:"not null;index;uniqueIndex:word_language_homograph"`
+	Homograph    int    `gorm:"not null;default:1;check:homograph > 0;uniqueIndex:word_language_homograph"`
+	SearchKey    string `gorm:"not null;default:'';index"`
+	SortKey      []byte `gorm:"index:idx_words_sort_key_id,priority:1"`
+	PartOfSpeech string `gorm:"not null;default:'';index;check:part_of_speech IN ('', 'noun', 'verb', 'adjective', 'adverb', 'pronoun', 'numeral', 'preposition', 'conjunction', 'particle', 'interjection', 'determiner')"`
+	Gender       string `gorm:"not null;default:'';check:gender IN ('', 'masculine', 'masculine_personal', 'masculine_animate', 'masculine_inanimate', 'feminine', 'neuter')"`
+	Aspect       string `gorm:"not null;default:'';check:aspect IN ('', 'perfective', 'imperfective', 'biaspectual')"`
+	Countability string `gorm:"not null;default:'';check:countability IN ('', 'countable', 'uncountable', 'plurale_tantum')"`
+	Transitivity string `gorm:"not null;default:'';check:transitivity IN ('', 'transitive', 'intransitive', 'ambitransitive')"`
+
+	Lang baselineLanguage `gorm:"foreignKey:Language;references:Code;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+}
+
+func (baselineWord) TableName() string { return "words" }
+
+type baselineSense struct {
+	ID         uint         `gorm:"primaryKey"`
+	WordID     uint         `gorm:"not null; uniqueIndex:wordid_ordinal"`
+	Ordinal    int          `gorm:"not null; check:ordinal > 0; uniqueIndex:wordid_ordinal"`
+	Definition string       `gorm:"not null"`
+	Domain     string       `gorm:"not null; default:''"`
+	Word       baselineWord `gorm:"foreignKey:WordID;references:ID;constraint:OnDelete:CASCADE"`
+}
+
+func (baselineSense) TableName() string { return "senses" }
+
+type baselineWordForm struct {
+	ID        uint         `gorm:"primaryKey"`
+	WordID    uint         `gorm:"not null; uniqueIndex:word_form_attributes"`
+	Form      string       `gorm:"not null; index; uniqueIndex:word_form_attributes"`
+	SearchKey string       `gorm:"not null; default:''; index"`
+	Case      string       `gorm:"column:grammatical_case; not null; default:''; uniqueIndex:word_form_attributes; check:grammatical_case IN ('', 'nominative', 'genitive', 'dative', 'accusative', 'instrumental', 'locative', 'vocative')"`
+	Number    string       `gorm:"not null; default:''; uniqueIndex:word_form_attributes; check:number IN ('', 'singular', 'plural')"`
+	Person    string       `gorm:"not null; default:''; uniqueIndex:word_form_attributes; check:person IN ('', 'first', 'second', 'third')"`
+	Tense     string       `gorm:"not null; default:''; uniqueIndex:word_form_attributes; check:tense IN ('', 'present', 'past', 'future')"`
+	Mood      string       `gorm:"not null; default:''; uniqueIndex:word_form_attributes; check:mood IN ('', 'indicative', 'imperative', 'conditional')"`
+	Aspect    string       `gorm:"not null; default:''; uniqueIndex:word_form_attributes; check:aspect IN ('', 'perfective', 'imperfective', 'biaspectual')"`
+	Gender    string       `gorm:"not null; default:''; uniqueIndex:word_form_attributes; check:gender IN ('', 'masculine', 'masculine_personal', 'masculine_animate', 'masculine_inanimate', 'feminine', 'neuter', 'non_masculine_personal')"`
+	Degree    string       `gorm:"not null; default:''; uniqueIndex:word_form_attributes; check:degree IN ('', 'positive', 'comparative', 'superlative')"`
+	Word      baselineWord `gorm:"foreignKey:WordID;references:ID;constraint:OnDelete:CASCADE"`
+}
+
+func (baselineWordForm) TableName() string { return "word_forms" }
+
+type baselineTranslation struct {
+	ID            uint           `gorm:"primaryKey"`
+	SourceWordID  uint           `gorm:"not null; index; uniqueIndex:translation_pair"`
+	TargetWordID  uint           `gorm:"not null; index; uniqueIndex:translation_pair"`
+	SourceSenseID *uint          `gorm:"index"`
+	TargetSenseID *uint          `gorm:"index"`
+	SourceWord    baselineWord   `gorm:"foreignKey:SourceWordID;references:ID;constraint:OnDelete:CASCADE"`
+	TargetWord    baselineWord   `gorm:"foreignKey:TargetWordID;references:ID;constraint:OnDelete:CASCADE"`
+	SourceSense   *baselineSense `gorm:"foreignKey:SourceSenseID;references:ID;constraint:OnDelete:SET NULL"`
+	TargetSense   *baselineSense `gorm:"foreignKey:TargetSenseID;references:ID;constraint:OnDelete:SET NULL"`
+}
+
+func (baselineTranslation) TableName() string { return "translations" }
+
+type baselineExample struct {
+	ID      uint           `gorm:"primaryKey"`
+	WordID  uint           `gorm:"not null; uniqueIndex:wordid_example"`
+	Example string         `gorm:"unique;not null; uniqueIndex:wordid_example"`
+	SenseID *uint          `gorm:"index"`
+	Word    baselineWord   `gorm:"foreignKey:WordID;references:ID;constraint:OnDelete:CASCADE"`
+	Sense   *baselineSense `gorm:"foreignKey:SenseID;references:ID;constraint:OnDelete:SET NULL"`
+}
+
+func (baselineExample) TableName() string { return "examples" }
+
+// migrateToBaseline brings database created before versioned migrations to schema of first migration
+func migrateToBaseline(db *gorm.DB) (err error) {
+	// SQLite databases were always created with schema of first migration
+	if !isPostgres(db) {
+		return nil
+	}
+
+	// db.AutoMigrate panics on some errors
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic during tables migration: %v", r)
+		}
+	}()
+
+	// Language registry has to exist before words can reference it
+	hadSearchConfig := db.Migrator().HasColumn(&baselineLanguage{}, "search_config")
+	if err := db.AutoMigrate(&baselineLanguage{}); err != nil {
+		return fmt.Errorf("failed to create tables: %w", err)
+	}
+	languages := []baselineLanguage{
+		{Code: "pl", Name: "Polish", Script: "Latn", Direction: "ltr", SearchConfig: "simple"},
+		{Code: "en", Name: "English", Script: "Latn", Direction: "ltr", SearchConfig: "english"},
+	}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&languages).Error; err != nil {
+		return fmt.Errorf("failed to register default languages: %w", err)
+	}
+	// Default languages registered before search configuration was introduced
+	if !hadSearchConfig {
+		for _, language := range languages {
+			if err := db.Model(&language).UpdateColumn("search_config", language.SearchConfig).Error; err != nil {
+				return fmt.Errorf("failed to set search configuration: %w", err)
+			}
+		}
+	}
+
+	if err := migrateLegacySchema(db); err != nil {
+		return fmt.Errorf("failed to migrate legacy schema: %w", err)
+	}
+
+	err = db.AutoMigrate(&baselineWord{}, &baselineWordForm{}, &baselineSense{}, &baselineTranslation{}, &baselineExample{})
+	if err != nil {
+		return fmt.Errorf("failed to create tables: %w", err)
+	}
+
+	if err := backfillSearchKeys(db); err != nil {
+		return fmt.Errorf("failed to fill search keys: %w", err)
+	}
+
+	err = db.Exec("CREATE INDEX IF NOT EXISTS idx_words_search_key_prefix ON words (search_key text_pattern_ops)").Error
+	if err != nil {
+		return fmt.Errorf("failed to create prefix index: %w", err)
+	}
+	return nil
+}
+
+// Databases created before language registry was introduced have pl/en specific schema:
+// language check constraint and globally unique word column on words, word_id_pl/word_id_en columns in translations.
+// Existing data is moved forward - polish word becomes translation source, english word its target.
+func migrateLegacySchema(db *gorm.DB) error {
+	m := db.Migrator()
+
+	if m.HasConstraint(&baselineWord{}, "chk_words_language") {
+		if err := m.DropConstraint(&baselineWord{}, "chk_words_language"); err != nil {
+			return fmt.Errorf("failed to drop language constraint: %w", err)
+		}
+	}
+
+	// Word is unique per language and homograph now
+	if m.HasIndex(&baselineWord{}, "idx_words_word") {
+		if err := m.DropIndex(&baselineWord{}, "idx_words_word"); err != nil {
+			return fmt.Errorf("failed to drop word index: %w", err)
+		}
+	}
+
+	if !m.HasColumn(&baselineTranslation{}, "word_id_pl") {
+		return nil
+	}
+
+	// Old constraints and indexes would duplicate ones created for renamed columns
+	for _, constraint := range []string{"fk_translations_word_pl", "fk_translations_word_en"} {
+		if m.HasConstraint(&baselineTranslation{}, constraint) {
+			if err := m.DropConstraint(&baselineTranslation{}, constraint); err != nil {
+				return fmt.Errorf("failed to drop constraint %s: %w", constraint, err)
+			}
+		}
+	}
+	for _, index := range []string{"pl_en_pair", "idx_translations_word_id_pl", "idx_translations_word_id_en"} {
+		if m.HasIndex(&baselineTranslation{}, index) {
+			if err := m.DropIndex(&baselineTranslation{}, index); err != nil {
+				return fmt.Errorf("failed to drop index %s: %w", index, err)
+			}
+		}
+	}
+
+	if err := m.RenameColumn(&baselineTranslation{}, "word_id_pl", "source_word_id"); err != nil {
+		return fmt.Errorf("failed to rename polish word column: %w", err)
+	}
+	if err := m.RenameColumn(&baselineTranslation{}, "word_id_en", "target_word_id"); err != nil {
+		return fmt.Errorf("failed to rename english word column: %w", err)
+	}
+	return nil
+}
+
+// Words and forms created before search and sort keys were introduced have empty keys
+func backfillSearchKeys(db *gorm.DB) error {
+	var words []baselineWord
+	err := db.Where("search_key = '' OR sort_key IS NULL").FindInBatches(&words, 500, func(tx *gorm.DB, batch int) error {
+		for _, word := range words {
+			err := tx.Model(&word).UpdateColumns(map[string]interface{}{
+				"search_key": textutil.SearchKey(word.Word),
+				"sort_key":   textutil.SortKey(word.Word),
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	var forms []baselineWordForm
+	return db.Where("search_key = ''").FindInBatches(&forms, 500, func(tx *gorm.DB, batch int) error {
+		for _, form := range forms {
+			if err := tx.Model(&form).UpdateColumn("search_key", textutil.SearchKey(form.Form)).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
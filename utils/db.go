@@ -2,61 +2,100 @@ package utils
 
 import (
 	"fmt"
-	"log"
+	"time"
 
 	"github.com/tdawidzi/dictionary_app/config"
+	"github.com/tdawidzi/dictionary_app/migrations"
 	"github.com/tdawidzi/dictionary_app/models"
 
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-// DB represents the database connection.
-var DB *gorm.DB
-
-func GetDB() *gorm.DB {
-	return DB
-}
-
-func SetDB(d *gorm.DB) {
-	DB = d
+// DefaultLanguages - languages registered on every database by first migration (dictionary originally supported only pl/en pair)
+var DefaultLanguages = []models.Language{
+	{Code: "pl", Name: "Polish", Script: "Latn", Direction: "ltr", SearchConfig: "simple"}, // Postgres has no polish stemmer built in
+	{Code: "en", Name: "English", Script: "Latn", Direction: "ltr", SearchConfig: "english"},
 }
 
-// ConnectDB establishes a connection to the PostgreSQL database
-// with configuration stored in 'config' struct
-func ConnectDB(config *config.Config) error {
-
-	var err error
+// OpenDB opens connection to the PostgreSQL or SQLite database (DB_DRIVER) without migrating it
+func OpenDB(config *config.Config) (*gorm.DB, error) {
+	dialector, err := dialector(config)
+	if err != nil {
+		return nil, err
+	}
 
-	// Get connection details from config
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		config.DB_Host, config.DB_Port, config.DB_User, config.DB_Password, config.DB_Name)
+	// Timestamps in UTC - SQLite stores them as text, compared as strings
+	db, err := gorm.Open(dialector, &gorm.Config{
+		TranslateError: true,
+		NowFunc:        func() time.Time { return time.Now().UTC() },
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	return db, nil
+}
 
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+// ConnectDB establishes a connection to the PostgreSQL or SQLite database (DB_DRIVER)
+// with configuration stored in 'config' struct, and migrates it to the newest schema
+func ConnectDB(config *config.Config) (*gorm.DB, error) {
+	db, err := OpenDB(config)
 	if err != nil {
-		return fmt.Errorf("failed to connect to database: %w", err)
+		return nil, err
 	}
 
 	// Ensures that database has all necessary tables
-	err = migrateTables(DB)
+	err = migrateTables(db)
 	if err != nil {
-		return fmt.Errorf("failed to initialize database: %w", err)
+		return nil, fmt.Errorf("failed to initialize database: %w", err)
 	}
-	return nil
+	return db, nil
+}
+
+func dialector(cfg *config.Config) (gorm.Dialector, error) {
+	switch cfg.DB_Driver {
+	case config.DriverPostgres:
+		// Get connection details from config
+		dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+			cfg.DB_Host, cfg.DB_Port, cfg.DB_User, cfg.DB_Password, cfg.DB_Name)
+		return postgres.Open(dsn), nil
+	case config.DriverSQLite:
+		// SQLite checks foreign keys (cascade deletes) only when enabled per connection.
+		// Transactions take write lock at start - SQLite has no row locks, so writers are serialized instead.
+		dsn := cfg.DB_Path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_txlock=immediate"
+		return sqlite.Open(dsn), nil
+	}
+	return nil, fmt.Errorf("unsupported database driver: %s", cfg.DB_Driver)
+}
+
+// isPostgres checks if database is PostgreSQL - SQLite has no legacy schema, full-text and trigram search
+func isPostgres(db *gorm.DB) bool {
+	return db.Dialector.Name() == "postgres"
 }
 
-// Tables migration - to ensure that all necessary tables exists in database
+// NewMigrator creates migrator of database schema (see migrations)
+func NewMigrator(db *gorm.DB) (*migrations.Migrator, error) {
+	m, err := migrations.New(db)
+	if err != nil {
+		return nil, err
+	}
+	m.Baseline = migrateToBaseline
+	return m, nil
+}
+
+// Tables migration - applies pending migrations, to ensure that all necessary tables exists in database
 func migrateTables(db *gorm.DB) error {
-	// db.AutoMigrate does not return error - it panics. To protect API from fatal error:
-	defer func() {
-		if r := recover(); r != nil {
-			log.Fatalf("Panic during tables migration: %v", r)
-		}
-	}()
-
-	err := db.AutoMigrate(&models.Word{}, &models.Translation{}, &models.Example{})
+	m, err := NewMigrator(db)
 	if err != nil {
-		return fmt.Errorf("failed to create tables: %v", err)
+		return err
+	}
+	applied, err := m.Up()
+	if err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+	for _, migration := range applied {
+		fmt.Printf("Applied migration %s\n", migration)
 	}
 	fmt.Println("Successfully created tables")
 	return nil
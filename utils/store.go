@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"fmt"
+
+	"github.com/tdawidzi/dictionary_app/config"
+	"github.com/tdawidzi/dictionary_app/repository"
+)
+
+// OpenStore opens storage selected by DB_DRIVER. Returned function closes the storage.
+func OpenStore(cfg *config.Config) (repository.Store, func() error, error) {
+	switch cfg.DB_Driver {
+	case config.DriverPostgres, config.DriverSQLite:
+		db, err := ConnectDB(cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load db instance: %w", err)
+		}
+		return repository.NewGormStore(db), sqlDB.Close, nil
+	case config.DriverMemory:
+		fmt.Println("Using in-memory storage - data is lost when server stops")
+		return repository.NewMemoryStore(DefaultLanguages...), func() error { return nil }, nil
+	}
+	return nil, nil, fmt.Errorf("unsupported database driver: %s", cfg.DB_Driver)
+}
@@ -0,0 +1,159 @@
+// Package validation normalizes and checks text given in mutations before it is saved.
+// All problems of a request are collected, so client gets them at once (see apperrors.InvalidFields).
+package validation
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/tdawidzi/dictionary_app/apperrors"
+	"github.com/tdawidzi/dictionary_app/textutil"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Maximum lengths of texts (in characters)
+const (
+	MaxWordLength       = 100 // words and inflected forms
+	MaxExampleLength    = 1000
+	MaxDefinitionLength = 2000
+	MaxLabelLength      = 100 // domains, language names
+	MaxPrincipalLength  = 300 // user names - IDs of principals with issuers of their tokens
+)
+
+// Scripts maps ISO 15924 script codes (see models.Language) to unicode scripts of their letters
+var Scripts = map[string][]*unicode.RangeTable{
+	"Latn": {unicode.Latin},
+	"Cyrl": {unicode.Cyrillic},
+	"Grek": {unicode.Greek},
+	"Armn": {unicode.Armenian},
+	"Geor": {unicode.Georgian},
+	"Hebr": {unicode.Hebrew},
+	"Arab": {unicode.Arabic},
+	"Deva": {unicode.Devanagari},
+	"Thai": {unicode.Thai},
+	"Hani": {unicode.Han},
+	"Hang": {unicode.Hangul},
+	"Jpan": {unicode.Han, unicode.Hiragana, unicode.Katakana},
+	"Kore": {unicode.Hangul, unicode.Han},
+}
+
+// Normalize trims whitespace and converts text to unicode NFC form,
+// so the same text typed on different keyboards is stored the same way ("ó" vs "o" + combining acute)
+func Normalize(text string) string {
+	return norm.NFC.String(strings.TrimSpace(text))
+}
+
+// Validator collects errors of input fields
+type Validator struct {
+	errors []apperrors.FieldError
+}
+
+// Fail marks field as invalid
+func (v *Validator) Fail(field, format string, args ...interface{}) {
+	v.errors = append(v.errors, apperrors.FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// Failed checks if field was already marked as invalid
+func (v *Validator) Failed(field string) bool {
+	return slices.ContainsFunc(v.errors, func(e apperrors.FieldError) bool { return e.Field == field })
+}
+
+// Text normalizes required text and checks its length
+func (v *Validator) Text(field, text string, maxLength int) string {
+	text = Normalize(text)
+	if text == "" {
+		v.Fail(field, "cannot be empty")
+		return text
+	}
+	return v.OptionalText(field, text, maxLength)
+}
+
+// OptionalText normalizes text, which can be empty, and checks its length
+func (v *Validator) OptionalText(field, text string, maxLength int) string {
+	text = Normalize(text)
+	if length := utf8.RuneCountInString(text); length > maxLength {
+		v.Fail(field, "must be at most %d characters long (is %d)", maxLength, length)
+	} else if strings.ContainsFunc(text, unicode.IsControl) {
+		v.Fail(field, "cannot contain control characters")
+	}
+	return text
+}
+
+// Script checks if all letters of text belong to given script. Digits, punctuation and diacritical marks
+// are shared by all scripts. Texts in scripts unknown to validation are not checked.
+func (v *Validator) Script(field, text, script string) {
+	tables, ok := Scripts[script]
+	if !ok || v.Failed(field) {
+		return
+	}
+	for _, r := range text {
+		if unicode.IsLetter(r) && !unicode.In(r, tables...) {
+			v.Fail(field, "letter %q does not belong to %s script", r, script)
+			return
+		}
+	}
+}
+
+// OneOf checks if value is one of allowed values
+func (v *Validator) OneOf(field, value string, allowed ...string) {
+	if !slices.Contains(allowed, value) {
+		v.Fail(field, "must be one of: %s", strings.Join(allowed, ", "))
+	}
+}
+
+// ContainsWord checks if text (e.g. an example) uses the word - case and diacritic insensitive.
+// Inflected forms of the word are accepted too: the given ones (paradigm of the word) and
+// those which only extend the word ("kota", "cats"), as paradigms are usually incomplete.
+func (v *Validator) ContainsWord(field, text, word string, forms ...string) {
+	if v.Failed(field) {
+		return
+	}
+	tokens := words(text)
+	if containsPhrase(tokens, words(word), true) {
+		return
+	}
+	for _, form := range forms {
+		if containsPhrase(tokens, words(form), false) {
+			return
+		}
+	}
+	v.Fail(field, "must contain the word %q or its inflected form", word)
+}
+
+// Err returns VALIDATION error listing all invalid fields, or nil if all fields are valid
+func (v *Validator) Err() error {
+	if len(v.errors) == 0 {
+		return nil
+	}
+	return apperrors.InvalidFields(v.errors)
+}
+
+// words splits text into normalized words
+func words(text string) []string {
+	return strings.FieldsFunc(textutil.SearchKey(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// containsPhrase looks for words of phrase following each other in tokens.
+// With extensible flag, the last word of phrase only has to start token.
+func containsPhrase(tokens, phrase []string, extensible bool) bool {
+	if len(phrase) == 0 {
+		return false
+	}
+	last := len(phrase) - 1
+	for start := 0; start+len(phrase) <= len(tokens); start++ {
+		if !slices.Equal(tokens[start:start+last], phrase[:last]) {
+			continue
+		}
+		token := tokens[start+last]
+		if token == phrase[last] || extensible && strings.HasPrefix(token, phrase[last]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package validation_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tdawidzi/dictionary_app/apperrors"
+	"github.com/tdawidzi/dictionary_app/validation"
+)
+
+func fields(t *testing.T, err error) []apperrors.FieldError {
+	t.Helper()
+
+	var e *apperrors.Error
+	if !errors.As(err, &e) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	assert.Equal(t, apperrors.CodeValidation, e.Code)
+	return e.Fields
+}
+
+func TestNormalize(t *testing.T) {
+	// Letters written as base letter + combining mark ("z" + dot above)
+	assert.Equal(t, "żółw", validation.Normalize(" z\u0307o\u0301łw\t"))
+	assert.Equal(t, "kot", validation.Normalize("kot"))
+}
+
+func TestText(t *testing.T) {
+	var v validation.Validator
+	assert.Equal(t, "kot", v.Text("word", "  kot ", validation.MaxWordLength))
+	assert.NoError(t, v.Err())
+
+	v.Text("empty", "   ", validation.MaxWordLength)
+	v.Text("long", strings.Repeat("ą", validation.MaxWordLength+1), validation.MaxWordLength)
+	v.Text("control", "kot\x00", validation.MaxWordLength)
+	v.OptionalText("domain", "", validation.MaxLabelLength)
+	assert.Equal(t, []apperrors.FieldError{
+		{Field: "empty", Message: "cannot be empty"},
+		{Field: "long", Message: "must be at most 100 characters long (is 101)"},
+		{Field: "control", Message: "cannot contain control characters"},
+	}, fields(t, v.Err()))
+}
+
+func TestScript(t *testing.T) {
+	var v validation.Validator
+	v.Script("polish", "źdźbło-2", "Latn")
+	v.Script("ukrainian", "кіт", "Cyrl")
+	v.Script("unknown", "кіт", "Zzzz")
+	assert.NoError(t, v.Err())
+
+	// Cyrillic "о" in polish word
+	v.Script("word", "kоt", "Latn")
+	assert.Equal(t, []apperrors.FieldError{
+		{Field: "word", Message: `letter 'о' does not belong to Latn script`},
+	}, fields(t, v.Err()))
+}
+
+func TestContainsWord(t *testing.T) {
+	valid := []struct {
+		text  string
+		word  string
+		forms []string
+	}{
+		{"Kot siedzi na dachu.", "kot", nil},
+		{"Ala ma kota.", "kot", nil},
+		{"The cats are sleeping.", "cat", nil},
+		{"Widzę psa.", "pies", []string{"psa", "psem"}},
+		{"Żółw je sałatę.", "żółw", nil},
+		{"I like ice cream very much.", "ice cream", nil},
+	}
+	for _, c := range valid {
+		var v validation.Validator
+		v.ContainsWord("example", c.text, c.word, c.forms...)
+		assert.NoError(t, v.Err(), c.text)
+	}
+
+	invalid := []struct {
+		text string
+		word string
+	}{
+		{"Widzę psa.", "pies"},
+		{"Ala ma psa.", "kot"},
+		{"Skot", "kot"},
+		{"Cream and ice.", "ice cream"},
+	}
+	for _, c := range invalid {
+		var v validation.Validator
+		v.ContainsWord("example", c.text, c.word)
+		assert.Error(t, v.Err(), c.text)
+	}
+}
+
+func TestOneOf(t *testing.T) {
+	var v validation.Validator
+	v.OneOf("direction", "ltr", "ltr", "rtl")
+	assert.NoError(t, v.Err())
+
+	v.OneOf("direction", "up", "ltr", "rtl")
+	assert.Equal(t, []apperrors.FieldError{
+		{Field: "direction", Message: "must be one of: ltr, rtl"},
+	}, fields(t, v.Err()))
+}